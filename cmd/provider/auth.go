@@ -0,0 +1,206 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/providers"
+)
+
+// hmacMaxSkew is how far the timestamp of a signed request may be from the
+// mock's clock.
+const hmacMaxSkew = 5 * time.Minute
+
+// mockAuth enforces an authentication scheme on a mock provider, selected
+// with AUTH_SCHEME: none (the default), api_key, hmac, oauth2 or mtls.
+type mockAuth struct {
+	scheme string
+
+	// api_key
+	apiKey      string
+	apiKeyName  string
+	apiKeyQuery bool
+
+	// hmac
+	keyID  string
+	secret []byte
+
+	// oauth2
+	clientID     string
+	clientSecret string
+	tokenTTL     time.Duration
+	mu           sync.Mutex
+	tokens       map[string]time.Time
+
+	// mtls
+	certFile, keyFile string
+	tlsConfig         *tls.Config
+}
+
+// newMockAuth reads the authentication scheme from the environment.
+func newMockAuth() (*mockAuth, error) {
+	a := &mockAuth{scheme: getEnv("AUTH_SCHEME", "none")}
+
+	switch a.scheme {
+	case "none":
+	case providers.AuthAPIKey:
+		a.apiKey = os.Getenv("AUTH_API_KEY")
+		a.apiKeyName = getEnv("AUTH_API_KEY_HEADER", "X-API-Key")
+		if query := os.Getenv("AUTH_API_KEY_QUERY"); query != "" {
+			a.apiKeyName, a.apiKeyQuery = query, true
+		}
+		if a.apiKey == "" {
+			return nil, errors.New("AUTH_API_KEY is required")
+		}
+	case providers.AuthHMAC:
+		a.keyID = os.Getenv("AUTH_HMAC_KEY_ID")
+		a.secret = []byte(os.Getenv("AUTH_HMAC_SECRET"))
+		if a.keyID == "" || len(a.secret) == 0 {
+			return nil, errors.New("AUTH_HMAC_KEY_ID and AUTH_HMAC_SECRET are required")
+		}
+	case providers.AuthOAuth2:
+		a.clientID = os.Getenv("AUTH_CLIENT_ID")
+		a.clientSecret = os.Getenv("AUTH_CLIENT_SECRET")
+		if a.clientID == "" || a.clientSecret == "" {
+			return nil, errors.New("AUTH_CLIENT_ID and AUTH_CLIENT_SECRET are required")
+		}
+		ttl, err := time.ParseDuration(getEnv("AUTH_TOKEN_TTL", "1h"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid AUTH_TOKEN_TTL: %w", err)
+		}
+		a.tokenTTL = ttl
+		a.tokens = make(map[string]time.Time)
+	case providers.AuthMTLS:
+		a.certFile = os.Getenv("TLS_CERT_FILE")
+		a.keyFile = os.Getenv("TLS_KEY_FILE")
+		caFile := os.Getenv("TLS_CLIENT_CA_FILE")
+		if a.certFile == "" || a.keyFile == "" || caFile == "" {
+			return nil, errors.New("TLS_CERT_FILE, TLS_KEY_FILE and TLS_CLIENT_CA_FILE are required")
+		}
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("invalid TLS_CLIENT_CA_FILE: %w", err)
+		}
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.New("invalid TLS_CLIENT_CA_FILE: no certificates")
+		}
+		a.tlsConfig = &tls.Config{
+			ClientAuth: tls.RequireAndVerifyClientCert,
+			ClientCAs:  clientCAs,
+			MinVersion: tls.VersionTLS12,
+		}
+	default:
+		return nil, fmt.Errorf("unknown AUTH_SCHEME %q, expected none, api_key, hmac, oauth2 or mtls", a.scheme)
+	}
+	return a, nil
+}
+
+// wrap rejects requests to next that don't carry valid credentials. Client
+// certificates are verified by the TLS handshake instead.
+func (a *mockAuth) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := a.check(r); err != nil {
+			w.Header().Set("WWW-Authenticate", a.scheme)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (a *mockAuth) check(r *http.Request) error {
+	switch a.scheme {
+	case providers.AuthAPIKey:
+		key := r.Header.Get(a.apiKeyName)
+		if a.apiKeyQuery {
+			key = r.URL.Query().Get(a.apiKeyName)
+		}
+		if subtle.ConstantTimeCompare([]byte(key), []byte(a.apiKey)) != 1 {
+			return errors.New("invalid API key")
+		}
+
+	case providers.AuthHMAC:
+		keyID := r.Header.Get(providers.HeaderAuthKey)
+		timestamp := r.Header.Get(providers.HeaderAuthTimestamp)
+		signature, err := hex.DecodeString(r.Header.Get(providers.HeaderAuthSignature))
+		if err != nil || keyID != a.keyID {
+			return errors.New("invalid signature")
+		}
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil || time.Since(time.Unix(unix, 0)).Abs() > hmacMaxSkew {
+			return errors.New("stale or invalid timestamp")
+		}
+		want, _ := hex.DecodeString(providers.HMACSignature(a.secret, r.Method, r.URL.RequestURI(), keyID, timestamp))
+		if !hmac.Equal(signature, want) {
+			return errors.New("invalid signature")
+		}
+
+	case providers.AuthOAuth2:
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			return errors.New("missing bearer token")
+		}
+		a.mu.Lock()
+		expires, ok := a.tokens[token]
+		a.mu.Unlock()
+		if !ok || time.Now().After(expires) {
+			return errors.New("invalid or expired token")
+		}
+	}
+	return nil
+}
+
+// tokenHandler issues tokens for the client credentials grant.
+func (a *mockAuth) tokenHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fail := func(status int, code string) {
+			w.WriteHeader(status)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": code})
+		}
+
+		if r.Method != http.MethodPost {
+			fail(http.StatusMethodNotAllowed, "invalid_request")
+			return
+		}
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" {
+			fail(http.StatusBadRequest, "unsupported_grant_type")
+			return
+		}
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != a.clientID || subtle.ConstantTimeCompare([]byte(secret), []byte(a.clientSecret)) != 1 {
+			fail(http.StatusUnauthorized, "invalid_client")
+			return
+		}
+
+		token := rand.Text()
+		a.mu.Lock()
+		for t, expires := range a.tokens {
+			if time.Now().After(expires) {
+				delete(a.tokens, t)
+			}
+		}
+		a.tokens[token] = time.Now().Add(a.tokenTTL)
+		a.mu.Unlock()
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": token,
+			"token_type":   "Bearer",
+			"expires_in":   int(a.tokenTTL.Seconds()),
+		})
+	})
+}
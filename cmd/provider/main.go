@@ -4,21 +4,37 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/alex-user-go/hotels/internal/providers"
+	"github.com/alex-user-go/hotels/internal/providers/hotelpb"
 )
 
 // hotel represents a hotel returned by the mock providers.
+// Content fields are optional and only sent by some mocks.
 type hotel struct {
-	HotelID  string  `json:"hotel_id"`
-	Name     string  `json:"name"`
-	City     string  `json:"city"`
-	Currency string  `json:"currency"`
-	Price    float64 `json:"price"`
-	Nights   int     `json:"nights"`
+	HotelID   string   `json:"hotel_id"`
+	Name      string   `json:"name"`
+	City      string   `json:"city"`
+	Currency  string   `json:"currency"`
+	Price     float64  `json:"price"`
+	Taxes     float64  `json:"taxes,omitempty"`
+	Nights    int      `json:"nights"`
+	Address   string   `json:"address,omitempty"`
+	Stars     float64  `json:"stars,omitempty"`
+	Amenities []string `json:"amenities,omitempty"`
+	Images    []string `json:"images,omitempty"`
 }
 
 var errProviderUnavailable = errors.New("provider unavailable")
@@ -41,14 +57,36 @@ func main() {
 	case "mock3":
 		handler = NewMock3()
 		logger.Info("starting provider", "type", "provider3", "port", port)
+	case "mock4":
+		handler = NewMock4()
+		logger.Info("starting provider", "type", "provider4", "port", port)
+	case "mock5":
+		handler = NewMock5()
+		logger.Info("starting provider", "type", "provider5", "port", port)
+	case "mock6":
+		logger.Info("starting provider", "type", "provider6", "port", port)
+		if err := serveGRPC(logger, port, NewMock6()); err != nil {
+			logger.Error("server error", "error", err)
+			os.Exit(1)
+		}
+		return
 	default:
 		logger.Error("unknown provider type", "type", providerType)
 		os.Exit(1)
 	}
 
+	auth, err := newMockAuth()
+	if err != nil {
+		logger.Error("invalid authentication config", "error", err)
+		os.Exit(1)
+	}
+
 	// Setup routes
 	mux := http.NewServeMux()
-	mux.Handle("/search", handler)
+	mux.Handle("/search", auth.wrap(handler))
+	if auth.scheme == providers.AuthOAuth2 {
+		mux.Handle("/oauth/token", auth.tokenHandler())
+	}
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		if _, err := w.Write([]byte("OK")); err != nil {
@@ -64,12 +102,19 @@ func main() {
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  60 * time.Second,
+		TLSConfig:    auth.tlsConfig,
 	}
 
 	// Start server in goroutine
 	go func() {
-		logger.Info("server listening", "addr", addr)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		logger.Info("server listening", "addr", addr, "auth", auth.scheme)
+		var err error
+		if auth.tlsConfig != nil {
+			err = srv.ListenAndServeTLS(auth.certFile, auth.keyFile)
+		} else {
+			err = srv.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
 			logger.Error("server error", "error", err)
 		}
 	}()
@@ -92,6 +137,69 @@ func main() {
 	logger.Info("server stopped")
 }
 
+// serveGRPC serves a HotelSearch implementation over gRPC, with the standard
+// health service, until interrupted.
+func serveGRPC(logger *slog.Logger, port string, search hotelpb.HotelSearchServer) error {
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return err
+	}
+
+	srv := grpc.NewServer()
+	hotelpb.RegisterHotelSearchServer(srv, search)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+
+	go func() {
+		logger.Info("server listening", "addr", lis.Addr().String())
+		if err := srv.Serve(lis); err != nil {
+			logger.Error("server error", "error", err)
+		}
+	}()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	logger.Info("shutting down server")
+	srv.GracefulStop()
+	logger.Info("server stopped")
+	return nil
+}
+
+// parseOccupancy reads the guests from a provider request. The "occupancy"
+// parameter takes precedence; older clients only send "adults".
+func parseOccupancy(query url.Values) (providers.Occupancy, error) {
+	if value := query.Get("occupancy"); value != "" {
+		return providers.ParseOccupancy(value)
+	}
+
+	adults, err := strconv.Atoi(query.Get("adults"))
+	if err != nil || adults <= 0 {
+		return nil, errors.New("adults must be a positive integer")
+	}
+	return providers.SingleRoom(adults), nil
+}
+
+// occupancyFactor returns the price multiplier for an occupancy relative to
+// one room with one adult. Each extra adult adds 30%, children aged 2-11 add
+// 15% and older children 30%; infants stay free.
+func occupancyFactor(occupancy providers.Occupancy) float64 {
+	factor := 0.0
+	for _, room := range occupancy {
+		factor += 1 + 0.3*float64(room.Adults-1)
+		for _, age := range room.ChildAges {
+			switch {
+			case age < 2:
+			case age < 12:
+				factor += 0.15
+			default:
+				factor += 0.3
+			}
+		}
+	}
+	return factor
+}
+
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
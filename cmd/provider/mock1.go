@@ -10,6 +10,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/alex-user-go/hotels/internal/providers"
 )
 
 // Mock1 is the first mock provider with 100ms base latency and 10% failure rate.
@@ -27,7 +29,7 @@ func NewMock1() *Mock1 {
 }
 
 // search simulates searching for hotels with random latency and potential failures.
-func (p *Mock1) search(ctx context.Context, city, _ string, nights, adults int) ([]hotel, error) {
+func (p *Mock1) search(ctx context.Context, city, _ string, nights int, occupancy providers.Occupancy) ([]hotel, error) {
 	// Simulate random latency (50ms to 200ms)
 	latency := time.Duration(50+p.rng.Intn(150)) * time.Millisecond
 
@@ -43,11 +45,12 @@ func (p *Mock1) search(ctx context.Context, city, _ string, nights, adults int)
 	}
 
 	// Generate hotels
-	return p.generateHotels(city, nights, adults), nil
+	return p.generateHotels(city, nights, occupancy), nil
 }
 
-func (p *Mock1) generateHotels(city string, nights, _ int) []hotel {
+func (p *Mock1) generateHotels(city string, nights int, occupancy providers.Occupancy) []hotel {
 	city = strings.ToLower(strings.TrimSpace(city))
+	factor := occupancyFactor(occupancy)
 
 	return []hotel{
 		{
@@ -55,7 +58,7 @@ func (p *Mock1) generateHotels(city string, nights, _ int) []hotel {
 			Name:     "Grand Hotel",
 			City:     city,
 			Currency: "EUR",
-			Price:    p.calculatePrice(100, 200, nights),
+			Price:    p.calculatePrice(100, 200, nights, factor),
 			Nights:   nights,
 		},
 		{
@@ -63,7 +66,7 @@ func (p *Mock1) generateHotels(city string, nights, _ int) []hotel {
 			Name:     "City Center Inn",
 			City:     city,
 			Currency: "eur", // Inconsistent casing
-			Price:    p.calculatePrice(80, 150, nights),
+			Price:    p.calculatePrice(80, 150, nights, factor),
 			Nights:   nights,
 		},
 		{
@@ -71,7 +74,7 @@ func (p *Mock1) generateHotels(city string, nights, _ int) []hotel {
 			Name:     "Budget Stay",
 			City:     city,
 			Currency: "EUR",
-			Price:    p.calculatePrice(50, 100, nights),
+			Price:    p.calculatePrice(50, 100, nights, factor),
 			Nights:   nights,
 		},
 		{
@@ -79,8 +82,9 @@ func (p *Mock1) generateHotels(city string, nights, _ int) []hotel {
 			Name:     "Luxury Palace",
 			City:     city,
 			Currency: "EUR",
-			Price:    p.calculatePrice(200, 400, nights),
+			Price:    p.calculatePrice(200, 400, nights, factor),
 			Nights:   nights,
+			Address:  "Palace Square 1",
 		},
 	}
 }
@@ -90,10 +94,11 @@ func (p *Mock1) randomPrice(min, max float64) float64 {
 	return float64(int(price*100)) / 100
 }
 
-// calculatePrice calculates the total price based on per-night rate and number of nights.
-func (p *Mock1) calculatePrice(minPerNight, maxPerNight float64, nights int) float64 {
+// calculatePrice calculates the total price based on per-night rate, number of
+// nights and the occupancy factor.
+func (p *Mock1) calculatePrice(minPerNight, maxPerNight float64, nights int, factor float64) float64 {
 	perNightPrice := p.randomPrice(minPerNight, maxPerNight)
-	totalPrice := perNightPrice * float64(nights)
+	totalPrice := perNightPrice * float64(nights) * factor
 	return float64(int(totalPrice*100)) / 100
 }
 
@@ -103,9 +108,8 @@ func (p *Mock1) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	city := strings.TrimSpace(r.URL.Query().Get("city"))
 	checkin := strings.TrimSpace(r.URL.Query().Get("checkin"))
 	nightsStr := r.URL.Query().Get("nights")
-	adultsStr := r.URL.Query().Get("adults")
 
-	if city == "" || checkin == "" || nightsStr == "" || adultsStr == "" {
+	if city == "" || checkin == "" || nightsStr == "" {
 		http.Error(w, "missing required parameters", http.StatusBadRequest)
 		return
 	}
@@ -116,14 +120,14 @@ func (p *Mock1) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	adults, err := strconv.Atoi(adultsStr)
-	if err != nil || adults <= 0 {
-		http.Error(w, "invalid adults", http.StatusBadRequest)
+	occupancy, err := parseOccupancy(r.URL.Query())
+	if err != nil {
+		http.Error(w, "invalid occupancy: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	// Use the search method
-	hotels, err := p.search(r.Context(), city, checkin, nights, adults)
+	hotels, err := p.search(r.Context(), city, checkin, nights, occupancy)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusServiceUnavailable)
 		return
@@ -10,9 +10,12 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/alex-user-go/hotels/internal/providers"
 )
 
 // Mock2 is the second mock provider with 150ms base latency and 15% failure rate.
+// It quotes prices per night.
 type Mock2 struct {
 	rng    *rand.Rand
 	logger *slog.Logger
@@ -27,7 +30,7 @@ func NewMock2() *Mock2 {
 }
 
 // search simulates searching for hotels with random latency and potential failures.
-func (p *Mock2) search(ctx context.Context, city, _ string, nights, _ int) ([]hotel, error) {
+func (p *Mock2) search(ctx context.Context, city, _ string, nights int, occupancy providers.Occupancy) ([]hotel, error) {
 	// Simulate random latency (75ms to 300ms)
 	latency := time.Duration(75+p.rng.Intn(225)) * time.Millisecond
 
@@ -43,11 +46,12 @@ func (p *Mock2) search(ctx context.Context, city, _ string, nights, _ int) ([]ho
 	}
 
 	// Generate hotels
-	return p.generateHotels(city, nights), nil
+	return p.generateHotels(city, nights, occupancy), nil
 }
 
-func (p *Mock2) generateHotels(city string, nights int) []hotel {
+func (p *Mock2) generateHotels(city string, nights int, occupancy providers.Occupancy) []hotel {
 	city = strings.ToLower(strings.TrimSpace(city))
+	factor := occupancyFactor(occupancy)
 
 	hotels := []hotel{
 		{
@@ -55,7 +59,7 @@ func (p *Mock2) generateHotels(city string, nights int) []hotel {
 			Name:     "Grand Hotel",
 			City:     city,
 			Currency: "EUR",
-			Price:    p.randomPrice(100, 200),
+			Price:    p.randomPrice(100, 200, factor),
 			Nights:   nights,
 		},
 		{
@@ -63,7 +67,7 @@ func (p *Mock2) generateHotels(city string, nights int) []hotel {
 			Name:     "City Center Inn",
 			City:     city,
 			Currency: "eur",
-			Price:    p.randomPrice(80, 150),
+			Price:    p.randomPrice(80, 150, factor),
 			Nights:   nights,
 		},
 		{
@@ -71,16 +75,20 @@ func (p *Mock2) generateHotels(city string, nights int) []hotel {
 			Name:     "Budget Stay",
 			City:     city,
 			Currency: "EUR",
-			Price:    p.randomPrice(50, 100),
+			Price:    p.randomPrice(50, 100, factor),
 			Nights:   nights,
 		},
 		{
-			HotelID:  "H005",
-			Name:     "Seaside Resort",
-			City:     city,
-			Currency: "EUR",
-			Price:    p.randomPrice(150, 300),
-			Nights:   nights,
+			HotelID:   "H005",
+			Name:      "Seaside Resort",
+			City:      city,
+			Currency:  "EUR",
+			Price:     p.randomPrice(150, 300, factor),
+			Nights:    nights,
+			Address:   "15 Beach Promenade",
+			Stars:     4,
+			Amenities: []string{"wifi", "pool", "beach_access"},
+			Images:    []string{"https://images.example.com/hotels/H005/beach.jpg"},
 		},
 	}
 
@@ -99,8 +107,9 @@ func (p *Mock2) generateHotels(city string, nights int) []hotel {
 	return hotels
 }
 
-func (p *Mock2) randomPrice(min, max float64) float64 {
-	price := min + p.rng.Float64()*(max-min)
+// randomPrice returns a random price in [min, max) scaled by the occupancy factor.
+func (p *Mock2) randomPrice(min, max, factor float64) float64 {
+	price := (min + p.rng.Float64()*(max-min)) * factor
 	return float64(int(price*100)) / 100
 }
 
@@ -110,9 +119,8 @@ func (p *Mock2) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	city := strings.TrimSpace(r.URL.Query().Get("city"))
 	checkin := strings.TrimSpace(r.URL.Query().Get("checkin"))
 	nightsStr := r.URL.Query().Get("nights")
-	adultsStr := r.URL.Query().Get("adults")
 
-	if city == "" || checkin == "" || nightsStr == "" || adultsStr == "" {
+	if city == "" || checkin == "" || nightsStr == "" {
 		http.Error(w, "missing required parameters", http.StatusBadRequest)
 		return
 	}
@@ -123,14 +131,14 @@ func (p *Mock2) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	adults, err := strconv.Atoi(adultsStr)
-	if err != nil || adults <= 0 {
-		http.Error(w, "invalid adults", http.StatusBadRequest)
+	occupancy, err := parseOccupancy(r.URL.Query())
+	if err != nil {
+		http.Error(w, "invalid occupancy: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	// Use the search method
-	hotels, err := p.search(r.Context(), city, checkin, nights, adults)
+	hotels, err := p.search(r.Context(), city, checkin, nights, occupancy)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusServiceUnavailable)
 		return
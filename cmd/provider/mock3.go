@@ -10,9 +10,13 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/alex-user-go/hotels/internal/providers"
 )
 
 // Mock3 is the third mock provider with 120ms base latency and 10% failure rate.
+// It quotes prices per night in USD with the city tax itemized, and uses its
+// own hotel IDs and names.
 type Mock3 struct {
 	rng    *rand.Rand
 	logger *slog.Logger
@@ -27,7 +31,7 @@ func NewMock3() *Mock3 {
 }
 
 // search simulates searching for hotels with random latency and potential failures.
-func (p *Mock3) search(ctx context.Context, city, _ string, nights, _ int) ([]hotel, error) {
+func (p *Mock3) search(ctx context.Context, city, _ string, nights int, occupancy providers.Occupancy) ([]hotel, error) {
 	// Simulate random latency (60ms to 240ms)
 	latency := time.Duration(60+p.rng.Intn(180)) * time.Millisecond
 
@@ -43,43 +47,44 @@ func (p *Mock3) search(ctx context.Context, city, _ string, nights, _ int) ([]ho
 	}
 
 	// Generate hotels
-	return p.generateHotels(city, nights), nil
+	return p.generateHotels(city, nights, occupancy), nil
 }
 
-func (p *Mock3) generateHotels(city string, nights int) []hotel {
+func (p *Mock3) generateHotels(city string, nights int, occupancy providers.Occupancy) []hotel {
 	city = strings.ToLower(strings.TrimSpace(city))
+	factor := occupancyFactor(occupancy)
 
 	hotels := []hotel{
 		{
-			HotelID:  "H001",
-			Name:     "Grand Hotel",
+			HotelID:  "GH-100",
+			Name:     "The Grand Hotel",
 			City:     city,
-			Currency: "EUR",
-			Price:    p.randomPrice(100, 200),
+			Currency: "USD",
+			Price:    p.randomPrice(100, 200, factor),
 			Nights:   nights,
 		},
 		{
-			HotelID:  "H002",
-			Name:     "City Center Inn",
+			HotelID:  "CCI-200",
+			Name:     "City Centre Inn",
 			City:     city,
-			Currency: "eur",
-			Price:    p.randomPrice(80, 150),
+			Currency: "usd",
+			Price:    p.randomPrice(80, 150, factor),
 			Nights:   nights,
 		},
 		{
-			HotelID:  "H003",
+			HotelID:  "BS-300",
 			Name:     "Budget Stay",
 			City:     city,
-			Currency: "EUR",
-			Price:    p.randomPrice(50, 100),
+			Currency: "USD",
+			Price:    p.randomPrice(50, 100, factor),
 			Nights:   nights,
 		},
 		{
-			HotelID:  "H006",
+			HotelID:  "ML-400",
 			Name:     "Mountain Lodge",
 			City:     city,
-			Currency: "EUR",
-			Price:    p.randomPrice(120, 250),
+			Currency: "USD",
+			Price:    p.randomPrice(120, 250, factor),
 			Nights:   nights,
 		},
 	}
@@ -87,20 +92,26 @@ func (p *Mock3) generateHotels(city string, nights int) []hotel {
 	// Return duplicate hotel_id with different price (dedup test)
 	if p.rng.Float64() < 0.5 {
 		hotels = append(hotels, hotel{
-			HotelID:  "H001", // Duplicate - aggregator should keep lowest price
-			Name:     "Grand Hotel",
+			HotelID:  "GH-100", // Duplicate - aggregator should keep lowest price
+			Name:     "The Grand Hotel",
 			City:     city,
-			Currency: "EUR",
-			Price:    p.randomPrice(90, 180),
+			Currency: "USD",
+			Price:    p.randomPrice(90, 180, factor),
 			Nights:   nights,
 		})
 	}
 
+	// Itemize the 10% city tax included in every price
+	for i := range hotels {
+		hotels[i].Taxes = float64(int(hotels[i].Price*10)) / 100
+	}
+
 	return hotels
 }
 
-func (p *Mock3) randomPrice(min, max float64) float64 {
-	price := min + p.rng.Float64()*(max-min)
+// randomPrice returns a random price in [min, max) scaled by the occupancy factor.
+func (p *Mock3) randomPrice(min, max, factor float64) float64 {
+	price := (min + p.rng.Float64()*(max-min)) * factor
 	return float64(int(price*100)) / 100
 }
 
@@ -110,9 +121,8 @@ func (p *Mock3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	city := strings.TrimSpace(r.URL.Query().Get("city"))
 	checkin := strings.TrimSpace(r.URL.Query().Get("checkin"))
 	nightsStr := r.URL.Query().Get("nights")
-	adultsStr := r.URL.Query().Get("adults")
 
-	if city == "" || checkin == "" || nightsStr == "" || adultsStr == "" {
+	if city == "" || checkin == "" || nightsStr == "" {
 		http.Error(w, "missing required parameters", http.StatusBadRequest)
 		return
 	}
@@ -123,14 +133,14 @@ func (p *Mock3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	adults, err := strconv.Atoi(adultsStr)
-	if err != nil || adults <= 0 {
-		http.Error(w, "invalid adults", http.StatusBadRequest)
+	occupancy, err := parseOccupancy(r.URL.Query())
+	if err != nil {
+		http.Error(w, "invalid occupancy: "+err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	// Use the search method
-	hotels, err := p.search(r.Context(), city, checkin, nights, adults)
+	hotels, err := p.search(r.Context(), city, checkin, nights, occupancy)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusServiceUnavailable)
 		return
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/providers"
+)
+
+// Mock4 is the fourth mock provider with 100ms base latency and 10% failure
+// rate. Its response schema differs from the other mocks: results are wrapped
+// in an envelope, property and offer details are nested, and amounts are sent
+// as strings. Prices are per stay in GBP and include the itemized tax.
+type Mock4 struct {
+	rng    *rand.Rand
+	logger *slog.Logger
+}
+
+// mock4Response is the envelope of a Mock4 response.
+type mock4Response struct {
+	Status string `json:"status"`
+	Data   struct {
+		Results []mock4Result `json:"results"`
+	} `json:"data"`
+}
+
+type mock4Result struct {
+	Property mock4Property `json:"property"`
+	Offer    mock4Offer    `json:"offer"`
+}
+
+type mock4Property struct {
+	Code     string `json:"code"`
+	Title    string `json:"title"`
+	Rating   string `json:"rating,omitempty"`
+	Location struct {
+		City    string `json:"city"`
+		Address string `json:"address,omitempty"`
+	} `json:"location"`
+	Facilities []string `json:"facilities,omitempty"`
+}
+
+type mock4Offer struct {
+	Total struct {
+		Amount   string `json:"amount"`
+		Currency string `json:"currency"`
+	} `json:"total"`
+	Tax        string `json:"tax"`
+	StayNights int    `json:"stay_nights"`
+}
+
+// NewMock4 creates a new Mock4 provider.
+func NewMock4() *Mock4 {
+	return &Mock4{
+		rng:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		logger: slog.New(slog.NewTextHandler(os.Stdout, nil)),
+	}
+}
+
+// search simulates searching for hotels with random latency and potential failures.
+func (p *Mock4) search(ctx context.Context, city, _ string, nights int, occupancy providers.Occupancy) ([]mock4Result, error) {
+	// Simulate random latency (50ms to 200ms)
+	latency := time.Duration(50+p.rng.Intn(150)) * time.Millisecond
+
+	select {
+	case <-time.After(latency):
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	}
+
+	// Simulate 10% failure rate
+	if p.rng.Float64() < 0.1 {
+		return nil, errProviderUnavailable
+	}
+
+	// Generate hotels
+	return p.generateResults(city, nights, occupancy), nil
+}
+
+func (p *Mock4) generateResults(city string, nights int, occupancy providers.Occupancy) []mock4Result {
+	city = strings.ToLower(strings.TrimSpace(city))
+	factor := occupancyFactor(occupancy)
+
+	properties := []struct {
+		code, title, rating string
+		facilities          []string
+		min, max            float64
+	}{
+		{code: "P-1001", title: "Grand Hotel", rating: "5", facilities: []string{"spa", "restaurant"}, min: 90, max: 170},
+		{code: "P-1003", title: "Budget Stay", rating: "2", min: 45, max: 85},
+		{code: "P-1005", title: "Seaside Resort", rating: "4", facilities: []string{"pool", "beach"}, min: 120, max: 220},
+	}
+
+	results := make([]mock4Result, 0, len(properties))
+	for _, prop := range properties {
+		total := p.randomPrice(prop.min, prop.max) * float64(nights) * factor
+
+		var r mock4Result
+		r.Property = mock4Property{Code: prop.code, Title: prop.title, Rating: prop.rating, Facilities: prop.facilities}
+		r.Property.Location.City = city
+		r.Offer.Total.Amount = fmt.Sprintf("%.2f", total)
+		r.Offer.Total.Currency = "GBP"
+		r.Offer.Tax = fmt.Sprintf("%.2f", total*0.2/1.2) // 20% VAT included in the total
+		r.Offer.StayNights = nights
+		results = append(results, r)
+	}
+	return results
+}
+
+func (p *Mock4) randomPrice(min, max float64) float64 {
+	price := min + p.rng.Float64()*(max-min)
+	return float64(int(price*100)) / 100
+}
+
+// ServeHTTP handles HTTP requests for this provider.
+func (p *Mock4) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Parse query parameters
+	city := strings.TrimSpace(r.URL.Query().Get("city"))
+	checkin := strings.TrimSpace(r.URL.Query().Get("checkin"))
+	nightsStr := r.URL.Query().Get("nights")
+
+	if city == "" || checkin == "" || nightsStr == "" {
+		http.Error(w, "missing required parameters", http.StatusBadRequest)
+		return
+	}
+
+	nights, err := strconv.Atoi(nightsStr)
+	if err != nil || nights <= 0 {
+		http.Error(w, "invalid nights", http.StatusBadRequest)
+		return
+	}
+
+	occupancy, err := parseOccupancy(r.URL.Query())
+	if err != nil {
+		http.Error(w, "invalid occupancy: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Use the search method
+	results, err := p.search(r.Context(), city, checkin, nights, occupancy)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	var resp mock4Response
+	resp.Status = "OK"
+	resp.Data.Results = results
+
+	// Return JSON response
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		p.logger.Error("failed to encode response", "error", err)
+		return
+	}
+}
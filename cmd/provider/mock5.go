@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/providers"
+)
+
+// Mock5 is the fifth mock provider, a legacy SOAP 1.1 service with 200ms base
+// latency and 10% failure rate. Searches are POSTed as SOAP envelopes and
+// failures are reported as SOAP faults. Prices are per night in CHF.
+type Mock5 struct {
+	rng    *rand.Rand
+	logger *slog.Logger
+}
+
+const soapEnvelopeNS = "http://schemas.xmlsoap.org/soap/envelope/"
+
+// mock5Request is the SOAP envelope of a Mock5 search. Elements are matched
+// by local name, whatever their namespace prefix.
+type mock5Request struct {
+	Body struct {
+		Availability *struct {
+			City    string `xml:"City"`
+			CheckIn string `xml:"CheckIn"`
+			Nights  int    `xml:"Nights"`
+			Rooms   []struct {
+				Adults   int `xml:"adults,attr"`
+				Children []struct {
+					Age int `xml:"age,attr"`
+				} `xml:"Child"`
+			} `xml:"Rooms>Room"`
+		} `xml:"AvailabilityRequest"`
+	} `xml:"Body"`
+}
+
+type mock5Envelope struct {
+	XMLName xml.Name `xml:"soap:Envelope"`
+	NS      string   `xml:"xmlns:soap,attr"`
+	Body    struct {
+		Response *mock5Response `xml:"AvailabilityResponse,omitempty"`
+		Fault    *mock5Fault    `xml:"soap:Fault,omitempty"`
+	} `xml:"soap:Body"`
+}
+
+type mock5Response struct {
+	Properties []mock5Property `xml:"Properties>Property"`
+}
+
+type mock5Property struct {
+	ID       string    `xml:"id,attr"`
+	Name     string    `xml:"Name"`
+	City     string    `xml:"City"`
+	Category int       `xml:"Category"`
+	Rate     mock5Rate `xml:"Rate"`
+}
+
+type mock5Rate struct {
+	Currency string `xml:"currency,attr"`
+	Nights   int    `xml:"nights,attr"`
+	Nightly  string `xml:"Nightly"`
+	Tax      string `xml:"Tax"`
+}
+
+type mock5Fault struct {
+	Code   string `xml:"faultcode"`
+	String string `xml:"faultstring"`
+}
+
+// NewMock5 creates a new Mock5 provider.
+func NewMock5() *Mock5 {
+	return &Mock5{
+		rng:    rand.New(rand.NewSource(time.Now().UnixNano())),
+		logger: slog.New(slog.NewTextHandler(os.Stdout, nil)),
+	}
+}
+
+// search simulates searching for hotels with random latency and potential failures.
+func (p *Mock5) search(ctx context.Context, city, _ string, nights int, occupancy providers.Occupancy) ([]mock5Property, error) {
+	// Simulate random latency (100ms to 300ms)
+	latency := time.Duration(100+p.rng.Intn(200)) * time.Millisecond
+
+	select {
+	case <-time.After(latency):
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	}
+
+	// Simulate 10% failure rate
+	if p.rng.Float64() < 0.1 {
+		return nil, errProviderUnavailable
+	}
+
+	// Generate hotels
+	return p.generateProperties(city, nights, occupancy), nil
+}
+
+func (p *Mock5) generateProperties(city string, nights int, occupancy providers.Occupancy) []mock5Property {
+	city = strings.ToLower(strings.TrimSpace(city))
+	factor := occupancyFactor(occupancy)
+
+	templates := []struct {
+		id, name string
+		category int
+		min, max float64
+	}{
+		{id: "LH-02", name: "City Center Inn", category: 3, min: 85, max: 140},
+		{id: "LH-04", name: "Luxury Palace", category: 5, min: 210, max: 360},
+		{id: "LH-06", name: "Mountain Lodge", category: 3, min: 95, max: 160},
+	}
+
+	properties := make([]mock5Property, 0, len(templates))
+	for _, t := range templates {
+		nightly := p.randomPrice(t.min, t.max) * factor
+		properties = append(properties, mock5Property{
+			ID:       t.id,
+			Name:     t.name,
+			City:     city,
+			Category: t.category,
+			Rate: mock5Rate{
+				Currency: "CHF",
+				Nights:   nights,
+				Nightly:  fmt.Sprintf("%.2f", nightly),
+				Tax:      fmt.Sprintf("%.2f", nightly*0.081/1.081), // 8.1% VAT included
+			},
+		})
+	}
+	return properties
+}
+
+func (p *Mock5) randomPrice(min, max float64) float64 {
+	price := min + p.rng.Float64()*(max-min)
+	return float64(int(price*100)) / 100
+}
+
+// ServeHTTP handles HTTP requests for this provider.
+func (p *Mock5) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req mock5Request
+	if err := xml.NewDecoder(r.Body).Decode(&req); err != nil {
+		p.fault(w, "soap:Client", "malformed request: "+err.Error())
+		return
+	}
+	search := req.Body.Availability
+	if search == nil {
+		p.fault(w, "soap:Client", "unsupported operation")
+		return
+	}
+
+	city := strings.TrimSpace(search.City)
+	checkin := strings.TrimSpace(search.CheckIn)
+	if city == "" || checkin == "" {
+		p.fault(w, "soap:Client", "missing required parameters")
+		return
+	}
+	if search.Nights <= 0 {
+		p.fault(w, "soap:Client", "invalid nights")
+		return
+	}
+
+	occupancy := make(providers.Occupancy, 0, len(search.Rooms))
+	for _, room := range search.Rooms {
+		guests := providers.Room{Adults: room.Adults}
+		for _, child := range room.Children {
+			guests.ChildAges = append(guests.ChildAges, child.Age)
+		}
+		occupancy = append(occupancy, guests)
+	}
+	if err := occupancy.Validate(); err != nil {
+		p.fault(w, "soap:Client", "invalid occupancy: "+err.Error())
+		return
+	}
+
+	// Use the search method
+	properties, err := p.search(r.Context(), city, checkin, search.Nights, occupancy)
+	if err != nil {
+		p.fault(w, "soap:Server", err.Error())
+		return
+	}
+
+	env := mock5Envelope{NS: soapEnvelopeNS}
+	env.Body.Response = &mock5Response{Properties: properties}
+	p.write(w, http.StatusOK, env)
+}
+
+// fault writes a SOAP fault. SOAP 1.1 faults are sent with status 500.
+func (p *Mock5) fault(w http.ResponseWriter, code, message string) {
+	env := mock5Envelope{NS: soapEnvelopeNS}
+	env.Body.Fault = &mock5Fault{Code: code, String: message}
+	p.write(w, http.StatusInternalServerError, env)
+}
+
+func (p *Mock5) write(w http.ResponseWriter, status int, env mock5Envelope) {
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	w.WriteHeader(status)
+	if _, err := w.Write([]byte(xml.Header)); err != nil {
+		p.logger.Error("failed to write response", "error", err)
+		return
+	}
+	if err := xml.NewEncoder(w).Encode(env); err != nil {
+		p.logger.Error("failed to encode response", "error", err)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/alex-user-go/hotels/internal/providers"
+	"github.com/alex-user-go/hotels/internal/providers/hotelpb"
+)
+
+// Mock6 is the sixth mock provider, a gRPC service implementing
+// hotelpb.HotelSearch with 150ms base latency and 10% failure rate.
+// Failures are returned as Unavailable and invalid requests as
+// InvalidArgument. Prices are per stay in USD and hotels come with their
+// location.
+type Mock6 struct {
+	hotelpb.UnimplementedHotelSearchServer
+
+	rng *rand.Rand
+}
+
+// NewMock6 creates a new Mock6 provider.
+func NewMock6() *Mock6 {
+	return &Mock6{
+		rng: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Search implements hotelpb.HotelSearchServer.
+func (p *Mock6) Search(ctx context.Context, req *hotelpb.SearchRequest) (*hotelpb.SearchResponse, error) {
+	city := strings.TrimSpace(req.GetCity())
+	if city == "" || strings.TrimSpace(req.GetCheckin()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing required parameters")
+	}
+	if req.GetNights() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid nights")
+	}
+
+	occupancy := make(providers.Occupancy, 0, len(req.GetRooms()))
+	for _, room := range req.GetRooms() {
+		guests := providers.Room{Adults: int(room.GetAdults())}
+		for _, age := range room.GetChildAges() {
+			guests.ChildAges = append(guests.ChildAges, int(age))
+		}
+		occupancy = append(occupancy, guests)
+	}
+	if err := occupancy.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid occupancy: "+err.Error())
+	}
+
+	hotels, err := p.search(ctx, city, int(req.GetNights()), occupancy)
+	if err != nil {
+		if errors.Is(err, errProviderUnavailable) {
+			return nil, status.Error(codes.Unavailable, err.Error())
+		}
+		return nil, status.FromContextError(err).Err()
+	}
+	return &hotelpb.SearchResponse{Hotels: hotels}, nil
+}
+
+// search simulates searching for hotels with random latency and potential failures.
+func (p *Mock6) search(ctx context.Context, city string, nights int, occupancy providers.Occupancy) ([]*hotelpb.Hotel, error) {
+	// Simulate random latency (50ms to 250ms)
+	latency := time.Duration(50+p.rng.Intn(200)) * time.Millisecond
+
+	select {
+	case <-time.After(latency):
+	case <-ctx.Done():
+		return nil, context.Cause(ctx)
+	}
+
+	// Simulate 10% failure rate
+	if p.rng.Float64() < 0.1 {
+		return nil, errProviderUnavailable
+	}
+
+	// Generate hotels
+	return p.generateHotels(city, nights, occupancy), nil
+}
+
+func (p *Mock6) generateHotels(city string, nights int, occupancy providers.Occupancy) []*hotelpb.Hotel {
+	city = strings.ToLower(strings.TrimSpace(city))
+	factor := occupancyFactor(occupancy)
+
+	templates := []struct {
+		id, name string
+		stars    float64
+		lat, lon float64
+		min, max float64
+	}{
+		{id: "rpc-grand", name: "Grand Hotel", stars: 5, lat: 48.8698, lon: 2.3075, min: 110, max: 210},
+		{id: "rpc-seaside", name: "Seaside Resort", stars: 4, lat: 43.6950, lon: 7.2650, min: 130, max: 240},
+		{id: "rpc-lodge", name: "Mountain Lodge", stars: 3, lat: 47.2692, lon: 11.3933, min: 90, max: 170},
+	}
+
+	hotels := make([]*hotelpb.Hotel, 0, len(templates))
+	for _, t := range templates {
+		price := p.randomPrice(t.min, t.max) * float64(nights) * factor
+		hotels = append(hotels, &hotelpb.Hotel{
+			HotelId:    t.id,
+			Name:       t.name,
+			City:       city,
+			Currency:   "USD",
+			Price:      float64(int(price*100)) / 100,
+			Nights:     int32(nights),
+			PriceBasis: hotelpb.PriceBasis_PRICE_BASIS_PER_STAY,
+			Stars:      t.stars,
+			Location:   &hotelpb.Location{Latitude: t.lat, Longitude: t.lon},
+		})
+	}
+	return hotels
+}
+
+func (p *Mock6) randomPrice(min, max float64) float64 {
+	price := min + p.rng.Float64()*(max-min)
+	return float64(int(price*100)) / 100
+}
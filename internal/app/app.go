@@ -1,20 +1,36 @@
 package app
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
+	"math"
 	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/alex-user-go/hotels/internal/content"
+	"github.com/alex-user-go/hotels/internal/fx"
+	"github.com/alex-user-go/hotels/internal/geo"
 	"github.com/alex-user-go/hotels/internal/handler"
 	"github.com/alex-user-go/hotels/internal/middleware"
 	"github.com/alex-user-go/hotels/internal/obs"
 	"github.com/alex-user-go/hotels/internal/providers"
 	"github.com/alex-user-go/hotels/internal/search"
+	"github.com/alex-user-go/hotels/internal/search/anomaly"
 	"github.com/alex-user-go/hotels/internal/search/cache"
+	"github.com/alex-user-go/hotels/internal/search/latency"
+	"github.com/alex-user-go/hotels/internal/search/mapping"
+	"github.com/alex-user-go/hotels/internal/search/markup"
+	"github.com/alex-user-go/hotels/internal/search/normalize"
 	"github.com/alex-user-go/hotels/internal/search/ratelimit"
 )
 
@@ -29,20 +45,102 @@ func Run() error {
 	// Initialize metrics
 	metrics := obs.NewMetrics(logger)
 
-	// Initialize providers (HTTP clients)
-	providersList := []providers.Provider{
-		providers.NewHTTPProvider("provider1", getEnv("PROVIDER1_URL", "http://localhost:9001"), 2*time.Second),
-		providers.NewHTTPProvider("provider2", getEnv("PROVIDER2_URL", "http://localhost:9002"), 2*time.Second),
-		providers.NewHTTPProvider("provider3", getEnv("PROVIDER3_URL", "http://localhost:9003"), 2*time.Second),
+	// Initialize providers (HTTP, XML and gRPC clients)
+	searchTimeout, err := getEnvDuration("SEARCH_TIMEOUT", 2*time.Second)
+	if err != nil {
+		return err
+	}
+	if searchTimeout <= 0 {
+		return errors.New("invalid SEARCH_TIMEOUT: must be positive")
+	}
+	providersList, timeoutOpts, err := loadProviders(logger, metrics, searchTimeout)
+	if err != nil {
+		return err
+	}
+	defer closeProviders(providersList, logger)
+
+	// Initialize currency converter (rates reloaded from file periodically)
+	refreshInterval, err := getEnvDuration("FX_REFRESH_INTERVAL", time.Hour)
+	if err != nil {
+		return err
+	}
+	converter, err := fx.NewConverter(
+		context.Background(),
+		fx.NewFileSource(getEnv("FX_RATES_FILE", "data/fx_rates.json")),
+		refreshInterval,
+		logger,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to load exchange rates: %w", err)
+	}
+	defer converter.Close()
+
+	// Initialize hotel mapping (provider IDs to canonical IDs)
+	mappedHotels, err := mapping.Load(getEnv("HOTEL_MAPPING_FILE", "data/hotel_mapping.json"))
+	if err != nil {
+		return err
+	}
+	hotelMapping, err := mapping.New(mappedHotels, logger)
+	if err != nil {
+		return fmt.Errorf("invalid hotel mapping: %w", err)
+	}
+
+	// Initialize hotel content catalog
+	catalog, err := content.LoadFile(getEnv("HOTEL_CONTENT_FILE", "data/hotels.json"))
+	if err != nil {
+		return err
+	}
+	contentStore, err := content.NewStore(catalog)
+	if err != nil {
+		return fmt.Errorf("invalid hotel content: %w", err)
 	}
 
 	// Initialize aggregator
-	aggregator := search.NewAggregator(
-		providersList,
-		2*time.Second,
-		metrics,
+	aggregatorOpts := []search.Option{
+		search.WithConverter(converter),
+		search.WithResolver(hotelMapping),
+		search.WithContent(contentStore),
+	}
+	anomalyConfig, err := loadAnomalyConfig()
+	if err != nil {
+		return err
+	}
+	if anomalyConfig != nil {
+		aggregatorOpts = append(aggregatorOpts, search.WithPriceScreen(anomaly.NewDetector(*anomalyConfig)))
+	}
+	pipelineOpts, err := loadPipelines(providersList, normalize.Standard(hotelMapping, contentStore))
+	if err != nil {
+		return err
+	}
+	aggregatorOpts = append(aggregatorOpts, pipelineOpts...)
+	aggregatorOpts = append(aggregatorOpts, timeoutOpts...)
+	latencyConfig, err := loadLatencyConfig()
+	if err != nil {
+		return err
+	}
+	if latencyConfig != nil {
+		aggregatorOpts = append(aggregatorOpts, search.WithAdaptiveTimeouts(latency.NewTracker(*latencyConfig)))
+	}
+	aggregator := search.NewAggregator(providersList, searchTimeout, metrics, logger, aggregatorOpts...)
+	for _, p := range providersList {
+		metrics.RegisterTimeout(p.Name(), func() time.Duration { return aggregator.Timeout(p.Name()) })
+	}
+
+	// Initialize pricing rules (reloaded when the file changes)
+	rulesInterval, err := getEnvDuration("PRICING_RULES_RELOAD_INTERVAL", 10*time.Second)
+	if err != nil {
+		return err
+	}
+	pricing, err := markup.NewEngine(
+		getEnv("PRICING_RULES_FILE", "data/pricing_rules.json"),
+		rulesInterval,
 		logger,
+		markup.WithConverter(converter),
 	)
+	if err != nil {
+		return fmt.Errorf("failed to load pricing rules: %w", err)
+	}
+	defer pricing.Close()
 
 	// Initialize cache
 	searchCache := cache.NewCache(30 * time.Second)
@@ -52,17 +150,58 @@ func Run() error {
 	limiter := ratelimit.New(10, time.Minute)
 	defer limiter.Close()
 
+	// Initialize search parameter limits
+	limits, err := loadLimits()
+	if err != nil {
+		return err
+	}
+	limits.Currencies = converter
+
+	// Initialize city gazetteer for searches by coordinates
+	cities, err := geo.LoadFile(getEnv("GAZETTEER_FILE", "data/cities.json"))
+	if err != nil {
+		return err
+	}
+	if limits.Cities, err = geo.NewGazetteer(cities); err != nil {
+		return fmt.Errorf("invalid gazetteer: %w", err)
+	}
+
+	batch, err := loadBatchConfig()
+	if err != nil {
+		return err
+	}
+	fanOut := handler.DefaultFanOutConfig()
+	if fanOut.Concurrency, err = getEnvInt("FANOUT_CONCURRENCY", fanOut.Concurrency); err != nil {
+		return err
+	}
+	if fanOut.MaxConcurrent, err = getEnvInt("FANOUT_MAX_CONCURRENT", fanOut.MaxConcurrent); err != nil {
+		return err
+	}
+
 	// Initialize handler
-	h := handler.New(aggregator, searchCache, limiter, metrics, logger)
+	h := handler.New(aggregator, searchCache, limiter, metrics, logger,
+		handler.WithLimits(limits),
+		handler.WithBatch(batch),
+		handler.WithFanOut(fanOut),
+		handler.WithContent(contentStore),
+		handler.WithMarkup(pricing),
+	)
 
 	// Setup routes with logging middleware
 	mux := http.NewServeMux()
 	mux.HandleFunc("GET /search", h.SearchHandler)
+	mux.HandleFunc("POST /search", h.SearchJSONHandler)
+	mux.HandleFunc("POST /search/batch", h.BatchSearchHandler)
+	mux.HandleFunc("GET /search/flexible", h.FlexibleSearchHandler)
+	mux.HandleFunc("GET /search/multi", h.MultiCitySearchHandler)
+	mux.HandleFunc("GET /hotels/{id}", h.HotelHandler)
 	mux.HandleFunc("GET /healthz", obs.HealthHandler(logger))
 	mux.HandleFunc("GET /metrics", metrics.MetricsHandler())
+	mux.HandleFunc("GET /admin/mapping", hotelMapping.ReportHandler())
+	mux.HandleFunc("GET /admin/timeouts", aggregator.TimeoutsHandler())
 
 	// Wrap with middleware
-	wrappedHandler := middleware.Logging(logger)(mux)
+	wrappedHandler := middleware.Logging(logger)(middleware.APIKeys(mux))
 
 	// Configure server
 	srv := &http.Server{
@@ -107,3 +246,556 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// Provider protocols, selected with PROVIDERn_PROTOCOL.
+const (
+	protocolHTTP     = "http"
+	protocolXML      = "xml"
+	protocolGRPC     = "grpc"
+	protocolSnapshot = "snapshot"
+	protocolReplay   = "replay"
+)
+
+// providerConfig holds the defaults of a provider, matching the bundled
+// mock providers.
+type providerConfig struct {
+	name, url  string
+	disabled   bool
+	protocol   string
+	basis      providers.PriceBasis
+	schema     string
+	template   string
+	soapAction string
+}
+
+// loadProviders creates the clients of the enabled providers, along with
+// the aggregator options setting their search timeouts. Each provider's
+// protocol, price basis, response schema and request template default to
+// those of the bundled mock providers; the snapshot provider is disabled by
+// default. Per provider:
+//   - the client is built by newProvider, from PROVIDERn_URL and
+//     PROVIDERn_PROTOCOL;
+//   - searches time out after PROVIDERn_TIMEOUT, which defaults to the
+//     search timeout;
+//   - recording, budgets and bulkheads are added by limitProvider.
+func loadProviders(logger *slog.Logger, metrics *obs.Metrics, searchTimeout time.Duration) ([]providers.Provider, []search.Option, error) {
+	configs := []providerConfig{
+		{name: "provider1", url: "http://localhost:9001", basis: providers.PricePerStay},
+		{name: "provider2", url: "http://localhost:9002", basis: providers.PricePerNight},
+		{name: "provider3", url: "http://localhost:9003", basis: providers.PricePerNight},
+		{name: "provider4", url: "http://localhost:9004", basis: providers.PricePerStay, schema: "data/schemas/provider4.json"},
+		{
+			name: "provider5", url: "http://localhost:9005/search", protocol: protocolXML, basis: providers.PricePerNight,
+			schema: "data/schemas/provider5.json", template: "data/templates/provider5.xml", soapAction: "urn:lodging:availability#Search",
+		},
+		{name: "provider6", url: "localhost:9006", protocol: protocolGRPC, basis: providers.PricePerStay},
+		{name: "provider7", url: "data/snapshots", disabled: true, protocol: protocolSnapshot, basis: providers.PricePerNight},
+	}
+
+	list := make([]providers.Provider, 0, len(configs))
+	var timeouts []search.Option
+	for _, cfg := range configs {
+		prefix := strings.ToUpper(cfg.name)
+		enabled, err := getEnvBool(prefix+"_ENABLED", !cfg.disabled)
+		if err != nil {
+			closeProviders(list, logger)
+			return nil, nil, err
+		}
+		if !enabled {
+			continue
+		}
+		timeout, err := getEnvDuration(prefix+"_TIMEOUT", searchTimeout)
+		if err == nil && timeout <= 0 {
+			err = fmt.Errorf("invalid %s_TIMEOUT: must be positive", prefix)
+		}
+		if err != nil {
+			closeProviders(list, logger)
+			return nil, nil, err
+		}
+		p, err := newProvider(cfg, timeout, logger)
+		if err != nil {
+			closeProviders(list, logger)
+			return nil, nil, err
+		}
+		limited, err := limitProvider(p, metrics)
+		if err != nil {
+			closeProviders(append(list, p), logger)
+			return nil, nil, err
+		}
+		list = append(list, limited)
+		timeouts = append(timeouts, search.WithProviderTimeout(cfg.name, timeout))
+	}
+	return list, timeouts, nil
+}
+
+// limitProvider wraps a provider in the recorder, budget and bulkhead
+// configured for it. The bulkhead is outermost so that searches it rejects
+// don't use up the budget. Searches are recorded to PROVIDERn_RECORD_FILE.
+// A provider with a rate limit or daily quota is skipped once its budget is
+// used up, and its remaining budget is exported in metrics. A provider with
+// a maximum number of searches in flight rejects searches once that many,
+// plus the allowed number of queued ones, are pending.
+func limitProvider(p providers.Provider, metrics *obs.Metrics) (providers.Provider, error) {
+	prefix := strings.ToUpper(p.Name())
+	if path := os.Getenv(prefix + "_RECORD_FILE"); path != "" {
+		p = providers.NewRecorder(p, path)
+	}
+
+	budget, err := loadBudget(prefix)
+	if err != nil {
+		return nil, err
+	}
+	if len(budget) > 0 {
+		b := providers.NewBudgetedProvider(p, budget...)
+		if b.RateRemaining() >= 0 {
+			metrics.RegisterBudget(p.Name(), "second", b.RateRemaining)
+		}
+		if b.DailyRemaining() >= 0 {
+			metrics.RegisterBudget(p.Name(), "day", func() float64 { return float64(b.DailyRemaining()) })
+		}
+		p = b
+	}
+
+	maxInFlight, err := getEnvInt(prefix+"_MAX_INFLIGHT", 0)
+	if err != nil {
+		return nil, err
+	}
+	maxQueue, err := getEnvInt(prefix+"_MAX_QUEUE", 0)
+	if err != nil {
+		return nil, err
+	}
+	if maxInFlight < 0 || maxQueue < 0 {
+		return nil, fmt.Errorf("invalid %s bulkhead: max in-flight and queued searches must not be negative", prefix)
+	}
+	if maxInFlight > 0 {
+		b := providers.NewBulkheadProvider(p, maxInFlight, maxQueue)
+		metrics.RegisterBulkhead(p.Name(), func() obs.BulkheadStats { return obs.BulkheadStats(b.Stats()) })
+		p = b
+	}
+	return p, nil
+}
+
+// newProvider creates a provider client from its defaults and environment.
+// The URL of HTTP providers is a base URL, and they authenticate with the
+// secrets in PROVIDERn_AUTH_FILE. XML providers need a request template and
+// a schema, and their URL is the full endpoint. The URL of gRPC providers is
+// a gRPC target, that of snapshot providers a snapshot file or directory and
+// that of replay providers a cassette file.
+func newProvider(cfg providerConfig, timeout time.Duration, logger *slog.Logger) (providers.Provider, error) {
+	prefix := strings.ToUpper(cfg.name)
+	basis, err := providers.ParsePriceBasis(getEnv(prefix+"_PRICE_BASIS", string(cfg.basis)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s_PRICE_BASIS: %w", prefix, err)
+	}
+	var schema *providers.Schema
+	if path := getEnv(prefix+"_SCHEMA_FILE", cfg.schema); path != "" {
+		if schema, err = providers.LoadSchema(path); err != nil {
+			return nil, fmt.Errorf("invalid %s_SCHEMA_FILE: %w", prefix, err)
+		}
+	}
+	url := getEnv(prefix+"_URL", cfg.url)
+	protocol := getEnv(prefix+"_PROTOCOL", cmp.Or(cfg.protocol, protocolHTTP))
+	if protocol != protocolHTTP && os.Getenv(prefix+"_AUTH_FILE") != "" {
+		return nil, fmt.Errorf("%s_AUTH_FILE is only supported with the http protocol", prefix)
+	}
+	if protocol != protocolHTTP && protocol != protocolXML {
+		for _, setting := range transportSettings {
+			if os.Getenv(prefix+setting) != "" {
+				return nil, fmt.Errorf("%s%s is only supported with the http and xml protocols", prefix, setting)
+			}
+		}
+	}
+
+	switch protocol {
+	case protocolHTTP:
+		opts := []providers.HTTPOption{providers.WithPriceBasis(basis)}
+		if schema != nil {
+			opts = append(opts, providers.WithSchema(schema))
+		}
+		if path := os.Getenv(prefix + "_AUTH_FILE"); path != "" {
+			auth, err := providers.LoadAuth(path, timeout)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s_AUTH_FILE: %w", prefix, err)
+			}
+			opts = append(opts, auth)
+		}
+		transport, maxBytes, err := loadTransport(prefix)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, providers.WithTransport(transport), providers.WithMaxResponseBytes(maxBytes))
+		return providers.NewHTTPProvider(cfg.name, url, timeout, opts...), nil
+
+	case protocolXML:
+		path := getEnv(prefix+"_TEMPLATE_FILE", cfg.template)
+		if path == "" || schema == nil {
+			return nil, fmt.Errorf("%s_PROTOCOL %s requires %s_TEMPLATE_FILE and %s_SCHEMA_FILE", prefix, protocol, prefix, prefix)
+		}
+		request, err := providers.LoadRequestTemplate(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s_TEMPLATE_FILE: %w", prefix, err)
+		}
+		transport, maxBytes, err := loadTransport(prefix)
+		if err != nil {
+			return nil, err
+		}
+		return providers.NewXMLProvider(cfg.name, url, request, schema, timeout,
+			providers.WithXMLPriceBasis(basis),
+			providers.WithSOAPAction(getEnv(prefix+"_SOAP_ACTION", cfg.soapAction)),
+			providers.WithXMLTransport(transport),
+			providers.WithXMLMaxResponseBytes(maxBytes),
+		), nil
+
+	case protocolGRPC:
+		p, err := providers.NewGRPCProvider(cfg.name, url, timeout, providers.WithGRPCPriceBasis(basis))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s_URL: %w", prefix, err)
+		}
+		return p, nil
+
+	case protocolSnapshot:
+		interval, err := getEnvDuration(prefix+"_RELOAD_INTERVAL", 10*time.Second)
+		if err != nil {
+			return nil, err
+		}
+		p, err := providers.NewSnapshotProvider(cfg.name, url, interval, logger, providers.WithSnapshotPriceBasis(basis))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s_URL: %w", prefix, err)
+		}
+		return p, nil
+
+	case protocolReplay:
+		cassette, err := providers.LoadCassette(url)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s_URL: %w", prefix, err)
+		}
+		scale, err := getEnvFloat(prefix+"_REPLAY_LATENCY_SCALE", 1)
+		if err != nil {
+			return nil, err
+		}
+		opts := []providers.ReplayOption{providers.WithLatencyScale(scale)}
+		if match := os.Getenv(prefix + "_REPLAY_MATCH"); match != "" {
+			var fields []string
+			for field := range strings.SplitSeq(match, ",") {
+				fields = append(fields, strings.TrimSpace(field))
+			}
+			opts = append(opts, providers.WithMatch(fields...))
+		}
+		p, err := providers.NewReplayProvider(cfg.name, cassette, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s_REPLAY_MATCH: %w", prefix, err)
+		}
+		return p, nil
+
+	default:
+		return nil, fmt.Errorf("invalid %s_PROTOCOL: unknown protocol %q, expected http, xml, grpc, snapshot or replay", prefix, protocol)
+	}
+}
+
+// loadBudget reads the outbound budget of a provider: PROVIDERn_RATE_LIMIT
+// requests per second with bursts of PROVIDERn_RATE_BURST, and
+// PROVIDERn_DAILY_QUOTA requests per day. Zero limits are not enforced.
+func loadBudget(prefix string) ([]providers.BudgetOption, error) {
+	rate, err := getEnvFloat(prefix+"_RATE_LIMIT", 0)
+	if err != nil {
+		return nil, err
+	}
+	burst, err := getEnvInt(prefix+"_RATE_BURST", max(1, int(math.Ceil(rate))))
+	if err != nil {
+		return nil, err
+	}
+	quota, err := getEnvInt(prefix+"_DAILY_QUOTA", 0)
+	if err != nil {
+		return nil, err
+	}
+	if rate < 0 || burst < 1 || quota < 0 {
+		return nil, fmt.Errorf("invalid %s budget: rate limit and daily quota must not be negative, and burst must be at least 1", prefix)
+	}
+
+	var opts []providers.BudgetOption
+	if rate > 0 {
+		opts = append(opts, providers.WithRateLimit(rate, burst))
+	}
+	if quota > 0 {
+		opts = append(opts, providers.WithDailyQuota(quota))
+	}
+	return opts, nil
+}
+
+// transportSettings are the environment variable suffixes read by
+// loadTransport.
+var transportSettings = []string{
+	"_MAX_IDLE_CONNS_PER_HOST", "_IDLE_CONN_TIMEOUT", "_RESPONSE_HEADER_TIMEOUT",
+	"_TLS_MIN_VERSION", "_CA_FILE", "_PROXY_URL", "_HTTP2", "_MAX_RESPONSE_BYTES",
+}
+
+// loadTransport reads the connection settings and response size limit of an
+// HTTP or XML provider.
+func loadTransport(prefix string) (providers.TransportConfig, int64, error) {
+	var cfg providers.TransportConfig
+	var err error
+	if cfg.MaxIdleConnsPerHost, err = getEnvInt(prefix+"_MAX_IDLE_CONNS_PER_HOST", 0); err != nil {
+		return cfg, 0, err
+	}
+	if cfg.IdleConnTimeout, err = getEnvDuration(prefix+"_IDLE_CONN_TIMEOUT", 0); err != nil {
+		return cfg, 0, err
+	}
+	if cfg.ResponseHeaderTimeout, err = getEnvDuration(prefix+"_RESPONSE_HEADER_TIMEOUT", 0); err != nil {
+		return cfg, 0, err
+	}
+	if cfg.MaxIdleConnsPerHost < 0 || cfg.IdleConnTimeout < 0 || cfg.ResponseHeaderTimeout < 0 {
+		return cfg, 0, fmt.Errorf("invalid %s transport: idle connections and timeouts must not be negative", prefix)
+	}
+	if version := os.Getenv(prefix + "_TLS_MIN_VERSION"); version != "" {
+		if cfg.TLSMinVersion, err = providers.ParseTLSVersion(version); err != nil {
+			return cfg, 0, fmt.Errorf("invalid %s_TLS_MIN_VERSION: %w", prefix, err)
+		}
+	}
+	if path := os.Getenv(prefix + "_CA_FILE"); path != "" {
+		if cfg.RootCAs, err = providers.LoadCAFile(path); err != nil {
+			return cfg, 0, fmt.Errorf("invalid %s_CA_FILE: %w", prefix, err)
+		}
+	}
+	if proxy := os.Getenv(prefix + "_PROXY_URL"); proxy != "" {
+		if cfg.Proxy, err = url.Parse(proxy); err != nil || cfg.Proxy.Host == "" {
+			return cfg, 0, fmt.Errorf("invalid %s_PROXY_URL %q", prefix, proxy)
+		}
+	}
+	http2, err := getEnvBool(prefix+"_HTTP2", true)
+	if err != nil {
+		return cfg, 0, err
+	}
+	cfg.DisableHTTP2 = !http2
+	maxBytes, err := getEnvInt(prefix+"_MAX_RESPONSE_BYTES", providers.DefaultMaxResponseBytes)
+	if err != nil {
+		return cfg, 0, err
+	}
+	if maxBytes <= 0 {
+		return cfg, 0, fmt.Errorf("invalid %s_MAX_RESPONSE_BYTES: must be positive", prefix)
+	}
+
+	return cfg, int64(maxBytes), nil
+}
+
+// closeProviders closes the providers that hold connections, reload files
+// or record searches.
+func closeProviders(list []providers.Provider, logger *slog.Logger) {
+	for _, p := range list {
+		if c, ok := p.(io.Closer); ok {
+			if err := c.Close(); err != nil {
+				logger.Error("failed to close provider", "provider", p.Name(), "error", err)
+			}
+		}
+	}
+}
+
+// loadPipelines builds the normalization pipeline of each provider from
+// PROVIDERn_PIPELINE, a comma-separated list of step names. Providers
+// without one use all the given steps.
+func loadPipelines(list []providers.Provider, steps []normalize.Step) ([]search.Option, error) {
+	registry := normalize.Registry{}
+	registry.Register(steps...)
+
+	var opts []search.Option
+	for _, p := range list {
+		key := strings.ToUpper(p.Name()) + "_PIPELINE"
+		value := os.Getenv(key)
+		if value == "" {
+			continue
+		}
+		pipeline, err := registry.Build(strings.Split(value, ","))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", key, err)
+		}
+		opts = append(opts, search.WithPipeline(p.Name(), pipeline))
+	}
+	return opts, nil
+}
+
+// loadLimits builds search parameter limits from the environment. A zero
+// maximum is not enforced.
+func loadLimits() (handler.Limits, error) {
+	limits := handler.DefaultLimits()
+
+	var err error
+	if limits.MaxNights, err = getEnvInt("MAX_NIGHTS", limits.MaxNights); err != nil {
+		return limits, err
+	}
+	if limits.MaxAdults, err = getEnvInt("MAX_ADULTS", limits.MaxAdults); err != nil {
+		return limits, err
+	}
+	if limits.MaxChildren, err = getEnvInt("MAX_CHILDREN", limits.MaxChildren); err != nil {
+		return limits, err
+	}
+	if limits.MaxRooms, err = getEnvInt("MAX_ROOMS", limits.MaxRooms); err != nil {
+		return limits, err
+	}
+	if limits.MaxHorizonDays, err = getEnvInt("MAX_BOOKING_HORIZON_DAYS", limits.MaxHorizonDays); err != nil {
+		return limits, err
+	}
+	if limits.MaxFlexibleDates, err = getEnvInt("MAX_FLEXIBLE_DATES", limits.MaxFlexibleDates); err != nil {
+		return limits, err
+	}
+	if limits.MaxCities, err = getEnvInt("MAX_CITIES", limits.MaxCities); err != nil {
+		return limits, err
+	}
+	if limits.MaxRadiusKm, err = getEnvFloat("MAX_RADIUS_KM", limits.MaxRadiusKm); err != nil {
+		return limits, err
+	}
+	maxBodyBytes, err := getEnvInt("MAX_BODY_BYTES", int(limits.MaxBodyBytes))
+	if err != nil {
+		return limits, err
+	}
+	limits.MaxBodyBytes = int64(maxBodyBytes)
+	if min(limits.MaxNights, limits.MaxAdults, limits.MaxChildren, limits.MaxRooms, limits.MaxHorizonDays, limits.MaxFlexibleDates, limits.MaxCities) < 0 ||
+		limits.MaxBodyBytes < 0 || !(limits.MaxRadiusKm >= 0) || math.IsInf(limits.MaxRadiusKm, 1) {
+		return limits, errors.New("invalid search limits: maximums must be finite and not negative")
+	}
+	if limits.MaxAdults > 0 && limits.MaxRooms > limits.MaxAdults {
+		return limits, errors.New("invalid search limits: MAX_ROOMS must not exceed MAX_ADULTS, since every room needs an adult")
+	}
+	if limits.Location, err = time.LoadLocation(getEnv("SEARCH_TIMEZONE", "UTC")); err != nil {
+		return limits, fmt.Errorf("invalid SEARCH_TIMEZONE: %w", err)
+	}
+
+	return limits, nil
+}
+
+// loadAnomalyConfig builds price anomaly detection settings from the
+// environment. It returns nil if detection is turned off.
+func loadAnomalyConfig() (*anomaly.Config, error) {
+	cfg := anomaly.DefaultConfig()
+
+	action := getEnv("PRICE_ANOMALY_ACTION", string(cfg.Action))
+	if strings.EqualFold(action, "off") {
+		return nil, nil
+	}
+	var err error
+	if cfg.Action, err = anomaly.ParseAction(action); err != nil {
+		return nil, fmt.Errorf("invalid PRICE_ANOMALY_ACTION: %w", err)
+	}
+	if cfg.PeerFactor, err = getEnvFloat("PRICE_ANOMALY_PEER_FACTOR", cfg.PeerFactor); err != nil {
+		return nil, err
+	}
+	if cfg.CityFactor, err = getEnvFloat("PRICE_ANOMALY_CITY_FACTOR", cfg.CityFactor); err != nil {
+		return nil, err
+	}
+	if !validFactor(cfg.PeerFactor) || !validFactor(cfg.CityFactor) {
+		return nil, errors.New("invalid price anomaly factors: PRICE_ANOMALY_PEER_FACTOR and PRICE_ANOMALY_CITY_FACTOR must be finite and greater than 1")
+	}
+	if cfg.MinPeers < 0 || cfg.MinCitySamples < 0 || cfg.WindowSize < 0 || cfg.MaxWindows < 0 {
+		return nil, errors.New("invalid price anomaly settings: sample counts and window sizes must not be negative")
+	}
+
+	return &cfg, nil
+}
+
+// validFactor reports whether f bounds a deviation: finite and greater
+// than 1.
+func validFactor(f float64) bool {
+	return f > 1 && !math.IsInf(f, 1)
+}
+
+// loadLatencyConfig builds adaptive provider timeout settings from the
+// environment. Returns nil if adaptive timeouts are turned off.
+func loadLatencyConfig() (*latency.Config, error) {
+	cfg := latency.DefaultConfig()
+
+	enabled, err := getEnvBool("ADAPTIVE_TIMEOUTS", true)
+	if err != nil || !enabled {
+		return nil, err
+	}
+	if cfg.Quantile, err = getEnvFloat("ADAPTIVE_TIMEOUT_QUANTILE", cfg.Quantile); err != nil {
+		return nil, err
+	}
+	if cfg.Factor, err = getEnvFloat("ADAPTIVE_TIMEOUT_FACTOR", cfg.Factor); err != nil {
+		return nil, err
+	}
+	if cfg.Floor, err = getEnvDuration("ADAPTIVE_TIMEOUT_FLOOR", cfg.Floor); err != nil {
+		return nil, err
+	}
+	if cfg.MinSamples, err = getEnvInt("ADAPTIVE_TIMEOUT_MIN_SAMPLES", cfg.MinSamples); err != nil {
+		return nil, err
+	}
+	if cfg.WindowSize, err = getEnvInt("ADAPTIVE_TIMEOUT_WINDOW", cfg.WindowSize); err != nil {
+		return nil, err
+	}
+	if cfg.Quantile <= 0 || cfg.Quantile > 1 || cfg.Factor < 1 || cfg.Floor < 0 || cfg.WindowSize < 1 || cfg.MinSamples > cfg.WindowSize {
+		return nil, errors.New("invalid adaptive timeouts: quantile must be in (0, 1], factor at least 1, floor not negative, and the window at least 1 and no smaller than the minimum samples")
+	}
+
+	return &cfg, nil
+}
+
+// loadBatchConfig builds batch search settings from the environment.
+func loadBatchConfig() (handler.BatchConfig, error) {
+	cfg := handler.DefaultBatchConfig()
+
+	var err error
+	if cfg.MaxItems, err = getEnvInt("BATCH_MAX_ITEMS", cfg.MaxItems); err != nil {
+		return cfg, err
+	}
+	if cfg.Concurrency, err = getEnvInt("BATCH_CONCURRENCY", cfg.Concurrency); err != nil {
+		return cfg, err
+	}
+	if cfg.Weight, err = getEnvFloat("BATCH_WEIGHT", cfg.Weight); err != nil {
+		return cfg, err
+	}
+	if cfg.MaxItems < 1 || cfg.Concurrency < 1 || !(cfg.Weight >= 0) || math.IsInf(cfg.Weight, 1) {
+		return cfg, errors.New("invalid batch settings: BATCH_MAX_ITEMS and BATCH_CONCURRENCY must be at least 1, and BATCH_WEIGHT finite and not negative")
+	}
+
+	return cfg, nil
+}
+
+// getEnvBool gets a boolean environment variable with a default fallback.
+func getEnvBool(key string, defaultValue bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return b, nil
+}
+
+// getEnvInt gets an integer environment variable with a default fallback.
+func getEnvInt(key string, defaultValue int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return n, nil
+}
+
+// getEnvFloat gets a float environment variable with a default fallback.
+func getEnvFloat(key string, defaultValue float64) (float64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return f, nil
+}
+
+// getEnvDuration gets a duration environment variable with a default fallback.
+func getEnvDuration(key string, defaultValue time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return d, nil
+}
@@ -0,0 +1,118 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/alex-user-go/hotels/internal/providers"
+)
+
+func TestLoadLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr bool
+	}{
+		{name: "defaults"},
+		{name: "unlimited", env: map[string]string{"MAX_NIGHTS": "0", "MAX_RADIUS_KM": "0"}},
+		{name: "negative nights", env: map[string]string{"MAX_NIGHTS": "-1"}, wantErr: true},
+		{name: "negative body", env: map[string]string{"MAX_BODY_BYTES": "-1"}, wantErr: true},
+		{name: "NaN radius", env: map[string]string{"MAX_RADIUS_KM": "NaN"}, wantErr: true},
+		{name: "infinite radius", env: map[string]string{"MAX_RADIUS_KM": "+Inf"}, wantErr: true},
+		{name: "more rooms than adults", env: map[string]string{"MAX_ADULTS": "2", "MAX_ROOMS": "3"}, wantErr: true},
+		{name: "unlimited adults", env: map[string]string{"MAX_ADULTS": "0", "MAX_ROOMS": "3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			if _, err := loadLimits(); (err != nil) != tt.wantErr {
+				t.Errorf("loadLimits() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadBatchConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr bool
+	}{
+		{name: "defaults"},
+		{name: "free", env: map[string]string{"BATCH_WEIGHT": "0"}},
+		{name: "no items", env: map[string]string{"BATCH_MAX_ITEMS": "0"}, wantErr: true},
+		{name: "no concurrency", env: map[string]string{"BATCH_CONCURRENCY": "0"}, wantErr: true},
+		{name: "negative weight", env: map[string]string{"BATCH_WEIGHT": "-0.5"}, wantErr: true},
+		{name: "NaN weight", env: map[string]string{"BATCH_WEIGHT": "NaN"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			if _, err := loadBatchConfig(); (err != nil) != tt.wantErr {
+				t.Errorf("loadBatchConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadAnomalyConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		wantErr bool
+	}{
+		{name: "defaults"},
+		{name: "off", env: map[string]string{"PRICE_ANOMALY_ACTION": "off", "PRICE_ANOMALY_PEER_FACTOR": "NaN"}},
+		{name: "NaN factor", env: map[string]string{"PRICE_ANOMALY_PEER_FACTOR": "NaN"}, wantErr: true},
+		{name: "infinite factor", env: map[string]string{"PRICE_ANOMALY_CITY_FACTOR": "Inf"}, wantErr: true},
+		{name: "factor of 1", env: map[string]string{"PRICE_ANOMALY_CITY_FACTOR": "1"}, wantErr: true},
+		{name: "factor below 1", env: map[string]string{"PRICE_ANOMALY_PEER_FACTOR": "0.5"}, wantErr: true},
+		{name: "factor above 1", env: map[string]string{"PRICE_ANOMALY_PEER_FACTOR": "1.5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			if _, err := loadAnomalyConfig(); (err != nil) != tt.wantErr {
+				t.Errorf("loadAnomalyConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadTransport(t *testing.T) {
+	tests := []struct {
+		name      string
+		env       map[string]string
+		wantBytes int64
+		wantErr   bool
+	}{
+		{name: "defaults", wantBytes: providers.DefaultMaxResponseBytes},
+		{name: "max response bytes", env: map[string]string{"PROVIDER1_MAX_RESPONSE_BYTES": "1024"}, wantBytes: 1024},
+		{name: "zero max response bytes", env: map[string]string{"PROVIDER1_MAX_RESPONSE_BYTES": "0"}, wantErr: true},
+		{name: "negative max response bytes", env: map[string]string{"PROVIDER1_MAX_RESPONSE_BYTES": "-1"}, wantErr: true},
+		{name: "negative idle connections", env: map[string]string{"PROVIDER1_MAX_IDLE_CONNS_PER_HOST": "-1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			_, maxBytes, err := loadTransport("PROVIDER1")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadTransport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if maxBytes != tt.wantBytes {
+				t.Errorf("max response bytes = %d, want %d", maxBytes, tt.wantBytes)
+			}
+		})
+	}
+}
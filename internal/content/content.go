@@ -0,0 +1,281 @@
+// Package content stores descriptive hotel content: address, star rating,
+// location, amenities and images.
+package content
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alex-user-go/hotels/internal/providers"
+	"github.com/alex-user-go/hotels/internal/search/types"
+)
+
+// ErrNotFound is returned when no content is known for a hotel.
+var ErrNotFound = errors.New("hotel not found")
+
+// DefaultMaxLearned caps the hotels whose provider content is kept.
+const DefaultMaxLearned = 10000
+
+// Hotel is the content of a hotel, keyed by its canonical ID.
+type Hotel struct {
+	HotelID   string          `json:"hotel_id"`
+	Name      string          `json:"name"`
+	City      string          `json:"city,omitempty"`
+	Address   string          `json:"address,omitempty"`
+	Stars     float64         `json:"stars,omitempty"`
+	Location  *types.Location `json:"location,omitempty"`
+	Amenities []string        `json:"amenities,omitempty"`
+	Images    []string        `json:"images,omitempty"`
+}
+
+// csvColumns is the header expected in CSV datasets. Amenities and images
+// are separated by "|".
+var csvColumns = []string{"hotel_id", "name", "city", "address", "stars", "latitude", "longitude", "amenities", "images"}
+
+// LoadFile reads a content dataset. Files ending in .csv are read as CSV,
+// anything else as a JSON array of hotels.
+func LoadFile(path string) ([]Hotel, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content file: %w", err)
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return readCSV(f)
+	}
+
+	var hotels []Hotel
+	if err := json.NewDecoder(f).Decode(&hotels); err != nil {
+		return nil, fmt.Errorf("failed to parse content file: %w", err)
+	}
+	return hotels, nil
+}
+
+// readCSV parses a CSV dataset with a csvColumns header.
+func readCSV(r io.Reader) ([]Hotel, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(csvColumns)
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content header: %w", err)
+	}
+	for i, name := range csvColumns {
+		if strings.TrimSpace(header[i]) != name {
+			return nil, fmt.Errorf("content column %d must be %q, got %q", i+1, name, header[i])
+		}
+	}
+
+	var hotels []Hotel
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return hotels, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse content file: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		h := Hotel{
+			HotelID:   strings.TrimSpace(record[0]),
+			Name:      strings.TrimSpace(record[1]),
+			City:      strings.TrimSpace(record[2]),
+			Address:   strings.TrimSpace(record[3]),
+			Amenities: splitList(record[7]),
+			Images:    splitList(record[8]),
+		}
+		if s := strings.TrimSpace(record[4]); s != "" {
+			if h.Stars, err = strconv.ParseFloat(s, 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid stars %q", line, s)
+			}
+		}
+		lat, lon := strings.TrimSpace(record[5]), strings.TrimSpace(record[6])
+		if lat != "" || lon != "" {
+			loc := &types.Location{}
+			if loc.Latitude, err = strconv.ParseFloat(lat, 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid latitude %q", line, lat)
+			}
+			if loc.Longitude, err = strconv.ParseFloat(lon, 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid longitude %q", line, lon)
+			}
+			h.Location = loc
+		}
+		hotels = append(hotels, h)
+	}
+}
+
+// splitList splits a "|"-separated CSV field, dropping empty items.
+func splitList(s string) []string {
+	var items []string
+	for item := range strings.SplitSeq(s, "|") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Store serves hotel content from a catalog. Fields missing from the
+// catalog, and hotels missing altogether, are filled in from content
+// providers send with their offers; the catalog always wins.
+type Store struct {
+	catalog    map[string]Hotel
+	maxLearned int
+
+	mu      sync.RWMutex
+	learned map[string]Hotel
+}
+
+// StoreOption configures a Store.
+type StoreOption func(*Store)
+
+// WithMaxLearned caps the hotels whose provider content is kept. Past the
+// cap, an arbitrary hotel's content is forgotten for each new one and is
+// learned again when next seen. Defaults to DefaultMaxLearned.
+func WithMaxLearned(n int) StoreOption {
+	return func(s *Store) {
+		s.maxLearned = n
+	}
+}
+
+// NewStore creates a Store from a catalog. It fails if a hotel has no ID
+// or an ID is repeated.
+func NewStore(catalog []Hotel, opts ...StoreOption) (*Store, error) {
+	s := &Store{
+		catalog:    make(map[string]Hotel, len(catalog)),
+		maxLearned: DefaultMaxLearned,
+		learned:    make(map[string]Hotel),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	for i, h := range catalog {
+		h.HotelID = strings.TrimSpace(h.HotelID)
+		if h.HotelID == "" {
+			return nil, fmt.Errorf("hotel %d has no hotel_id", i)
+		}
+		if _, ok := s.catalog[h.HotelID]; ok {
+			return nil, fmt.Errorf("duplicate hotel_id %q", h.HotelID)
+		}
+		s.catalog[h.HotelID] = h
+	}
+	return s, nil
+}
+
+// Learn records the content a provider sent for a hotel. Only fields still
+// unknown are kept, so the first provider to describe a field wins.
+func (s *Store) Learn(hotelID string, h providers.Hotel) {
+	offered := Hotel{
+		HotelID:   hotelID,
+		Name:      strings.TrimSpace(h.Name),
+		City:      strings.TrimSpace(h.City),
+		Address:   strings.TrimSpace(h.Address),
+		Stars:     h.Stars,
+		Amenities: h.Amenities,
+		Images:    h.Images,
+	}
+	if h.Latitude != nil && h.Longitude != nil {
+		offered.Location = &types.Location{Latitude: *h.Latitude, Longitude: *h.Longitude}
+	}
+
+	s.mu.RLock()
+	known, ok := s.learned[hotelID]
+	s.mu.RUnlock()
+	if ok && !hasGaps(known, offered) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	known, ok = s.learned[hotelID]
+	if !ok && len(s.learned) >= s.maxLearned {
+		// Forget an arbitrary hotel to stay under the cap
+		for id := range s.learned {
+			delete(s.learned, id)
+			break
+		}
+	}
+	if s.maxLearned > 0 {
+		s.learned[hotelID] = merge(known, offered)
+	}
+}
+
+// Get returns the content of a hotel, or ErrNotFound.
+func (s *Store) Get(hotelID string) (Hotel, error) {
+	s.mu.RLock()
+	learned, isLearned := s.learned[hotelID]
+	s.mu.RUnlock()
+
+	h, inCatalog := s.catalog[hotelID]
+	if !inCatalog && !isLearned {
+		return Hotel{}, ErrNotFound
+	}
+	h = merge(h, learned)
+	h.HotelID = hotelID
+	return h, nil
+}
+
+// Enrich adds the known content of a hotel to a search result.
+func (s *Store) Enrich(h *types.Hotel) {
+	c, err := s.Get(h.HotelID)
+	if err != nil {
+		return
+	}
+	h.Address = c.Address
+	h.Stars = c.Stars
+	h.Location = c.Location
+	h.Amenities = c.Amenities
+	if len(c.Images) > 0 {
+		h.Image = c.Images[0]
+	}
+}
+
+// merge fills the empty fields of h from fallback.
+func merge(h, fallback Hotel) Hotel {
+	if h.HotelID == "" {
+		h.HotelID = fallback.HotelID
+	}
+	if h.Name == "" {
+		h.Name = fallback.Name
+	}
+	if h.City == "" {
+		h.City = fallback.City
+	}
+	if h.Address == "" {
+		h.Address = fallback.Address
+	}
+	if h.Stars == 0 {
+		h.Stars = fallback.Stars
+	}
+	if h.Location == nil {
+		h.Location = fallback.Location
+	}
+	if len(h.Amenities) == 0 {
+		h.Amenities = fallback.Amenities
+	}
+	if len(h.Images) == 0 {
+		h.Images = fallback.Images
+	}
+	return h
+}
+
+// hasGaps reports whether offered would fill any empty field of known.
+func hasGaps(known, offered Hotel) bool {
+	return (known.Name == "" && offered.Name != "") ||
+		(known.City == "" && offered.City != "") ||
+		(known.Address == "" && offered.Address != "") ||
+		(known.Stars == 0 && offered.Stars != 0) ||
+		(known.Location == nil && offered.Location != nil) ||
+		(len(known.Amenities) == 0 && len(offered.Amenities) > 0) ||
+		(len(known.Images) == 0 && len(offered.Images) > 0)
+}
@@ -0,0 +1,186 @@
+package content_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alex-user-go/hotels/internal/content"
+	"github.com/alex-user-go/hotels/internal/providers"
+	"github.com/alex-user-go/hotels/internal/search/types"
+)
+
+func writeFile(t *testing.T, name, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadFile(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		data string
+	}{
+		{
+			name: "json",
+			file: "hotels.json",
+			data: `[{"hotel_id": "H001", "name": "Grand Hotel", "address": "1 Grand Avenue", "stars": 4.5,
+				"location": {"latitude": 48.8566, "longitude": 2.3522}, "amenities": ["wifi", "spa"], "images": ["a.jpg"]}]`,
+		},
+		{
+			name: "csv",
+			file: "hotels.csv",
+			data: "hotel_id,name,city,address,stars,latitude,longitude,amenities,images\n" +
+				"H001,Grand Hotel,,1 Grand Avenue,4.5,48.8566,2.3522,wifi|spa,a.jpg\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hotels, err := content.LoadFile(writeFile(t, tt.file, tt.data))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(hotels) != 1 {
+				t.Fatalf("expected 1 hotel, got %d", len(hotels))
+			}
+			h := hotels[0]
+			if h.HotelID != "H001" || h.Address != "1 Grand Avenue" || h.Stars != 4.5 {
+				t.Errorf("unexpected hotel: %+v", h)
+			}
+			if h.Location == nil || h.Location.Latitude != 48.8566 || h.Location.Longitude != 2.3522 {
+				t.Errorf("unexpected location: %+v", h.Location)
+			}
+			if len(h.Amenities) != 2 || len(h.Images) != 1 {
+				t.Errorf("unexpected amenities %v or images %v", h.Amenities, h.Images)
+			}
+		})
+	}
+}
+
+func TestLoadFile_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		data string
+	}{
+		{name: "malformed json", file: "hotels.json", data: `{"hotel_id": "H001"}`},
+		{name: "wrong csv header", file: "hotels.csv", data: "id,name,city,address,stars,latitude,longitude,amenities,images\n"},
+		{name: "invalid stars", file: "hotels.csv", data: "hotel_id,name,city,address,stars,latitude,longitude,amenities,images\nH001,A,,,five,,,,\n"},
+		{name: "half a location", file: "hotels.csv", data: "hotel_id,name,city,address,stars,latitude,longitude,amenities,images\nH001,A,,,,48.8,,,\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := content.LoadFile(writeFile(t, tt.file, tt.data)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestStore_CatalogGapsFilledByProviders(t *testing.T) {
+	store, err := content.NewStore([]content.Hotel{
+		{HotelID: "H001", Name: "Grand Hotel", Stars: 5, Images: []string{"catalog.jpg"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	lat, lon := 48.8566, 2.3522
+	store.Learn("H001", providers.Hotel{Name: "The Grand", Address: "1 Grand Avenue", Stars: 3, Images: []string{"provider.jpg"}})
+	store.Learn("H001", providers.Hotel{Address: "Elsewhere", Latitude: &lat, Longitude: &lon})
+	store.Learn("H009", providers.Hotel{Name: "Seaside Resort", Stars: 4})
+
+	h, err := store.Get("H001")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Catalog fields win, gaps come from the first provider that sent them
+	if h.Name != "Grand Hotel" || h.Stars != 5 || h.Images[0] != "catalog.jpg" {
+		t.Errorf("catalog content overridden: %+v", h)
+	}
+	if h.Address != "1 Grand Avenue" {
+		t.Errorf("address = %q, want the first provider's", h.Address)
+	}
+	if h.Location == nil || h.Location.Latitude != lat {
+		t.Errorf("location = %+v, want the second provider's", h.Location)
+	}
+
+	// Hotels missing from the catalog are served from provider content
+	if h, err := store.Get("H009"); err != nil || h.Name != "Seaside Resort" || h.Stars != 4 {
+		t.Errorf("Get(H009) = %+v, %v", h, err)
+	}
+
+	if _, err := store.Get("H404"); !errors.Is(err, content.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestStore_MaxLearned(t *testing.T) {
+	store, err := content.NewStore([]content.Hotel{
+		{HotelID: "H001", Name: "Grand Hotel"},
+	}, content.WithMaxLearned(2))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, id := range []string{"H001", "p3:X-1", "p3:X-2", "p3:X-3"} {
+		store.Learn(id, providers.Hotel{Name: "Hotel " + id, Stars: 3})
+	}
+
+	// At most two hotels are remembered
+	var learned int
+	for _, id := range []string{"p3:X-1", "p3:X-2", "p3:X-3"} {
+		if _, err := store.Get(id); err == nil {
+			learned++
+		}
+	}
+	if learned > 2 {
+		t.Errorf("%d learned hotels kept, want at most 2", learned)
+	}
+	if _, err := store.Get("p3:X-3"); err != nil {
+		t.Errorf("Get(p3:X-3) = %v, want the latest hotel kept", err)
+	}
+
+	// Catalog hotels are unaffected
+	if h, err := store.Get("H001"); err != nil || h.Name != "Grand Hotel" {
+		t.Errorf("Get(H001) = %+v, %v", h, err)
+	}
+}
+
+func TestStore_Enrich(t *testing.T) {
+	store, err := content.NewStore([]content.Hotel{
+		{HotelID: "H001", Name: "Grand Hotel", Address: "1 Grand Avenue", Stars: 5,
+			Amenities: []string{"wifi"}, Images: []string{"a.jpg", "b.jpg"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	h := types.Hotel{HotelID: "H001", Name: "Grand Hotel", Price: 100}
+	store.Enrich(&h)
+	if h.Address != "1 Grand Avenue" || h.Stars != 5 || len(h.Amenities) != 1 || h.Image != "a.jpg" {
+		t.Errorf("unexpected enriched hotel: %+v", h)
+	}
+
+	unknown := types.Hotel{HotelID: "H404", Name: "Unknown", Price: 100}
+	store.Enrich(&unknown)
+	if unknown.Address != "" || unknown.Image != "" {
+		t.Errorf("unknown hotel should not be enriched: %+v", unknown)
+	}
+}
+
+func TestNewStore_Invalid(t *testing.T) {
+	if _, err := content.NewStore([]content.Hotel{{Name: "No ID"}}); err == nil {
+		t.Error("expected error for missing hotel_id")
+	}
+	if _, err := content.NewStore([]content.Hotel{{HotelID: "H001"}, {HotelID: "H001"}}); err == nil {
+		t.Error("expected error for duplicate hotel_id")
+	}
+}
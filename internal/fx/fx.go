@@ -0,0 +1,180 @@
+package fx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnknownCurrency is returned when no rate is known for a currency.
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// Rates holds exchange rates relative to a base currency.
+// Each rate is the number of units of the currency per one unit of Base.
+type Rates struct {
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+// Source loads exchange rates.
+type Source interface {
+	// Rates returns the latest known exchange rates.
+	Rates(ctx context.Context) (*Rates, error)
+}
+
+// FileSource loads exchange rates from a local JSON file.
+type FileSource struct {
+	path string
+}
+
+// NewFileSource creates a new FileSource reading from path.
+func NewFileSource(path string) *FileSource {
+	return &FileSource{path: path}
+}
+
+// Rates reads and parses the rates file.
+func (s *FileSource) Rates(_ context.Context) (*Rates, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rates file: %w", err)
+	}
+
+	var rates Rates
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, fmt.Errorf("failed to parse rates file: %w", err)
+	}
+
+	return &rates, nil
+}
+
+// Converter converts amounts between currencies using rates from a Source.
+// Rates are refreshed periodically in the background; on refresh failure
+// the last successfully loaded rates are kept.
+type Converter struct {
+	mu       sync.RWMutex
+	base     string
+	rates    map[string]float64
+	source   Source
+	interval time.Duration
+	logger   *slog.Logger
+	done     chan struct{}
+}
+
+// NewConverter creates a new Converter and performs the initial load.
+// A non-positive interval disables background refresh.
+func NewConverter(ctx context.Context, source Source, interval time.Duration, logger *slog.Logger) (*Converter, error) {
+	c := &Converter{
+		source:   source,
+		interval: interval,
+		logger:   logger,
+		done:     make(chan struct{}),
+	}
+
+	if err := c.Refresh(ctx); err != nil {
+		return nil, err
+	}
+
+	// Start background refresh
+	if interval > 0 {
+		go c.refreshLoop()
+	}
+
+	return c, nil
+}
+
+// Close stops the background refresh goroutine.
+func (c *Converter) Close() {
+	close(c.done)
+}
+
+// Refresh reloads rates from the source.
+func (c *Converter) Refresh(ctx context.Context) error {
+	loaded, err := c.source.Rates(ctx)
+	if err != nil {
+		return err
+	}
+
+	base := normalizeCode(loaded.Base)
+	if base == "" {
+		return fmt.Errorf("rates have no base currency")
+	}
+
+	rates := make(map[string]float64, len(loaded.Rates)+1)
+	for code, rate := range loaded.Rates {
+		if rate <= 0 {
+			return fmt.Errorf("invalid rate %v for %s", rate, code)
+		}
+		rates[normalizeCode(code)] = rate
+	}
+	rates[base] = 1
+
+	c.mu.Lock()
+	c.base = base
+	c.rates = rates
+	c.mu.Unlock()
+
+	return nil
+}
+
+// Convert converts amount from one currency to another.
+// The result is rounded to two decimal places.
+func (c *Converter) Convert(amount float64, from, to string) (float64, error) {
+	from = normalizeCode(from)
+	to = normalizeCode(to)
+	if from == to {
+		return amount, nil
+	}
+
+	c.mu.RLock()
+	fromRate, fromOK := c.rates[from]
+	toRate, toOK := c.rates[to]
+	c.mu.RUnlock()
+
+	if !fromOK {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownCurrency, from)
+	}
+	if !toOK {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownCurrency, to)
+	}
+
+	converted := amount / fromRate * toRate
+	return math.Round(converted*100) / 100, nil
+}
+
+// Supports reports whether a rate is known for the currency.
+func (c *Converter) Supports(currency string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.rates[normalizeCode(currency)]
+	return ok
+}
+
+// refreshLoop periodically reloads rates from the source.
+func (c *Converter) refreshLoop() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), c.interval)
+			if err := c.Refresh(ctx); err != nil {
+				c.logger.Error("failed to refresh exchange rates", "error", err)
+			}
+			cancel()
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
@@ -0,0 +1,132 @@
+package fx_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alex-user-go/hotels/internal/fx"
+)
+
+// staticSource is a test source returning predefined rates.
+type staticSource struct {
+	rates *fx.Rates
+	err   error
+}
+
+func (s *staticSource) Rates(ctx context.Context) (*fx.Rates, error) {
+	return s.rates, s.err
+}
+
+func TestConverter_Convert(t *testing.T) {
+	source := &staticSource{rates: &fx.Rates{
+		Base:  "EUR",
+		Rates: map[string]float64{"USD": 1.25, "gbp": 0.8},
+	}}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	c, err := fx.NewConverter(context.Background(), source, 0, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	tests := []struct {
+		name    string
+		amount  float64
+		from    string
+		to      string
+		want    float64
+		wantErr error
+	}{
+		{name: "same currency", amount: 100, from: "EUR", to: "EUR", want: 100},
+		{name: "base to quote", amount: 100, from: "EUR", to: "USD", want: 125},
+		{name: "quote to base", amount: 125, from: "USD", to: "EUR", want: 100},
+		{name: "cross rate", amount: 125, from: "USD", to: "GBP", want: 80},
+		{name: "case insensitive", amount: 100, from: "eur", to: "usd", want: 125},
+		{name: "rounded to cents", amount: 10, from: "USD", to: "GBP", want: 6.4},
+		{name: "unknown source", amount: 100, from: "JPY", to: "EUR", wantErr: fx.ErrUnknownCurrency},
+		{name: "unknown target", amount: 100, from: "EUR", to: "JPY", wantErr: fx.ErrUnknownCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Convert(tt.amount, tt.from, tt.to)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Convert() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConverter_RefreshKeepsRatesOnFailure(t *testing.T) {
+	source := &staticSource{rates: &fx.Rates{Base: "EUR", Rates: map[string]float64{"USD": 2}}}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	c, err := fx.NewConverter(context.Background(), source, 0, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer c.Close()
+
+	source.err = errors.New("source down")
+	if err := c.Refresh(context.Background()); err == nil {
+		t.Fatal("expected refresh error, got nil")
+	}
+
+	got, err := c.Convert(10, "EUR", "USD")
+	if err != nil || got != 20 {
+		t.Errorf("Convert() = %v, %v; want 20, nil", got, err)
+	}
+}
+
+func TestNewConverter_InvalidRates(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	tests := []struct {
+		name  string
+		rates *fx.Rates
+	}{
+		{name: "missing base", rates: &fx.Rates{Rates: map[string]float64{"USD": 1.1}}},
+		{name: "zero rate", rates: &fx.Rates{Base: "EUR", Rates: map[string]float64{"USD": 0}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := fx.NewConverter(context.Background(), &staticSource{rates: tt.rates}, 0, logger); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestFileSource_Rates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	if err := os.WriteFile(path, []byte(`{"base":"EUR","rates":{"USD":1.08}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	rates, err := fx.NewFileSource(path).Rates(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rates.Base != "EUR" || rates.Rates["USD"] != 1.08 {
+		t.Errorf("unexpected rates: %+v", rates)
+	}
+
+	if _, err := fx.NewFileSource(filepath.Join(t.TempDir(), "missing.json")).Rates(context.Background()); err == nil {
+		t.Error("expected error for missing file, got nil")
+	}
+}
@@ -0,0 +1,120 @@
+// Package geo resolves coordinates to the cities covering them.
+package geo
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/alex-user-go/hotels/internal/search/types"
+)
+
+// EarthRadiusKm is the mean radius of the Earth.
+const EarthRadiusKm = 6371.0
+
+// DistanceKm returns the great-circle distance between two points using the
+// haversine formula.
+func DistanceKm(a, b types.Location) float64 {
+	lat1, lat2 := radians(a.Latitude), radians(b.Latitude)
+	dLat := lat2 - lat1
+	dLon := radians(b.Longitude - a.Longitude)
+
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+// ValidLocation reports whether a point has a valid latitude and longitude.
+func ValidLocation(loc types.Location) bool {
+	return loc.Latitude >= -90 && loc.Latitude <= 90 &&
+		loc.Longitude >= -180 && loc.Longitude <= 180
+}
+
+// City is a searchable city: its name as sent to providers, its center and
+// the radius of the area it covers.
+type City struct {
+	Name      string  `json:"name"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+	RadiusKm  float64 `json:"radius_km"`
+}
+
+// Location returns the center of the city.
+func (c City) Location() types.Location {
+	return types.Location{Latitude: c.Latitude, Longitude: c.Longitude}
+}
+
+// LoadFile reads a gazetteer file: a JSON array of cities.
+func LoadFile(path string) ([]City, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gazetteer file: %w", err)
+	}
+
+	var cities []City
+	if err := json.Unmarshal(data, &cities); err != nil {
+		return nil, fmt.Errorf("failed to parse gazetteer file: %w", err)
+	}
+
+	return cities, nil
+}
+
+// Gazetteer finds the cities around a point.
+type Gazetteer struct {
+	cities []City
+}
+
+// NewGazetteer creates a Gazetteer. It fails if a city has no name, invalid
+// coordinates or a negative radius.
+func NewGazetteer(cities []City) (*Gazetteer, error) {
+	g := &Gazetteer{cities: make([]City, 0, len(cities))}
+	for i, c := range cities {
+		c.Name = strings.TrimSpace(c.Name)
+		if c.Name == "" {
+			return nil, fmt.Errorf("city %d has no name", i)
+		}
+		if !ValidLocation(c.Location()) {
+			return nil, fmt.Errorf("city %q has invalid coordinates", c.Name)
+		}
+		if c.RadiusKm < 0 {
+			return nil, fmt.Errorf("city %q has a negative radius", c.Name)
+		}
+		g.cities = append(g.cities, c)
+	}
+	return g, nil
+}
+
+// Covering returns the names of the cities whose area overlaps the circle of
+// radiusKm around point, nearest first.
+func (g *Gazetteer) Covering(point types.Location, radiusKm float64) []string {
+	type near struct {
+		name     string
+		distance float64
+	}
+	var found []near
+	for _, c := range g.cities {
+		if d := DistanceKm(point, c.Location()); d <= radiusKm+c.RadiusKm {
+			found = append(found, near{name: c.Name, distance: d})
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].distance != found[j].distance {
+			return found[i].distance < found[j].distance
+		}
+		return found[i].name < found[j].name
+	})
+
+	names := make([]string, len(found))
+	for i, n := range found {
+		names[i] = n.name
+	}
+	return names
+}
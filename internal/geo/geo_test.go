@@ -0,0 +1,114 @@
+package geo_test
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alex-user-go/hotels/internal/geo"
+	"github.com/alex-user-go/hotels/internal/search/types"
+)
+
+var (
+	paris  = types.Location{Latitude: 48.8566, Longitude: 2.3522}
+	london = types.Location{Latitude: 51.5074, Longitude: -0.1278}
+)
+
+func TestDistanceKm(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b types.Location
+		want float64
+	}{
+		{name: "same point", a: paris, b: paris, want: 0},
+		{name: "paris to london", a: paris, b: london, want: 343.5},
+		{name: "antipodes", a: types.Location{}, b: types.Location{Longitude: 180}, want: math.Pi * geo.EarthRadiusKm},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := geo.DistanceKm(tt.a, tt.b)
+			if math.Abs(got-tt.want) > 0.5 {
+				t.Errorf("DistanceKm() = %.2f, want %.2f", got, tt.want)
+			}
+			if back := geo.DistanceKm(tt.b, tt.a); math.Abs(back-got) > 1e-9 {
+				t.Errorf("distance is not symmetric: %v vs %v", got, back)
+			}
+		})
+	}
+}
+
+func TestGazetteer_Covering(t *testing.T) {
+	g, err := geo.NewGazetteer([]geo.City{
+		{Name: "paris", Latitude: 48.8566, Longitude: 2.3522, RadiusKm: 12},
+		{Name: "versailles", Latitude: 48.8049, Longitude: 2.1204, RadiusKm: 5},
+		{Name: "london", Latitude: 51.5074, Longitude: -0.1278, RadiusKm: 20},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		point    types.Location
+		radiusKm float64
+		want     []string
+	}{
+		{name: "inside city", point: paris, radiusKm: 1, want: []string{"paris"}},
+		{name: "overlapping neighbour", point: paris, radiusKm: 15, want: []string{"paris", "versailles"}},
+		{name: "nearest first", point: types.Location{Latitude: 48.81, Longitude: 2.13}, radiusKm: 10, want: []string{"versailles", "paris"}},
+		{name: "nothing near", point: types.Location{Latitude: 0, Longitude: 0}, radiusKm: 50, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := g.Covering(tt.point, tt.radiusKm)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Covering() = %v, want %v", got, tt.want)
+			}
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Errorf("Covering() = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestNewGazetteer_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		city geo.City
+	}{
+		{name: "missing name", city: geo.City{Latitude: 1, Longitude: 1}},
+		{name: "latitude out of range", city: geo.City{Name: "x", Latitude: 91}},
+		{name: "longitude out of range", city: geo.City{Name: "x", Longitude: -181}},
+		{name: "negative radius", city: geo.City{Name: "x", RadiusKm: -1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := geo.NewGazetteer([]geo.City{tt.city}); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cities.json")
+	data := `[{"name": "paris", "latitude": 48.8566, "longitude": 2.3522, "radius_km": 12}]`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cities, err := geo.LoadFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cities) != 1 || cities[0].Name != "paris" || cities[0].RadiusKm != 12 {
+		t.Errorf("unexpected cities: %+v", cities)
+	}
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/middleware"
+)
+
+// BatchConfig controls POST /search/batch.
+type BatchConfig struct {
+	// MaxItems is the largest number of searches accepted in one batch.
+	MaxItems int
+	// Concurrency bounds how many searches of a batch run at once.
+	Concurrency int
+	// Weight is the number of rate limit tokens charged per search.
+	// A batch always costs at least one token.
+	Weight float64
+}
+
+// DefaultBatchConfig returns the default batch settings.
+func DefaultBatchConfig() BatchConfig {
+	return BatchConfig{
+		MaxItems:    50,
+		Concurrency: 4,
+		Weight:      0.2,
+	}
+}
+
+// cost returns the rate limit tokens charged for a batch of n searches.
+func (c BatchConfig) cost(n int) int {
+	return max(1, int(math.Ceil(float64(n)*c.Weight)))
+}
+
+// WithBatch sets the batch endpoint settings.
+func WithBatch(cfg BatchConfig) Option {
+	return func(h *Handler) {
+		h.batch = cfg
+	}
+}
+
+// BatchRequest is the JSON body of POST /search/batch.
+type BatchRequest struct {
+	Searches []SearchRequest `json:"searches"`
+}
+
+// BatchResponse holds one result per search, in request order.
+type BatchResponse struct {
+	Results []BatchResult `json:"results"`
+}
+
+// BatchResult is the outcome of a single search in a batch. Exactly one of
+// Result and Error is set.
+type BatchResult struct {
+	Index  int             `json:"index"`
+	Status int             `json:"status"`
+	Result *SearchResponse `json:"result,omitempty"`
+	Error  *Problem        `json:"error,omitempty"`
+}
+
+// BatchSearchHandler handles POST /search/batch requests.
+// Every search goes through the same cache as single searches; invalid or
+// failed searches are reported per item and don't fail the batch.
+func (h *Handler) BatchSearchHandler(w http.ResponseWriter, r *http.Request) {
+	startTime := time.Now()
+	h.metrics.IncRequests()
+	requestID := middleware.RequestID(r.Context())
+	ip := ExtractIP(r)
+
+	// Parse the batch first: its size determines the rate limit cost
+	var req BatchRequest
+	if err := h.limits.decodeJSON(r, &req); err != nil {
+		writeRequestError(w, err)
+		return
+	}
+	if len(req.Searches) == 0 {
+		writeValidationError(w, &ValidationError{
+			InvalidParams: []InvalidParam{{Name: "searches", Reason: "searches must not be empty"}},
+		})
+		return
+	}
+	if len(req.Searches) > h.batch.MaxItems {
+		writeValidationError(w, &ValidationError{
+			InvalidParams: []InvalidParam{{
+				Name:   "searches",
+				Reason: fmt.Sprintf("searches must contain at most %d items", h.batch.MaxItems),
+			}},
+		})
+		return
+	}
+
+	// Validate every search before charging: a geo search counts once for
+	// each of its cities, like a single geo search.
+	params := make([]*SearchParams, len(req.Searches))
+	errs := make([]error, len(req.Searches))
+	searches := 0
+	for i := range req.Searches {
+		params[i], errs[i] = h.limits.ValidateSearchRequest(&req.Searches[i])
+		if errs[i] == nil && params[i].Geo != nil {
+			searches += len(params[i].Geo.Cities)
+		} else {
+			searches++
+		}
+	}
+
+	// Check rate limit
+	cost := h.batch.cost(searches)
+	if !h.rateLimiter.AllowN(ip, cost) {
+		h.logger.Warn("rate limit exceeded", "request_id", requestID, "ip", ip, "cost", cost)
+		writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
+		return
+	}
+
+	results := make([]BatchResult, len(req.Searches))
+	runBounded(r.Context(), len(req.Searches), h.batch.Concurrency, nil, func(i int) {
+		results[i] = h.batchItem(r.Context(), i, params[i], errs[i], startTime)
+	})
+
+	h.writeJSON(w, http.StatusOK, BatchResponse{Results: results})
+}
+
+// batchItem runs a single search of a batch, or reports why it failed
+// validation.
+func (h *Handler) batchItem(ctx context.Context, index int, params *SearchParams, err error, startTime time.Time) BatchResult {
+	var problem *Problem
+
+	if err != nil {
+		p := requestProblem(err)
+		problem = &p
+	} else if ctx.Err() != nil {
+		p := errorProblem(http.StatusServiceUnavailable, "search cancelled")
+		problem = &p
+	} else {
+		var response *SearchResponse
+		if response, problem = h.searchPage(ctx, params, startTime); problem == nil {
+			return BatchResult{Index: index, Status: http.StatusOK, Result: response}
+		}
+	}
+
+	return BatchResult{Index: index, Status: problem.Status, Error: problem}
+}
+
+// runBounded calls fn for every index in [0, n) with at most limit calls
+// running concurrently, and waits for all of them to finish. If shared is
+// not nil, each call also holds one of its slots, bounding calls across
+// every runBounded sharing it. Indices not yet started when ctx is done are
+// still passed to fn so it can record the cancellation.
+func runBounded(ctx context.Context, n, limit int, shared chan struct{}, fn func(i int)) {
+	if limit <= 0 {
+		limit = 1
+	}
+	sem := make(chan struct{}, limit)
+
+	var wg sync.WaitGroup
+	for i := range n {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			fn(i)
+			continue
+		}
+		if shared != nil {
+			select {
+			case shared <- struct{}{}:
+			case <-ctx.Done():
+				<-sem
+				fn(i)
+				continue
+			}
+		}
+		wg.Go(func() {
+			defer func() {
+				if shared != nil {
+					<-shared
+				}
+				<-sem
+			}()
+			fn(i)
+		})
+	}
+	wg.Wait()
+}
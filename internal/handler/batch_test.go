@@ -0,0 +1,189 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/handler"
+	"github.com/alex-user-go/hotels/internal/obs"
+	"github.com/alex-user-go/hotels/internal/providers"
+	"github.com/alex-user-go/hotels/internal/search"
+	"github.com/alex-user-go/hotels/internal/search/cache"
+	"github.com/alex-user-go/hotels/internal/search/ratelimit"
+)
+
+// countingProvider counts searches and tracks peak concurrency.
+type countingProvider struct {
+	calls    atomic.Int64
+	inflight atomic.Int64
+	peak     atomic.Int64
+	delay    time.Duration
+}
+
+func (c *countingProvider) Name() string {
+	return "counting"
+}
+
+func (c *countingProvider) Search(ctx context.Context, city, checkin string, nights int, occupancy providers.Occupancy) ([]providers.Hotel, error) {
+	c.calls.Add(1)
+	n := c.inflight.Add(1)
+	defer c.inflight.Add(-1)
+	for {
+		peak := c.peak.Load()
+		if n <= peak || c.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(c.delay)
+	return []providers.Hotel{{HotelID: "H001", Name: "Hotel " + city, Currency: "EUR", Price: 100}}, nil
+}
+
+func batchBody(items ...string) string {
+	return `{"searches":[` + strings.Join(items, ",") + `]}`
+}
+
+func batchItem(city string) string {
+	return fmt.Sprintf(`{"city":%q,"checkin":%q,"nights":2,"adults":2}`, city, futureDate)
+}
+
+func newBatchHandler(p providers.Provider, limiter *ratelimit.Limiter, cfg handler.BatchConfig) (*handler.Handler, func()) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	metrics := obs.NewMetrics(logger)
+	searchCache := cache.NewCache(30 * time.Second)
+	aggregator := search.NewAggregator([]providers.Provider{p}, 2*time.Second, metrics, logger)
+
+	h := handler.New(aggregator, searchCache, limiter, metrics, logger, handler.WithBatch(cfg))
+	return h, searchCache.Close
+}
+
+func postBatch(h *handler.Handler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/search/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "192.168.1.1:12345"
+	w := httptest.NewRecorder()
+	h.BatchSearchHandler(w, req)
+	return w
+}
+
+func TestHandler_BatchSearchHandler(t *testing.T) {
+	provider := &countingProvider{delay: 20 * time.Millisecond}
+	limiter := ratelimit.New(10, time.Minute)
+	defer limiter.Close()
+	h, cleanup := newBatchHandler(provider, limiter, handler.BatchConfig{MaxItems: 10, Concurrency: 2, Weight: 0.5})
+	defer cleanup()
+
+	body := batchBody(
+		batchItem("paris"),
+		`{"city":"","checkin":"`+futureDate+`","nights":2,"adults":2}`,
+		batchItem("rome"),
+		batchItem("paris"), // Same search as the first item, shares the cache entry
+		batchItem("oslo"),
+	)
+	w := postBatch(h, body)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var resp handler.BatchResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Results) != 5 {
+		t.Fatalf("expected 5 results, got %d", len(resp.Results))
+	}
+
+	wantStatus := []int{200, 400, 200, 200, 200}
+	for i, res := range resp.Results {
+		if res.Index != i {
+			t.Errorf("results[%d].index = %d", i, res.Index)
+		}
+		if res.Status != wantStatus[i] {
+			t.Errorf("results[%d].status = %d, want %d", i, res.Status, wantStatus[i])
+		}
+		if res.Status == http.StatusOK && (res.Result == nil || res.Error != nil) {
+			t.Errorf("results[%d] should carry only a result: %+v", i, res)
+		}
+	}
+	if resp.Results[1].Error == nil || resp.Results[1].Error.Detail != "city is required" {
+		t.Errorf("results[1].error = %+v, want city is required", resp.Results[1].Error)
+	}
+	if resp.Results[2].Result.Search.City != "rome" {
+		t.Errorf("results out of order: %+v", resp.Results[2].Result.Search)
+	}
+
+	// paris, rome, oslo: the duplicate paris search is collapsed or cached
+	if calls := provider.calls.Load(); calls != 3 {
+		t.Errorf("provider called %d times, want 3", calls)
+	}
+	if peak := provider.peak.Load(); peak > 2 {
+		t.Errorf("peak concurrency = %d, want at most 2", peak)
+	}
+
+	// 5 items at weight 0.5 cost 3 tokens, leaving 7
+	if !limiter.AllowN("192.168.1.1", 7) || limiter.Allow("192.168.1.1") {
+		t.Error("expected batch to consume exactly 3 rate limit tokens")
+	}
+}
+
+func TestHandler_BatchSearchHandler_Rejected(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		rate       int
+		wantStatus int
+		wantError  string
+	}{
+		{
+			name:       "empty batch",
+			body:       batchBody(),
+			rate:       10,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "searches must not be empty",
+		},
+		{
+			name:       "too many items",
+			body:       batchBody(batchItem("a"), batchItem("b"), batchItem("c"), batchItem("d")),
+			rate:       10,
+			wantStatus: http.StatusBadRequest,
+			wantError:  "searches must contain at most 3 items",
+		},
+		{
+			name:       "weight exceeds remaining tokens",
+			body:       batchBody(batchItem("a"), batchItem("b"), batchItem("c")),
+			rate:       2,
+			wantStatus: http.StatusTooManyRequests,
+			wantError:  "rate limit exceeded",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := ratelimit.New(tt.rate, time.Minute)
+			defer limiter.Close()
+			h, cleanup := newBatchHandler(&countingProvider{}, limiter, handler.BatchConfig{MaxItems: 3, Concurrency: 2, Weight: 1})
+			defer cleanup()
+
+			w := postBatch(h, tt.body)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			var problem handler.Problem
+			if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+				t.Fatalf("failed to decode problem: %v", err)
+			}
+			if problem.Detail != tt.wantError {
+				t.Errorf("error = %q, want %q", problem.Detail, tt.wantError)
+			}
+		})
+	}
+}
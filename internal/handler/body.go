@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/alex-user-go/hotels/internal/providers"
+	"github.com/alex-user-go/hotels/internal/search/listing"
+)
+
+// DefaultMaxBodyBytes is the default limit on JSON request bodies.
+const DefaultMaxBodyBytes = 64 << 10
+
+// SearchRequest is the JSON body of POST /search. Guests are given either
+// as Rooms or with the single-room Adults/Children shorthand. Lat, Lon and
+// RadiusKm search by coordinates instead of City.
+type SearchRequest struct {
+	City     string           `json:"city"`
+	Lat      *float64         `json:"lat,omitempty"`
+	Lon      *float64         `json:"lon,omitempty"`
+	RadiusKm *float64         `json:"radius_km,omitempty"`
+	Checkin  string           `json:"checkin"`
+	Nights   *int             `json:"nights"`
+	Adults   *int             `json:"adults,omitempty"`
+	Children []int            `json:"children,omitempty"`
+	Rooms    []providers.Room `json:"rooms,omitempty"`
+	Currency string           `json:"currency,omitempty"`
+	Options  *SearchOptions   `json:"options,omitempty"`
+}
+
+// SearchOptions controls sorting, filtering and pagination of a JSON search.
+type SearchOptions struct {
+	Sort     string  `json:"sort,omitempty"`
+	MinPrice float64 `json:"min_price,omitempty"`
+	MaxPrice float64 `json:"max_price,omitempty"`
+	Name     string  `json:"name,omitempty"`
+	Provider string  `json:"provider,omitempty"`
+	Cursor   string  `json:"cursor,omitempty"`
+	Limit    int     `json:"limit,omitempty"`
+}
+
+// ParseSearchRequest reads and validates a JSON search request body.
+// Unknown fields are rejected and the body size is limited by MaxBodyBytes.
+func (l Limits) ParseSearchRequest(r *http.Request) (*SearchParams, error) {
+	var req SearchRequest
+	if err := l.decodeJSON(r, &req); err != nil {
+		return nil, err
+	}
+	return l.ValidateSearchRequest(&req)
+}
+
+// ValidateSearchRequest validates a decoded search request, applying the
+// same rules as query parameters.
+func (l Limits) ValidateSearchRequest(req *SearchRequest) (*SearchParams, error) {
+	errs := &ValidationError{}
+
+	params := &SearchParams{
+		City:     strings.TrimSpace(req.City),
+		Checkin:  strings.TrimSpace(req.Checkin),
+		Currency: strings.ToUpper(strings.TrimSpace(req.Currency)),
+	}
+	if req.Nights == nil {
+		errs.add("nights", "nights is required")
+	} else {
+		params.Nights = *req.Nights
+	}
+	params.Occupancy = requestOccupancy(req, errs)
+	params.Geo = requestGeoArea(req, errs)
+	if opts := req.Options; opts != nil {
+		params.List = listing.Options{
+			Sort:     strings.TrimSpace(opts.Sort),
+			MinPrice: opts.MinPrice,
+			MaxPrice: opts.MaxPrice,
+			Name:     strings.TrimSpace(opts.Name),
+			Provider: strings.TrimSpace(opts.Provider),
+			Cursor:   strings.TrimSpace(opts.Cursor),
+			Limit:    opts.Limit,
+		}
+	}
+
+	l.validate(params, errs)
+	if len(errs.InvalidParams) > 0 {
+		return nil, errs
+	}
+	return params, nil
+}
+
+// requestGeoArea builds the area of a JSON geo search; nil if the request
+// has no coordinates.
+func requestGeoArea(req *SearchRequest, errs *ValidationError) *GeoArea {
+	if req.Lat == nil && req.Lon == nil && req.RadiusKm == nil {
+		return nil
+	}
+
+	area := &GeoArea{RadiusKm: DefaultRadiusKm}
+	if req.Lat == nil {
+		errs.add("lat", "lat is required")
+	} else {
+		area.Location.Latitude = *req.Lat
+	}
+	if req.Lon == nil {
+		errs.add("lon", "lon is required")
+	} else {
+		area.Location.Longitude = *req.Lon
+	}
+	if req.RadiusKm != nil {
+		area.RadiusKm = *req.RadiusKm
+	}
+	return area
+}
+
+// requestOccupancy builds the occupancy of a JSON search request.
+func requestOccupancy(req *SearchRequest, errs *ValidationError) providers.Occupancy {
+	if len(req.Rooms) > 0 {
+		if req.Adults != nil || len(req.Children) > 0 {
+			errs.add("rooms", "rooms must not be combined with adults or children")
+			return nil
+		}
+		if err := providers.Occupancy(req.Rooms).Validate(); err != nil {
+			errs.add("rooms", "rooms are invalid: "+err.Error())
+			return nil
+		}
+		return req.Rooms
+	}
+
+	if req.Adults == nil {
+		errs.add("adults", "adults is required")
+		return nil
+	}
+	if *req.Adults <= 0 {
+		errs.add("adults", "adults must be a positive integer")
+		return nil
+	}
+
+	room := providers.Room{Adults: *req.Adults, ChildAges: req.Children}
+	for _, age := range room.ChildAges {
+		if age < 0 || age > providers.MaxChildAge {
+			errs.add("children", fmt.Sprintf("children ages must be between 0 and %d", providers.MaxChildAge))
+			return nil
+		}
+	}
+	return providers.Occupancy{room}
+}
+
+// decodeJSON decodes a single JSON object from the request body into v.
+func (l Limits) decodeJSON(r *http.Request, v any) error {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "application/json" {
+		return &RequestError{
+			Status:  http.StatusUnsupportedMediaType,
+			Message: "Content-Type must be application/json",
+		}
+	}
+
+	maxBytes := l.MaxBodyBytes
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBodyBytes
+	}
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, maxBytes))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		return bodyError(err, maxBytes)
+	}
+	if err := dec.Decode(&struct{}{}); !errors.Is(err, io.EOF) {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return bodyError(err, maxBytes)
+		}
+		return &RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "request body must contain a single JSON object",
+		}
+	}
+
+	return nil
+}
+
+// bodyError translates a JSON decoding error into a RequestError.
+func bodyError(err error, maxBytes int64) error {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		maxErr    *http.MaxBytesError
+	)
+
+	switch {
+	case errors.As(err, &maxErr):
+		return &RequestError{
+			Status:  http.StatusRequestEntityTooLarge,
+			Message: fmt.Sprintf("request body must not be larger than %d bytes", maxBytes),
+		}
+	case errors.As(err, &syntaxErr):
+		return &RequestError{
+			Status:  http.StatusBadRequest,
+			Message: fmt.Sprintf("request body contains malformed JSON at position %d", syntaxErr.Offset),
+		}
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return &RequestError{Status: http.StatusBadRequest, Message: "request body contains malformed JSON"}
+	case errors.As(err, &typeErr):
+		return &RequestError{
+			Status:  http.StatusBadRequest,
+			Message: fmt.Sprintf("request body field %q must be of type %s", typeErr.Field, typeErr.Type),
+		}
+	case errors.Is(err, io.EOF):
+		return &RequestError{Status: http.StatusBadRequest, Message: "request body must not be empty"}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.TrimPrefix(err.Error(), "json: unknown field ")
+		return &RequestError{
+			Status:  http.StatusBadRequest,
+			Message: "request body contains unknown field " + field,
+		}
+	default:
+		return &RequestError{Status: http.StatusBadRequest, Message: "request body is invalid"}
+	}
+}
@@ -0,0 +1,181 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alex-user-go/hotels/internal/handler"
+)
+
+func TestHandler_SearchJSONHandler(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+		wantError   string
+	}{
+		{
+			name:        "adults shorthand",
+			contentType: "application/json",
+			body:        `{"city":"paris","checkin":"` + futureDate + `","nights":2,"adults":2,"children":[4]}`,
+			wantStatus:  http.StatusOK,
+		},
+		{
+			name:        "rooms with options",
+			contentType: "application/json; charset=utf-8",
+			body: `{"city":"paris","checkin":"` + futureDate + `","nights":2,
+				"rooms":[{"adults":2,"child_ages":[5,8]},{"adults":1}],
+				"currency":"eur","options":{"sort":"name","limit":5}}`,
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:        "shared validation",
+			contentType: "application/json",
+			body:        `{"city":"","checkin":"` + futureDate + `","nights":0,"adults":2}`,
+			wantStatus:  http.StatusBadRequest,
+			wantError:   "city is required; nights must be a positive integer",
+		},
+		{
+			name:        "missing nights and guests",
+			contentType: "application/json",
+			body:        `{"city":"paris","checkin":"` + futureDate + `"}`,
+			wantStatus:  http.StatusBadRequest,
+			wantError:   "nights is required; adults is required",
+		},
+		{
+			name:        "coordinates without lon",
+			contentType: "application/json",
+			body:        `{"lat":48.85,"radius_km":5,"checkin":"` + futureDate + `","nights":2,"adults":2}`,
+			wantStatus:  http.StatusBadRequest,
+			wantError:   "lon is required",
+		},
+		{
+			name:        "rooms combined with adults",
+			contentType: "application/json",
+			body:        `{"city":"paris","checkin":"` + futureDate + `","nights":2,"adults":2,"rooms":[{"adults":1}]}`,
+			wantStatus:  http.StatusBadRequest,
+			wantError:   "rooms must not be combined with adults or children",
+		},
+		{
+			name:        "unknown field",
+			contentType: "application/json",
+			body:        `{"city":"paris","checkin":"` + futureDate + `","nights":2,"adults":2,"stars":5}`,
+			wantStatus:  http.StatusBadRequest,
+			wantError:   `request body contains unknown field "stars"`,
+		},
+		{
+			name:        "unknown nested field",
+			contentType: "application/json",
+			body:        `{"city":"paris","checkin":"` + futureDate + `","nights":2,"adults":2,"options":{"page":2}}`,
+			wantStatus:  http.StatusBadRequest,
+			wantError:   `request body contains unknown field "page"`,
+		},
+		{
+			name:        "wrong type",
+			contentType: "application/json",
+			body:        `{"city":"paris","checkin":"` + futureDate + `","nights":"two","adults":2}`,
+			wantStatus:  http.StatusBadRequest,
+			wantError:   `request body field "nights" must be of type int`,
+		},
+		{
+			name:        "malformed JSON",
+			contentType: "application/json",
+			body:        `{"city":"paris",}`,
+			wantStatus:  http.StatusBadRequest,
+			wantError:   "request body contains malformed JSON at position 17",
+		},
+		{
+			name:        "multiple objects",
+			contentType: "application/json",
+			body:        `{"city":"paris"}{"city":"rome"}`,
+			wantStatus:  http.StatusBadRequest,
+			wantError:   "request body must contain a single JSON object",
+		},
+		{
+			name:        "empty body",
+			contentType: "application/json",
+			body:        ``,
+			wantStatus:  http.StatusBadRequest,
+			wantError:   "request body must not be empty",
+		},
+		{
+			name:        "too large",
+			contentType: "application/json",
+			body:        `{"city":"` + strings.Repeat("a", handler.DefaultMaxBodyBytes) + `"}`,
+			wantStatus:  http.StatusRequestEntityTooLarge,
+			wantError:   "request body must not be larger than 65536 bytes",
+		},
+		{
+			name:        "wrong content type",
+			contentType: "text/plain",
+			body:        `{}`,
+			wantStatus:  http.StatusUnsupportedMediaType,
+			wantError:   "Content-Type must be application/json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, cleanup := newTestHandler(&mockProvider{})
+			defer cleanup()
+
+			req := httptest.NewRequest(http.MethodPost, "/search", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			req.RemoteAddr = "192.168.1.1:12345"
+			w := httptest.NewRecorder()
+
+			h.SearchJSONHandler(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body: %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+
+			if tt.wantError != "" {
+				var problem handler.Problem
+				if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+					t.Fatalf("failed to decode problem: %v", err)
+				}
+				if problem.Detail != tt.wantError {
+					t.Errorf("error = %q, want %q", problem.Detail, tt.wantError)
+				}
+				return
+			}
+
+			var resp handler.SearchResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if resp.Search.City != "paris" || resp.Search.Currency != "EUR" {
+				t.Errorf("unexpected search info: %+v", resp.Search)
+			}
+		})
+	}
+}
+
+func TestHandler_SearchJSONHandler_SharesCacheWithGET(t *testing.T) {
+	h, cleanup := newTestHandler(&mockProvider{})
+	defer cleanup()
+
+	get := httptest.NewRequest(http.MethodGet, "/search?city=paris&checkin="+futureDate+"&nights=2&occupancy=2-5|1", nil)
+	get.RemoteAddr = "192.168.1.1:12345"
+	h.SearchHandler(httptest.NewRecorder(), get)
+
+	body := `{"city":"paris","checkin":"` + futureDate + `","nights":2,"rooms":[{"adults":2,"child_ages":[5]},{"adults":1}]}`
+	post := httptest.NewRequest(http.MethodPost, "/search", strings.NewReader(body))
+	post.Header.Set("Content-Type", "application/json")
+	post.RemoteAddr = "192.168.1.1:12345"
+	w := httptest.NewRecorder()
+	h.SearchJSONHandler(w, post)
+
+	var resp handler.SearchResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if resp.Stats.Cache != "hit" {
+		t.Errorf("cache = %q, want hit", resp.Stats.Cache)
+	}
+}
@@ -0,0 +1,284 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/middleware"
+	"github.com/alex-user-go/hotels/internal/search/listing"
+	"github.com/alex-user-go/hotels/internal/search/types"
+)
+
+// DefaultBestLimit is the number of best hotel/date combinations returned
+// by a flexible-date search when no limit is requested.
+const DefaultBestLimit = 5
+
+// FanOutConfig controls searches that expand into several aggregated
+// searches: flexible-date and multi-city searches share these settings.
+type FanOutConfig struct {
+	// Concurrency bounds how many aggregated searches of one request run at once.
+	Concurrency int
+	// MaxConcurrent bounds how many aggregated searches of all fan-out
+	// requests run at once; zero or less is unlimited.
+	MaxConcurrent int
+}
+
+// DefaultFanOutConfig returns the default fan-out settings.
+func DefaultFanOutConfig() FanOutConfig {
+	return FanOutConfig{Concurrency: 4, MaxConcurrent: 16}
+}
+
+// WithFanOut sets the fan-out settings.
+func WithFanOut(cfg FanOutConfig) Option {
+	return func(h *Handler) {
+		h.fanOut = cfg
+	}
+}
+
+// FlexibleParams holds validated flexible-date search parameters.
+// Search holds everything but the check-in date; one aggregated search is
+// run per date in Dates.
+type FlexibleParams struct {
+	Search      SearchParams
+	CheckinFrom string
+	CheckinTo   string
+	Dates       []string
+}
+
+// FlexibleResponse is the response of a flexible-date search.
+type FlexibleResponse struct {
+	Search   FlexibleInfo  `json:"search"`
+	Stats    FlexibleStats `json:"stats"`
+	Calendar []CalendarDay `json:"calendar"`
+	Best     []BestCombo   `json:"best"`
+}
+
+// FlexibleInfo contains the flexible-date search parameters.
+type FlexibleInfo struct {
+	SearchInfo
+	CheckinFrom string `json:"checkin_from"`
+	CheckinTo   string `json:"checkin_to"`
+}
+
+// FlexibleStats contains flexible-date search statistics.
+type FlexibleStats struct {
+	DatesTotal     int   `json:"dates_total"`
+	DatesSucceeded int   `json:"dates_succeeded"`
+	DatesFailed    int   `json:"dates_failed"`
+	CacheHits      int   `json:"cache_hits"`
+	DurationMs     int64 `json:"duration_ms"`
+}
+
+// CalendarDay is the cheapest offer for one check-in date.
+// Cheapest is nil when no hotel matched or the search failed.
+type CalendarDay struct {
+	Checkin     string       `json:"checkin"`
+	Cheapest    *types.Hotel `json:"cheapest"`
+	HotelsTotal int          `json:"hotels_total"`
+	Cache       string       `json:"cache,omitempty"`
+	Error       string       `json:"error,omitempty"`
+}
+
+// BestCombo is a hotel at its cheapest check-in date.
+type BestCombo struct {
+	Checkin string      `json:"checkin"`
+	Hotel   types.Hotel `json:"hotel"`
+}
+
+// ParseFlexibleParams parses and validates flexible-date search parameters.
+// checkin_from and checkin_to are inclusive and span at most
+// MaxFlexibleDates dates; limit is the number of best combinations.
+func (l Limits) ParseFlexibleParams(r *http.Request) (*FlexibleParams, error) {
+	query := r.URL.Query()
+	errs := &ValidationError{}
+
+	params := &FlexibleParams{
+		Search: SearchParams{
+			City:      strings.TrimSpace(query.Get("city")),
+			Nights:    parseRequiredInt(query, "nights", errs),
+			Occupancy: parseOccupancy(query, errs),
+			Currency:  strings.ToUpper(strings.TrimSpace(query.Get("currency"))),
+			List:      parseListOptions(query, errs),
+		},
+		CheckinFrom: strings.TrimSpace(query.Get("checkin_from")),
+		CheckinTo:   strings.TrimSpace(query.Get("checkin_to")),
+	}
+
+	if params.Search.City == "" {
+		errs.add("city", "city is required")
+	}
+
+	from, fromOK := l.validateCheckin("checkin_from", params.CheckinFrom, errs)
+	to, toOK := l.validateCheckin("checkin_to", params.CheckinTo, errs)
+	if fromOK && toOK {
+		days := int(to.Sub(from).Hours()/24) + 1
+		switch {
+		case days <= 0:
+			errs.add("checkin_to", "checkin_to must not be before checkin_from")
+		case l.MaxFlexibleDates > 0 && days > l.MaxFlexibleDates:
+			errs.add("checkin_to", fmt.Sprintf("checkin_to must be within %d days of checkin_from", l.MaxFlexibleDates))
+		default:
+			for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+				params.Dates = append(params.Dates, d.Format(dateLayout))
+			}
+		}
+	}
+
+	// Results are ranked by price, so sorting and paging don't apply
+	if params.Search.List.Sort != "" {
+		errs.add("sort", "sort is not supported for flexible-date searches")
+	}
+	if params.Search.List.Cursor != "" {
+		errs.add("cursor", "cursor is not supported for flexible-date searches")
+	}
+
+	l.validateStay(&params.Search, errs)
+	if len(errs.InvalidParams) > 0 {
+		return nil, errs
+	}
+	return params, nil
+}
+
+// FlexibleSearchHandler handles GET /search/flexible requests.
+// One aggregated search per candidate check-in date is run through the
+// cache, and the cheapest offer per date is returned as a price calendar.
+func (h *Handler) FlexibleSearchHandler(w http.ResponseWriter, r *http.Request) {
+	startTime := time.Now()
+	h.metrics.IncRequests()
+	requestID := middleware.RequestID(r.Context())
+
+	ip := ExtractIP(r)
+	params, err := h.limits.ParseFlexibleParams(r)
+	if err != nil {
+		h.logger.Debug("invalid request parameters", "request_id", requestID, "error", err, "ip", ip)
+		writeRequestError(w, err)
+		return
+	}
+
+	// Check rate limit, charging every date like a batched search
+	cost := h.batch.cost(len(params.Dates))
+	if !h.rateLimiter.AllowN(ip, cost) {
+		h.logger.Warn("rate limit exceeded", "request_id", requestID, "ip", ip, "cost", cost)
+		writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
+		return
+	}
+
+	days, matched := h.searchDates(r.Context(), params)
+
+	stats := FlexibleStats{DatesTotal: len(days)}
+	for _, day := range days {
+		if day.Error != "" {
+			stats.DatesFailed++
+			continue
+		}
+		stats.DatesSucceeded++
+		if day.Cache == "hit" {
+			stats.CacheHits++
+		}
+	}
+	if stats.DatesSucceeded == 0 {
+		writeError(w, http.StatusInternalServerError, "search failed")
+		return
+	}
+	stats.DurationMs = time.Since(startTime).Milliseconds()
+
+	limit := params.Search.List.Limit
+	if limit == 0 {
+		limit = DefaultBestLimit
+	}
+
+	h.writeJSON(w, http.StatusOK, FlexibleResponse{
+		Search: FlexibleInfo{
+			SearchInfo:  newSearchInfo(&params.Search),
+			CheckinFrom: params.CheckinFrom,
+			CheckinTo:   params.CheckinTo,
+		},
+		Stats:    stats,
+		Calendar: days,
+		Best:     bestCombos(params.Dates, matched, limit),
+	})
+}
+
+// searchDates runs one search per candidate date with bounded concurrency.
+// It returns the calendar and, per date, the hotels matching the filters.
+func (h *Handler) searchDates(ctx context.Context, params *FlexibleParams) ([]CalendarDay, [][]types.Hotel) {
+	days := make([]CalendarDay, len(params.Dates))
+	matched := make([][]types.Hotel, len(params.Dates))
+
+	runBounded(ctx, len(params.Dates), h.fanOut.Concurrency, h.fanOutSlots, func(i int) {
+		day := CalendarDay{Checkin: params.Dates[i]}
+		defer func() { days[i] = day }()
+
+		if ctx.Err() != nil {
+			day.Error = "search cancelled"
+			return
+		}
+
+		search := params.Search
+		search.Checkin = params.Dates[i]
+		result, cacheHit, err := h.search(ctx, &search)
+		if err != nil {
+			h.logger.Error("search failed",
+				"request_id", middleware.RequestID(ctx),
+				"error", err,
+				"city", search.City,
+				"checkin", search.Checkin,
+			)
+			day.Error = "search failed"
+			return
+		}
+
+		day.Cache = "miss"
+		if cacheHit {
+			day.Cache = "hit"
+		}
+
+		hotels := listing.Filter(result.Hotels, search.List)
+		day.HotelsTotal = len(hotels)
+		for j := range hotels {
+			if day.Cheapest == nil || hotels[j].Price < day.Cheapest.Price {
+				day.Cheapest = &hotels[j]
+			}
+		}
+		matched[i] = hotels
+	})
+
+	return days, matched
+}
+
+// bestCombos returns up to limit hotels at their cheapest date, cheapest
+// first. Each hotel appears once; ties go to the earlier date.
+func bestCombos(dates []string, matched [][]types.Hotel, limit int) []BestCombo {
+	best := make(map[string]BestCombo)
+	for i, hotels := range matched {
+		for _, h := range hotels {
+			if existing, ok := best[h.HotelID]; !ok || h.Price < existing.Hotel.Price {
+				best[h.HotelID] = BestCombo{Checkin: dates[i], Hotel: h}
+			}
+		}
+	}
+
+	combos := make([]BestCombo, 0, len(best))
+	for _, c := range best {
+		combos = append(combos, c)
+	}
+	sort.Slice(combos, func(i, j int) bool {
+		a, b := combos[i], combos[j]
+		if a.Hotel.Price != b.Hotel.Price {
+			return a.Hotel.Price < b.Hotel.Price
+		}
+		if a.Checkin != b.Checkin {
+			return a.Checkin < b.Checkin
+		}
+		return a.Hotel.HotelID < b.Hotel.HotelID
+	})
+
+	if len(combos) > limit {
+		combos = combos[:limit]
+	}
+	return combos
+}
@@ -0,0 +1,236 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/handler"
+	"github.com/alex-user-go/hotels/internal/providers"
+	"github.com/alex-user-go/hotels/internal/search/ratelimit"
+)
+
+// calendarProvider prices hotels by check-in date and fails on some dates.
+type calendarProvider struct {
+	prices map[string]map[string]float64 // checkin -> hotel ID -> price
+	fail   map[string]bool
+}
+
+func (c *calendarProvider) Name() string {
+	return "calendar"
+}
+
+func (c *calendarProvider) Search(ctx context.Context, city, checkin string, nights int, occupancy providers.Occupancy) ([]providers.Hotel, error) {
+	if c.fail[checkin] {
+		return nil, errors.New("provider error")
+	}
+	var hotels []providers.Hotel
+	for id, price := range c.prices[checkin] {
+		hotels = append(hotels, providers.Hotel{HotelID: id, Name: "Hotel " + id, Currency: "EUR", Price: price})
+	}
+	return hotels, nil
+}
+
+func TestHandler_FlexibleSearchHandler(t *testing.T) {
+	day := func(offset int) string {
+		return time.Now().AddDate(0, 1, offset).Format("2006-01-02")
+	}
+
+	provider := &calendarProvider{
+		prices: map[string]map[string]float64{
+			day(0): {"H001": 300, "H002": 250},
+			day(1): {"H001": 200, "H002": 260, "H003": 500},
+			day(3): {"H001": 220, "H003": 150},
+		},
+		fail: map[string]bool{day(2): true},
+	}
+	h, cleanup := newTestHandler(provider)
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodGet,
+		"/search/flexible?city=paris&checkin_from="+day(0)+"&checkin_to="+day(3)+"&nights=3&adults=2&limit=2", nil)
+	req.RemoteAddr = "192.168.1.1:12345"
+	w := httptest.NewRecorder()
+
+	h.FlexibleSearchHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var resp handler.FlexibleResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if resp.Stats.DatesTotal != 4 || resp.Stats.DatesSucceeded != 3 || resp.Stats.DatesFailed != 1 {
+		t.Errorf("unexpected stats: %+v", resp.Stats)
+	}
+
+	wantCheapest := []struct {
+		hotelID string
+		price   float64
+		failed  bool
+	}{
+		{hotelID: "H002", price: 250},
+		{hotelID: "H001", price: 200},
+		{failed: true},
+		{hotelID: "H003", price: 150},
+	}
+	if len(resp.Calendar) != len(wantCheapest) {
+		t.Fatalf("calendar has %d days, want %d", len(resp.Calendar), len(wantCheapest))
+	}
+	for i, want := range wantCheapest {
+		got := resp.Calendar[i]
+		if got.Checkin != day(i) {
+			t.Errorf("calendar[%d].checkin = %s, want %s", i, got.Checkin, day(i))
+		}
+		if want.failed {
+			if got.Error == "" || got.Cheapest != nil {
+				t.Errorf("calendar[%d] should report a failure, got %+v", i, got)
+			}
+			continue
+		}
+		if got.Cheapest == nil || got.Cheapest.HotelID != want.hotelID || got.Cheapest.Price != want.price {
+			t.Errorf("calendar[%d].cheapest = %+v, want %s at %v", i, got.Cheapest, want.hotelID, want.price)
+		}
+	}
+
+	// Each hotel at its cheapest date, cheapest first, capped by limit
+	if len(resp.Best) != 2 {
+		t.Fatalf("best has %d combos, want 2", len(resp.Best))
+	}
+	if resp.Best[0].Hotel.HotelID != "H003" || resp.Best[0].Checkin != day(3) {
+		t.Errorf("best[0] = %s on %s, want H003 on %s", resp.Best[0].Hotel.HotelID, resp.Best[0].Checkin, day(3))
+	}
+	if resp.Best[1].Hotel.HotelID != "H001" || resp.Best[1].Checkin != day(1) {
+		t.Errorf("best[1] = %s on %s, want H001 on %s", resp.Best[1].Hotel.HotelID, resp.Best[1].Checkin, day(1))
+	}
+
+	// A later single-date search for one of the dates is served from cache
+	single := httptest.NewRequest(http.MethodGet, "/search?city=paris&checkin="+day(1)+"&nights=3&adults=2", nil)
+	single.RemoteAddr = "192.168.1.2:12345"
+	sw := httptest.NewRecorder()
+	h.SearchHandler(sw, single)
+
+	var singleResp handler.SearchResponse
+	if err := json.NewDecoder(sw.Body).Decode(&singleResp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if singleResp.Stats.Cache != "hit" {
+		t.Errorf("single search cache = %q, want hit", singleResp.Stats.Cache)
+	}
+}
+
+func TestLimits_ParseFlexibleParams(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	limits := handler.DefaultLimits()
+	limits.Now = func() time.Time { return now }
+	limits.MaxFlexibleDates = 7
+
+	tests := []struct {
+		name       string
+		query      string
+		wantDates  int
+		wantParams []string
+	}{
+		{
+			name:      "inclusive range",
+			query:     "city=paris&checkin_from=2026-03-12&checkin_to=2026-03-18&nights=3&adults=2",
+			wantDates: 7,
+		},
+		{
+			name:      "single date",
+			query:     "city=paris&checkin_from=2026-03-12&checkin_to=2026-03-12&nights=3&adults=2",
+			wantDates: 1,
+		},
+		{
+			name:       "too many dates",
+			query:      "city=paris&checkin_from=2026-03-12&checkin_to=2026-03-19&nights=3&adults=2",
+			wantParams: []string{"checkin_to"},
+		},
+		{
+			name:       "reversed range",
+			query:      "city=paris&checkin_from=2026-03-12&checkin_to=2026-03-11&nights=3&adults=2",
+			wantParams: []string{"checkin_to"},
+		},
+		{
+			name:       "past start and missing end",
+			query:      "city=paris&checkin_from=2026-03-01&nights=3&adults=2",
+			wantParams: []string{"checkin_from", "checkin_to"},
+		},
+		{
+			name:       "sort and cursor not supported",
+			query:      "city=paris&checkin_from=2026-03-12&checkin_to=2026-03-13&nights=3&adults=2&sort=name&cursor=abc",
+			wantParams: []string{"sort", "cursor"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/search/flexible?"+tt.query, nil)
+			params, err := limits.ParseFlexibleParams(req)
+
+			if len(tt.wantParams) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(params.Dates) != tt.wantDates {
+					t.Errorf("dates = %v, want %d dates", params.Dates, tt.wantDates)
+				}
+				return
+			}
+
+			var verr *handler.ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected *ValidationError, got %v", err)
+			}
+			if len(verr.InvalidParams) != len(tt.wantParams) {
+				t.Fatalf("invalid params = %+v, want %v", verr.InvalidParams, tt.wantParams)
+			}
+			for i, name := range tt.wantParams {
+				if verr.InvalidParams[i].Name != name {
+					t.Errorf("invalid_params[%d] = %s, want %s", i, verr.InvalidParams[i].Name, name)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_FlexibleSearchHandler_RateLimit(t *testing.T) {
+	day := func(offset int) string {
+		return time.Now().AddDate(0, 1, offset).Format("2006-01-02")
+	}
+
+	tests := []struct {
+		name       string
+		lastDay    int
+		wantStatus int
+	}{
+		{name: "within the tokens", lastDay: 1, wantStatus: http.StatusOK},
+		{name: "a token per date", lastDay: 3, wantStatus: http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := ratelimit.New(2, time.Minute)
+			defer limiter.Close()
+			h, cleanup := newBatchHandler(&calendarProvider{}, limiter, handler.BatchConfig{MaxItems: 3, Concurrency: 2, Weight: 1})
+			defer cleanup()
+
+			req := httptest.NewRequest(http.MethodGet,
+				"/search/flexible?city=paris&checkin_from="+day(0)+"&checkin_to="+day(tt.lastDay)+"&nights=3&adults=2", nil)
+			req.RemoteAddr = "192.168.1.1:12345"
+			w := httptest.NewRecorder()
+			h.FlexibleSearchHandler(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body: %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
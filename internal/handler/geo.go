@@ -0,0 +1,106 @@
+package handler
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/alex-user-go/hotels/internal/geo"
+	"github.com/alex-user-go/hotels/internal/middleware"
+	"github.com/alex-user-go/hotels/internal/search"
+	"github.com/alex-user-go/hotels/internal/search/types"
+)
+
+// geoSearch runs the search for every city covering a geo search area
+// through the cache, merges the results and keeps the hotels within the
+// radius, annotated with their distance. A hotel found in several cities
+// keeps the offer the aggregator would have preferred. Hotels without a known location
+// are dropped. Provider statistics are summed over the cities, and the
+// result counts as a cache hit only if every city was one.
+func (h *Handler) geoSearch(ctx context.Context, params *SearchParams) (*types.Result, bool, error) {
+	area := params.Geo
+	results := make([]*types.Result, len(area.Cities))
+	hits := make([]bool, len(area.Cities))
+	errs := make([]error, len(area.Cities))
+
+	runBounded(ctx, len(area.Cities), h.fanOut.Concurrency, h.fanOutSlots, func(i int) {
+		if errs[i] = ctx.Err(); errs[i] != nil {
+			return
+		}
+		city := *params
+		city.City = area.Cities[i]
+		city.Geo = nil
+		results[i], hits[i], errs[i] = h.search(ctx, &city)
+	})
+
+	merged := &types.Result{}
+	byID := make(map[string]int)
+	cacheHit := true
+	succeeded := 0
+	var firstErr error
+	for i, result := range results {
+		if errs[i] != nil {
+			h.logger.Warn("geo search city failed",
+				"request_id", middleware.RequestID(ctx),
+				"city", area.Cities[i],
+				"error", errs[i],
+			)
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+			continue
+		}
+		succeeded++
+		cacheHit = cacheHit && hits[i]
+		merged.ProvidersTotal += result.ProvidersTotal
+		merged.ProvidersSucceeded += result.ProvidersSucceeded
+		merged.ProvidersFailed += result.ProvidersFailed
+		merged.ProvidersSkipped += result.ProvidersSkipped
+
+		for _, hotel := range result.Hotels {
+			if hotel.Location == nil {
+				continue
+			}
+			distance := geo.DistanceKm(area.Location, *hotel.Location)
+			if distance > area.RadiusKm {
+				continue
+			}
+			distance = math.Round(distance*100) / 100
+			hotel.DistanceKm = &distance
+
+			// The same hotel may be offered under several covering cities
+			if j, ok := byID[hotel.HotelID]; ok {
+				existing := &merged.Hotels[j]
+				offeredBy := mergeProviders(existing.Providers, hotel.Providers)
+				if search.BetterOffer(hotel, *existing) {
+					*existing = hotel
+				}
+				existing.Providers = offeredBy
+				continue
+			}
+			byID[hotel.HotelID] = len(merged.Hotels)
+			merged.Hotels = append(merged.Hotels, hotel)
+		}
+	}
+	if succeeded == 0 {
+		return nil, false, firstErr
+	}
+
+	sort.Slice(merged.Hotels, func(i, j int) bool {
+		a, b := merged.Hotels[i], merged.Hotels[j]
+		if a.Price != b.Price {
+			return a.Price < b.Price
+		}
+		return a.HotelID < b.HotelID
+	})
+
+	return merged, cacheHit, nil
+}
+
+// mergeProviders returns the sorted union of two sorted provider lists.
+func mergeProviders(a, b []string) []string {
+	merged := slices.Concat(a, b)
+	slices.Sort(merged)
+	return slices.Compact(merged)
+}
@@ -0,0 +1,334 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/content"
+	"github.com/alex-user-go/hotels/internal/geo"
+	"github.com/alex-user-go/hotels/internal/handler"
+	"github.com/alex-user-go/hotels/internal/obs"
+	"github.com/alex-user-go/hotels/internal/providers"
+	"github.com/alex-user-go/hotels/internal/search"
+	"github.com/alex-user-go/hotels/internal/search/anomaly"
+	"github.com/alex-user-go/hotels/internal/search/cache"
+	"github.com/alex-user-go/hotels/internal/search/ratelimit"
+	"github.com/alex-user-go/hotels/internal/search/types"
+)
+
+// testGazetteer covers Paris and its western neighbour Versailles, 18 km apart.
+func testGazetteer(t *testing.T) *geo.Gazetteer {
+	t.Helper()
+	g, err := geo.NewGazetteer([]geo.City{
+		{Name: "paris", Latitude: 48.8566, Longitude: 2.3522, RadiusKm: 12},
+		{Name: "versailles", Latitude: 48.8049, Longitude: 2.1204, RadiusKm: 5},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return g
+}
+
+// cityProvider offers a different set of hotels per city.
+type cityProvider struct {
+	calls  atomic.Int64
+	hotels map[string][]providers.Hotel
+}
+
+func (c *cityProvider) Name() string {
+	return "city"
+}
+
+func (c *cityProvider) Search(ctx context.Context, city, checkin string, nights int, occupancy providers.Occupancy) ([]providers.Hotel, error) {
+	c.calls.Add(1)
+	return c.hotels[city], nil
+}
+
+func TestHandler_SearchHandler_Geo(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	metrics := obs.NewMetrics(logger)
+	searchCache := cache.NewCache(30 * time.Second)
+	defer searchCache.Close()
+	limiter := ratelimit.New(10, time.Minute)
+	defer limiter.Close()
+
+	store, err := content.NewStore([]content.Hotel{
+		{HotelID: "H001", Location: &types.Location{Latitude: 48.8606, Longitude: 2.3376}},  // Louvre, 1.2 km
+		{HotelID: "H002", Location: &types.Location{Latitude: 48.8049, Longitude: 2.1204}},  // Versailles, 17.9 km
+		{HotelID: "H004", Location: &types.Location{Latitude: 48.9362, Longitude: 2.3574}},  // Saint-Denis, 8.9 km
+		{HotelID: "H009", Location: &types.Location{Latitude: 51.5074, Longitude: -0.1278}}, // London
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	provider := &cityProvider{hotels: map[string][]providers.Hotel{
+		"paris": {
+			{HotelID: "H001", Name: "Louvre Hotel", Currency: "EUR", Price: 200},
+			{HotelID: "H003", Name: "No Location", Currency: "EUR", Price: 50},
+			{HotelID: "H004", Name: "Stade Hotel", Currency: "EUR", Price: 90},
+			{HotelID: "H009", Name: "Misfiled London Hotel", Currency: "EUR", Price: 60},
+		},
+		"versailles": {
+			{HotelID: "H002", Name: "Chateau Hotel", Currency: "EUR", Price: 150},
+			{HotelID: "H004", Name: "Stade Hotel", Currency: "EUR", Price: 80},
+		},
+	}}
+	aggregator := search.NewAggregator([]providers.Provider{provider}, 2*time.Second, metrics, logger,
+		search.WithContent(store))
+
+	limits := handler.DefaultLimits()
+	limits.Cities = testGazetteer(t)
+	h := handler.New(aggregator, searchCache, limiter, metrics, logger, handler.WithLimits(limits))
+
+	req := httptest.NewRequest(http.MethodGet,
+		"/search?lat=48.8566&lon=2.3522&radius_km=18&sort=distance&checkin="+futureDate+"&nights=2&adults=2", nil)
+	req.RemoteAddr = "192.168.1.1:12345"
+	w := httptest.NewRecorder()
+
+	h.SearchHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var resp handler.SearchResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(resp.Search.Cities) != 2 || resp.Search.Cities[0] != "paris" || resp.Search.Cities[1] != "versailles" {
+		t.Errorf("search.cities = %v, want [paris versailles]", resp.Search.Cities)
+	}
+
+	// Hotels without a location or outside the radius are dropped; H004 is
+	// offered under both cities and keeps the cheaper offer
+	want := []struct {
+		id       string
+		distance float64
+		price    float64
+	}{
+		{id: "H001", distance: 1.2, price: 200},
+		{id: "H004", distance: 8.91, price: 80},
+		{id: "H002", distance: 17.86, price: 150},
+	}
+	if len(resp.Hotels) != len(want) {
+		t.Fatalf("got %d hotels, want %d: %+v", len(resp.Hotels), len(want), resp.Hotels)
+	}
+	for i, w := range want {
+		got := resp.Hotels[i]
+		if got.HotelID != w.id || got.Price != w.price {
+			t.Errorf("hotels[%d] = %s at %v, want %s at %v", i, got.HotelID, got.Price, w.id, w.price)
+		}
+		if got.DistanceKm == nil || *got.DistanceKm < w.distance-0.1 || *got.DistanceKm > w.distance+0.1 {
+			t.Errorf("hotels[%d].distance_km = %v, want about %v", i, got.DistanceKm, w.distance)
+		}
+	}
+
+	// Each covering city was searched and cached on its own
+	single := httptest.NewRequest(http.MethodGet, "/search?city=versailles&checkin="+futureDate+"&nights=2&adults=2", nil)
+	single.RemoteAddr = "192.168.1.2:12345"
+	sw := httptest.NewRecorder()
+	h.SearchHandler(sw, single)
+
+	var singleResp handler.SearchResponse
+	if err := json.NewDecoder(sw.Body).Decode(&singleResp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if singleResp.Stats.Cache != "hit" || provider.calls.Load() != 2 {
+		t.Errorf("single search cache = %q after %d provider calls, want a hit after 2", singleResp.Stats.Cache, provider.calls.Load())
+	}
+}
+
+// cheapScreen marks every offer below a price as suspect.
+type cheapScreen float64
+
+func (c cheapScreen) Screen(city string, nights int, occupancy providers.Occupancy, offers []types.Hotel) ([]types.Hotel, []anomaly.Finding) {
+	for i := range offers {
+		offers[i].Suspect = offers[i].Price < float64(c)
+	}
+	return offers, nil
+}
+
+func TestHandler_SearchHandler_GeoSuspectAndCost(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	metrics := obs.NewMetrics(logger)
+	searchCache := cache.NewCache(30 * time.Second)
+	defer searchCache.Close()
+	limiter := ratelimit.New(3, time.Minute)
+	defer limiter.Close()
+
+	store, err := content.NewStore([]content.Hotel{
+		{HotelID: "H004", Location: &types.Location{Latitude: 48.9362, Longitude: 2.3574}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	provider := &cityProvider{hotels: map[string][]providers.Hotel{
+		"paris":      {{HotelID: "H004", Name: "Stade Hotel", Currency: "EUR", Price: 90}},
+		"versailles": {{HotelID: "H004", Name: "Stade Hotel", Currency: "EUR", Price: 10}},
+	}}
+	aggregator := search.NewAggregator([]providers.Provider{provider}, 2*time.Second, metrics, logger,
+		search.WithContent(store), search.WithPriceScreen(cheapScreen(50)))
+
+	limits := handler.DefaultLimits()
+	limits.Cities = testGazetteer(t)
+	h := handler.New(aggregator, searchCache, limiter, metrics, logger,
+		handler.WithLimits(limits),
+		handler.WithBatch(handler.BatchConfig{MaxItems: 3, Concurrency: 2, Weight: 1}))
+
+	geoSearch := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet,
+			"/search?lat=48.8566&lon=2.3522&radius_km=18&checkin="+futureDate+"&nights=2&adults=2", nil)
+		req.RemoteAddr = "192.168.1.1:12345"
+		w := httptest.NewRecorder()
+		h.SearchHandler(w, req)
+		return w
+	}
+
+	// The suspect offer from Versailles doesn't replace the plausible one
+	w := geoSearch()
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+	var resp handler.SearchResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(resp.Hotels) != 1 || resp.Hotels[0].Price != 90 || resp.Hotels[0].Suspect {
+		t.Errorf("hotels = %+v, want H004 at 90 and not suspect", resp.Hotels)
+	}
+
+	// Each covering city costs a token: two of three are spent
+	if w := geoSearch(); w.Code != http.StatusTooManyRequests {
+		t.Errorf("second search status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+}
+
+func TestHandler_BatchSearchHandler_GeoCost(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	metrics := obs.NewMetrics(logger)
+	searchCache := cache.NewCache(30 * time.Second)
+	defer searchCache.Close()
+	limiter := ratelimit.New(4, time.Minute)
+	defer limiter.Close()
+
+	provider := &cityProvider{hotels: map[string][]providers.Hotel{}}
+	aggregator := search.NewAggregator([]providers.Provider{provider}, 2*time.Second, metrics, logger)
+	limits := handler.DefaultLimits()
+	limits.Cities = testGazetteer(t)
+	h := handler.New(aggregator, searchCache, limiter, metrics, logger,
+		handler.WithLimits(limits),
+		handler.WithBatch(handler.BatchConfig{MaxItems: 3, Concurrency: 2, Weight: 1}))
+
+	// Paris and Versailles are both within 18 km
+	geoItem := fmt.Sprintf(`{"lat":48.8566,"lon":2.3522,"radius_km":18,"checkin":%q,"nights":2,"adults":2}`, futureDate)
+
+	// Two cities and a city search cost three of four tokens
+	if w := postBatch(h, batchBody(geoItem, batchItem("paris"))); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+	// Another geo item needs two tokens, but only one is left
+	if w := postBatch(h, batchBody(geoItem)); w.Code != http.StatusTooManyRequests {
+		t.Errorf("second batch status = %d, want %d", w.Code, http.StatusTooManyRequests)
+	}
+}
+
+func TestLimits_ParseSearchParams_Geo(t *testing.T) {
+	limits := handler.DefaultLimits()
+	limits.Cities = testGazetteer(t)
+
+	tests := []struct {
+		name       string
+		limits     func(handler.Limits) handler.Limits
+		query      string
+		wantCities []string
+		wantParams []string
+	}{
+		{
+			name:       "default radius",
+			query:      "lat=48.8566&lon=2.3522",
+			wantCities: []string{"paris"},
+		},
+		{
+			name:       "city and coordinates",
+			query:      "city=paris&lat=48.8566&lon=2.3522",
+			wantParams: []string{"city"},
+		},
+		{
+			name:       "missing lon",
+			query:      "lat=48.8566&radius_km=5",
+			wantParams: []string{"lon"},
+		},
+		{
+			name:       "out of range",
+			query:      "lat=91&lon=2.3522&radius_km=500",
+			wantParams: []string{"lat", "radius_km"},
+		},
+		{
+			name:       "nothing nearby",
+			query:      "lat=0&lon=0&radius_km=5",
+			wantParams: []string{"radius_km"},
+		},
+		{
+			name: "geo search disabled",
+			limits: func(l handler.Limits) handler.Limits {
+				l.Cities = nil
+				return l
+			},
+			query:      "lat=48.8566&lon=2.3522",
+			wantParams: []string{"lat"},
+		},
+		{
+			name:       "distance sort without coordinates",
+			query:      "city=paris&sort=distance",
+			wantParams: []string{"sort"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := limits
+			if tt.limits != nil {
+				l = tt.limits(l)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/search?checkin="+futureDate+"&nights=2&adults=2&"+tt.query, nil)
+			params, err := l.ParseSearchParams(req)
+
+			if len(tt.wantParams) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if params.Geo == nil || params.Geo.RadiusKm != handler.DefaultRadiusKm {
+					t.Fatalf("geo = %+v, want default radius", params.Geo)
+				}
+				if len(params.Geo.Cities) != len(tt.wantCities) || params.Geo.Cities[0] != tt.wantCities[0] {
+					t.Errorf("cities = %v, want %v", params.Geo.Cities, tt.wantCities)
+				}
+				return
+			}
+
+			var verr *handler.ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected *ValidationError, got %v", err)
+			}
+			if len(verr.InvalidParams) != len(tt.wantParams) {
+				t.Fatalf("invalid params = %+v, want %v", verr.InvalidParams, tt.wantParams)
+			}
+			for i, name := range tt.wantParams {
+				if verr.InvalidParams[i].Name != name {
+					t.Errorf("invalid_params[%d] = %s, want %s", i, verr.InvalidParams[i].Name, name)
+				}
+			}
+		})
+	}
+}
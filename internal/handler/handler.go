@@ -1,32 +1,64 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
-	"fmt"
+	"errors"
 	"log/slog"
 	"net"
 	"net/http"
-	"strconv"
 	"strings"
 	"time"
 
+	"github.com/alex-user-go/hotels/internal/content"
 	"github.com/alex-user-go/hotels/internal/middleware"
 	"github.com/alex-user-go/hotels/internal/obs"
+	"github.com/alex-user-go/hotels/internal/providers"
 	"github.com/alex-user-go/hotels/internal/search"
 	"github.com/alex-user-go/hotels/internal/search/cache"
+	"github.com/alex-user-go/hotels/internal/search/listing"
+	"github.com/alex-user-go/hotels/internal/search/markup"
 	"github.com/alex-user-go/hotels/internal/search/ratelimit"
 	"github.com/alex-user-go/hotels/internal/search/types"
 )
 
+// DefaultCurrency is the display currency used when none is requested.
+const DefaultCurrency = "EUR"
+
 // Handler handles HTTP requests.
 type Handler struct {
 	aggregator  *search.Aggregator
 	cache       *cache.Cache
 	rateLimiter *ratelimit.Limiter
+	limits      Limits
+	batch       BatchConfig
+	fanOut      FanOutConfig
+	fanOutSlots chan struct{}
+	content     *content.Store
+	markup      *markup.Engine
 	metrics     *obs.Metrics
 	logger      *slog.Logger
 }
 
+// Option configures a Handler.
+type Option func(*Handler)
+
+// WithLimits sets the bounds used to validate search parameters.
+func WithLimits(limits Limits) Option {
+	return func(h *Handler) {
+		h.limits = limits
+	}
+}
+
+// WithMarkup sets the pricing rules applied to every search result. Cached
+// results hold net prices; rules are applied per request, so the caller's
+// API key tier is taken into account.
+func WithMarkup(engine *markup.Engine) Option {
+	return func(h *Handler) {
+		h.markup = engine
+	}
+}
+
 // New creates a new Handler.
 func New(
 	aggregator *search.Aggregator,
@@ -34,171 +66,227 @@ func New(
 	rateLimiter *ratelimit.Limiter,
 	metrics *obs.Metrics,
 	logger *slog.Logger,
+	opts ...Option,
 ) *Handler {
-	return &Handler{
+	h := &Handler{
 		aggregator:  aggregator,
 		cache:       searchCache,
 		rateLimiter: rateLimiter,
+		limits:      DefaultLimits(),
+		batch:       DefaultBatchConfig(),
+		fanOut:      DefaultFanOutConfig(),
 		metrics:     metrics,
 		logger:      logger,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.fanOut.MaxConcurrent > 0 {
+		h.fanOutSlots = make(chan struct{}, h.fanOut.MaxConcurrent)
+	}
+	return h
 }
 
 // SearchResponse represents the complete API response.
 type SearchResponse struct {
-	Search SearchInfo    `json:"search"`
-	Stats  SearchStats   `json:"stats"`
-	Hotels []types.Hotel `json:"hotels"`
+	Search     SearchInfo    `json:"search"`
+	Stats      SearchStats   `json:"stats"`
+	Hotels     []types.Hotel `json:"hotels"`
+	NextCursor string        `json:"next_cursor,omitempty"`
 }
 
-// SearchInfo contains the search parameters.
+// SearchInfo contains the search parameters. Geo searches report the
+// searched area and the cities covering it instead of City.
 type SearchInfo struct {
-	City    string `json:"city"`
-	Checkin string `json:"checkin"`
-	Nights  int    `json:"nights"`
-	Adults  int    `json:"adults"`
+	City     string           `json:"city,omitempty"`
+	Location *types.Location  `json:"location,omitempty"`
+	RadiusKm float64          `json:"radius_km,omitempty"`
+	Cities   []string         `json:"cities,omitempty"`
+	Checkin  string           `json:"checkin,omitempty"`
+	Nights   int              `json:"nights"`
+	Adults   int              `json:"adults"`
+	Children int              `json:"children"`
+	Rooms    []providers.Room `json:"rooms"`
+	Currency string           `json:"currency"`
 }
 
-// SearchStats contains search statistics.
+// SearchStats contains search statistics. Skipped providers were not
+// queried because their outbound budget was used up or they had too many
+// searches in flight.
 type SearchStats struct {
 	ProvidersTotal     int    `json:"providers_total"`
 	ProvidersSucceeded int    `json:"providers_succeeded"`
 	ProvidersFailed    int    `json:"providers_failed"`
+	ProvidersSkipped   int    `json:"providers_skipped"`
+	HotelsTotal        int    `json:"hotels_total"`
 	Cache              string `json:"cache"`
 	DurationMs         int64  `json:"duration_ms"`
 }
 
-// SearchHandler handles /search requests.
+// SearchHandler handles GET /search requests with query parameters.
 func (h *Handler) SearchHandler(w http.ResponseWriter, r *http.Request) {
+	h.handleSearch(w, r, h.limits.ParseSearchParams)
+}
+
+// SearchJSONHandler handles POST /search requests with a JSON body.
+// Results are cached and served exactly like the GET form.
+func (h *Handler) SearchJSONHandler(w http.ResponseWriter, r *http.Request) {
+	h.handleSearch(w, r, h.limits.ParseSearchRequest)
+}
+
+// handleSearch serves a single search whose parameters are read by parse.
+func (h *Handler) handleSearch(w http.ResponseWriter, r *http.Request, parse func(*http.Request) (*SearchParams, error)) {
 	startTime := time.Now()
 	h.metrics.IncRequests()
 	requestID := middleware.RequestID(r.Context())
 
-	// Check rate limit
+	// Parse and validate parameters
 	ip := ExtractIP(r)
-	if !h.rateLimiter.Allow(ip) {
-		h.logger.Warn("rate limit exceeded", "request_id", requestID, "ip", ip)
+	params, err := parse(r)
+	if err != nil {
+		h.logger.Debug("invalid request parameters", "request_id", requestID, "error", err, "ip", ip)
+		writeRequestError(w, err)
+		return
+	}
+
+	// Check rate limit. A geo search is charged for every covering city
+	// like a batched search.
+	cost := 1
+	if params.Geo != nil {
+		cost = h.batch.cost(len(params.Geo.Cities))
+	}
+	if !h.rateLimiter.AllowN(ip, cost) {
+		h.logger.Warn("rate limit exceeded", "request_id", requestID, "ip", ip, "cost", cost)
 		writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
 		return
 	}
 
-	// Parse and validate query parameters
-	params, err := ParseSearchParams(r)
-	if err != nil {
-		h.logger.Debug("invalid request parameters", "request_id", requestID, "error", err, "ip", ip)
-		writeError(w, http.StatusBadRequest, err.Error())
+	response, problem := h.searchPage(r.Context(), params, startTime)
+	if problem != nil {
+		writeProblem(w, *problem)
 		return
 	}
 
-	// Generate cache key and fetch from cache
-	key := h.cache.Key(params.City, params.Checkin, params.Nights, params.Adults)
+	h.writeJSON(w, http.StatusOK, response)
+}
 
-	// Get or fetch from cache
-	result, cacheHit, err := h.cache.GetOrFetch(r.Context(), key, func() (*types.Result, error) {
-		return h.aggregator.Search(r.Context(), params.City, params.Checkin, params.Nights, params.Adults)
-	})
+// writeJSON writes v as a JSON response with the given status.
+func (h *Handler) writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		// Can't change status after WriteHeader, just log
+		h.logger.Error("failed to encode response", "error", err)
+	}
+}
 
+// searchPage runs a search and applies its listing options, returning either
+// the response body or a problem describing why it failed.
+func (h *Handler) searchPage(ctx context.Context, params *SearchParams, startTime time.Time) (*SearchResponse, *Problem) {
+	search := h.search
+	if params.Geo != nil {
+		search = h.geoSearch
+	}
+	result, cacheHit, err := search(ctx, params)
 	if err != nil {
 		h.logger.Error("search failed",
-			"request_id", requestID,
+			"request_id", middleware.RequestID(ctx),
 			"error", err,
 			"city", params.City,
 			"checkin", params.Checkin,
-			"ip", ip,
 		)
-		writeError(w, http.StatusInternalServerError, "search failed")
-		return
+		problem := errorProblem(http.StatusInternalServerError, "search failed")
+		switch {
+		case errors.Is(err, providers.ErrBudgetExhausted):
+			problem = errorProblem(http.StatusServiceUnavailable, "provider budgets exhausted")
+		case errors.Is(err, providers.ErrBulkheadFull):
+			problem = errorProblem(http.StatusServiceUnavailable, "providers at capacity")
+		}
+		return nil, &problem
 	}
 
-	// Calculate duration
-	duration := time.Since(startTime).Milliseconds()
+	// Sort, filter and paginate the shared cached result
+	page, err := listing.Apply(result.Hotels, params.List)
+	if err != nil {
+		problem := validationProblem(&ValidationError{
+			InvalidParams: []InvalidParam{{Name: "cursor", Reason: err.Error()}},
+		})
+		return nil, &problem
+	}
 
 	// Build response
 	cacheStatus := "miss"
 	if cacheHit {
 		cacheStatus = "hit"
-		h.metrics.IncCacheHits()
 	}
 
-	response := SearchResponse{
-		Search: SearchInfo{
-			City:    params.City,
-			Checkin: params.Checkin,
-			Nights:  params.Nights,
-			Adults:  params.Adults,
-		},
+	return &SearchResponse{
+		Search: newSearchInfo(params),
 		Stats: SearchStats{
 			ProvidersTotal:     result.ProvidersTotal,
 			ProvidersSucceeded: result.ProvidersSucceeded,
 			ProvidersFailed:    result.ProvidersFailed,
+			ProvidersSkipped:   result.ProvidersSkipped,
+			HotelsTotal:        page.Total,
 			Cache:              cacheStatus,
-			DurationMs:         duration,
+			DurationMs:         time.Since(startTime).Milliseconds(),
 		},
-		Hotels: result.Hotels,
-	}
-
-	// Write response
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		// Can't change status after WriteHeader, just log
-		h.logger.Error("failed to encode response", "error", err)
-	}
-}
-
-// SearchParams holds validated search parameters.
-type SearchParams struct {
-	City    string
-	Checkin string
-	Nights  int
-	Adults  int
+		Hotels:     page.Hotels,
+		NextCursor: page.NextCursor,
+	}, nil
 }
 
-// ParseSearchParams parses and validates search parameters from the request.
-func ParseSearchParams(r *http.Request) (*SearchParams, error) {
-	query := r.URL.Query()
-
-	// City - required, non-empty
-	city := strings.TrimSpace(query.Get("city"))
-	if city == "" {
-		return nil, fmt.Errorf("city is required")
-	}
-
-	// Checkin - required, YYYY-MM-DD format
-	checkin := strings.TrimSpace(query.Get("checkin"))
-	if checkin == "" {
-		return nil, fmt.Errorf("checkin is required")
+// newSearchInfo describes the parameters of a search in a response.
+func newSearchInfo(params *SearchParams) SearchInfo {
+	info := SearchInfo{
+		City:     params.City,
+		Checkin:  params.Checkin,
+		Nights:   params.Nights,
+		Adults:   params.Occupancy.Adults(),
+		Children: params.Occupancy.Children(),
+		Rooms:    params.Occupancy,
+		Currency: params.Currency,
 	}
-	if _, err := time.Parse("2006-01-02", checkin); err != nil {
-		return nil, fmt.Errorf("checkin must be in YYYY-MM-DD format")
+	if g := params.Geo; g != nil {
+		info.Location = &g.Location
+		info.RadiusKm = g.RadiusKm
+		info.Cities = g.Cities
 	}
+	return info
+}
 
-	// Nights - required, positive integer
-	nightsStr := query.Get("nights")
-	if nightsStr == "" {
-		return nil, fmt.Errorf("nights is required")
-	}
-	nights, err := strconv.Atoi(nightsStr)
-	if err != nil || nights <= 0 {
-		return nil, fmt.Errorf("nights must be a positive integer")
-	}
+// search runs an aggregated search through the cache. Concurrent searches
+// with the same parameters, whatever the order of rooms and child ages, are
+// collapsed into a single provider fan-out.
+// Pricing rules are applied to a copy of the cached result.
+func (h *Handler) search(ctx context.Context, params *SearchParams) (*types.Result, bool, error) {
+	key := h.cache.Key(params.City, params.Checkin, params.Nights, params.Occupancy.Canonical().String(), params.Currency)
 
-	// Adults - required, positive integer
-	adultsStr := query.Get("adults")
-	if adultsStr == "" {
-		return nil, fmt.Errorf("adults is required")
+	result, cacheHit, err := h.cache.GetOrFetch(ctx, key, func() (*types.Result, error) {
+		return h.aggregator.Search(ctx, params.City, params.Checkin, params.Nights, params.Occupancy, params.Currency)
+	})
+	if cacheHit {
+		h.metrics.IncCacheHits()
 	}
-	adults, err := strconv.Atoi(adultsStr)
-	if err != nil || adults <= 0 {
-		return nil, fmt.Errorf("adults must be a positive integer")
+	if err != nil || h.markup == nil {
+		return result, cacheHit, err
 	}
 
-	return &SearchParams{
-		City:    city,
-		Checkin: checkin,
-		Nights:  nights,
-		Adults:  adults,
-	}, nil
+	result, applied := h.markup.Apply(markup.Query{
+		City:    params.City,
+		Checkin: params.Checkin,
+		Nights:  params.Nights,
+		APIKey:  middleware.APIKey(ctx),
+	}, result)
+	if len(applied) > 0 {
+		h.logger.Info("pricing rules applied",
+			"request_id", middleware.RequestID(ctx),
+			"city", params.City,
+			"checkin", params.Checkin,
+			"rules", applied,
+		)
+	}
+	return result, cacheHit, nil
 }
 
 // ExtractIP extracts the client IP from the request.
@@ -224,10 +312,3 @@ func ExtractIP(r *http.Request) string {
 	}
 	return ip
 }
-
-// writeError writes a JSON error response.
-func writeError(w http.ResponseWriter, status int, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
-}
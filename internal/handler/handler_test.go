@@ -8,17 +8,24 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/alex-user-go/hotels/internal/handler"
+	"github.com/alex-user-go/hotels/internal/middleware"
 	"github.com/alex-user-go/hotels/internal/obs"
 	"github.com/alex-user-go/hotels/internal/providers"
 	"github.com/alex-user-go/hotels/internal/search"
 	"github.com/alex-user-go/hotels/internal/search/cache"
+	"github.com/alex-user-go/hotels/internal/search/markup"
 	"github.com/alex-user-go/hotels/internal/search/ratelimit"
 )
 
+// futureDate is a check-in date that passes the default limits.
+var futureDate = time.Now().AddDate(0, 1, 0).Format("2006-01-02")
+
 func TestHandler_SearchHandler(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -29,7 +36,7 @@ func TestHandler_SearchHandler(t *testing.T) {
 	}{
 		{
 			name:        "successful search",
-			queryParams: "city=paris&checkin=2025-12-01&nights=2&adults=2",
+			queryParams: "city=paris&checkin=" + futureDate + "&nights=2&adults=2",
 			setupRateLimit: func(l *ratelimit.Limiter, ip string) {
 				// Allow requests
 			},
@@ -37,7 +44,7 @@ func TestHandler_SearchHandler(t *testing.T) {
 		},
 		{
 			name:        "missing city",
-			queryParams: "checkin=2025-12-01&nights=2&adults=2",
+			queryParams: "checkin=" + futureDate + "&nights=2&adults=2",
 			setupRateLimit: func(l *ratelimit.Limiter, ip string) {
 			},
 			wantStatus: http.StatusBadRequest,
@@ -61,7 +68,7 @@ func TestHandler_SearchHandler(t *testing.T) {
 		},
 		{
 			name:        "missing nights",
-			queryParams: "city=paris&checkin=2025-12-01&adults=2",
+			queryParams: "city=paris&checkin=" + futureDate + "&adults=2",
 			setupRateLimit: func(l *ratelimit.Limiter, ip string) {
 			},
 			wantStatus: http.StatusBadRequest,
@@ -69,7 +76,7 @@ func TestHandler_SearchHandler(t *testing.T) {
 		},
 		{
 			name:        "invalid nights (non-integer)",
-			queryParams: "city=paris&checkin=2025-12-01&nights=abc&adults=2",
+			queryParams: "city=paris&checkin=" + futureDate + "&nights=abc&adults=2",
 			setupRateLimit: func(l *ratelimit.Limiter, ip string) {
 			},
 			wantStatus: http.StatusBadRequest,
@@ -77,7 +84,7 @@ func TestHandler_SearchHandler(t *testing.T) {
 		},
 		{
 			name:        "invalid nights (zero)",
-			queryParams: "city=paris&checkin=2025-12-01&nights=0&adults=2",
+			queryParams: "city=paris&checkin=" + futureDate + "&nights=0&adults=2",
 			setupRateLimit: func(l *ratelimit.Limiter, ip string) {
 			},
 			wantStatus: http.StatusBadRequest,
@@ -85,7 +92,7 @@ func TestHandler_SearchHandler(t *testing.T) {
 		},
 		{
 			name:        "invalid nights (negative)",
-			queryParams: "city=paris&checkin=2025-12-01&nights=-1&adults=2",
+			queryParams: "city=paris&checkin=" + futureDate + "&nights=-1&adults=2",
 			setupRateLimit: func(l *ratelimit.Limiter, ip string) {
 			},
 			wantStatus: http.StatusBadRequest,
@@ -93,7 +100,7 @@ func TestHandler_SearchHandler(t *testing.T) {
 		},
 		{
 			name:        "missing adults",
-			queryParams: "city=paris&checkin=2025-12-01&nights=2",
+			queryParams: "city=paris&checkin=" + futureDate + "&nights=2",
 			setupRateLimit: func(l *ratelimit.Limiter, ip string) {
 			},
 			wantStatus: http.StatusBadRequest,
@@ -101,15 +108,23 @@ func TestHandler_SearchHandler(t *testing.T) {
 		},
 		{
 			name:        "invalid adults (zero)",
-			queryParams: "city=paris&checkin=2025-12-01&nights=2&adults=0",
+			queryParams: "city=paris&checkin=" + futureDate + "&nights=2&adults=0",
 			setupRateLimit: func(l *ratelimit.Limiter, ip string) {
 			},
 			wantStatus: http.StatusBadRequest,
 			wantError:  "adults must be a positive integer",
 		},
+		{
+			name:        "invalid currency",
+			queryParams: "city=paris&checkin=" + futureDate + "&nights=2&adults=2&currency=EURO",
+			setupRateLimit: func(l *ratelimit.Limiter, ip string) {
+			},
+			wantStatus: http.StatusBadRequest,
+			wantError:  "currency must be a 3-letter ISO 4217 code",
+		},
 		{
 			name:        "rate limit exceeded",
-			queryParams: "city=paris&checkin=2025-12-01&nights=2&adults=2",
+			queryParams: "city=paris&checkin=" + futureDate + "&nights=2&adults=2",
 			setupRateLimit: func(l *ratelimit.Limiter, ip string) {
 				// Exhaust rate limit
 				for i := 0; i < 10; i++ {
@@ -156,12 +171,18 @@ func TestHandler_SearchHandler(t *testing.T) {
 
 			// Check error message if expected
 			if tt.wantError != "" {
-				var errResp map[string]string
-				if err := json.NewDecoder(w.Body).Decode(&errResp); err != nil {
+				if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+					t.Errorf("Content-Type = %q, want application/problem+json", ct)
+				}
+				var problem handler.Problem
+				if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
 					t.Fatalf("failed to decode error response: %v", err)
 				}
-				if errResp["error"] != tt.wantError {
-					t.Errorf("error = %q, want %q", errResp["error"], tt.wantError)
+				if problem.Status != tt.wantStatus {
+					t.Errorf("problem status = %d, want %d", problem.Status, tt.wantStatus)
+				}
+				if problem.Detail != tt.wantError {
+					t.Errorf("error = %q, want %q", problem.Detail, tt.wantError)
 				}
 			}
 
@@ -177,6 +198,9 @@ func TestHandler_SearchHandler(t *testing.T) {
 				if resp.Search.City == "" {
 					t.Error("expected search.city to be set")
 				}
+				if resp.Search.Currency != handler.DefaultCurrency {
+					t.Errorf("search.currency = %q, want %q", resp.Search.Currency, handler.DefaultCurrency)
+				}
 				if resp.Stats.Cache == "" {
 					t.Error("expected stats.cache to be set")
 				}
@@ -269,17 +293,17 @@ func TestParseSearchParams(t *testing.T) {
 	}{
 		{
 			name:      "valid params",
-			query:     "city=paris&checkin=2025-12-01&nights=2&adults=2",
+			query:     "city=paris&checkin=" + futureDate + "&nights=2&adults=2",
 			wantError: "",
 		},
 		{
 			name:      "empty city",
-			query:     "city=&checkin=2025-12-01&nights=2&adults=2",
+			query:     "city=&checkin=" + futureDate + "&nights=2&adults=2",
 			wantError: "city is required",
 		},
 		{
 			name:      "whitespace city",
-			query:     "city=%20%20%20&checkin=2025-12-01&nights=2&adults=2",
+			query:     "city=%20%20%20&checkin=" + futureDate + "&nights=2&adults=2",
 			wantError: "city is required",
 		},
 		{
@@ -289,12 +313,27 @@ func TestParseSearchParams(t *testing.T) {
 		},
 		{
 			name:      "negative nights",
-			query:     "city=paris&checkin=2025-12-01&nights=-5&adults=2",
+			query:     "city=paris&checkin=" + futureDate + "&nights=-5&adults=2",
 			wantError: "nights must be a positive integer",
 		},
+		{
+			name:      "unknown sort",
+			query:     "city=paris&checkin=" + futureDate + "&nights=2&adults=2&sort=stars",
+			wantError: "sort must be one of price_asc, price_desc, name, providers, distance",
+		},
+		{
+			name:      "min price above max price",
+			query:     "city=paris&checkin=" + futureDate + "&nights=2&adults=2&min_price=200&max_price=100",
+			wantError: "min_price must not exceed max_price",
+		},
+		{
+			name:      "limit out of range",
+			query:     "city=paris&checkin=" + futureDate + "&nights=2&adults=2&limit=500",
+			wantError: "limit must be an integer between 1 and 100",
+		},
 		{
 			name:      "non-integer adults",
-			query:     "city=paris&checkin=2025-12-01&nights=2&adults=two",
+			query:     "city=paris&checkin=" + futureDate + "&nights=2&adults=two",
 			wantError: "adults must be a positive integer",
 		},
 	}
@@ -329,7 +368,7 @@ func (m *mockProvider) Name() string {
 	return "mock"
 }
 
-func (m *mockProvider) Search(ctx context.Context, city, checkin string, nights, adults int) ([]providers.Hotel, error) {
+func (m *mockProvider) Search(ctx context.Context, city, checkin string, nights int, occupancy providers.Occupancy) ([]providers.Hotel, error) {
 	return []providers.Hotel{
 		{HotelID: "1", Name: "Test Hotel", Price: 100.0, Currency: "EUR"},
 	}, nil
@@ -342,7 +381,7 @@ func (f *failingProvider) Name() string {
 	return "failing"
 }
 
-func (f *failingProvider) Search(ctx context.Context, city, checkin string, nights, adults int) ([]providers.Hotel, error) {
+func (f *failingProvider) Search(ctx context.Context, city, checkin string, nights int, occupancy providers.Occupancy) ([]providers.Hotel, error) {
 	return nil, errors.New("provider error")
 }
 
@@ -360,7 +399,7 @@ func TestHandler_SearchHandler_ProviderError(t *testing.T) {
 
 	h := handler.New(aggregator, searchCache, limiter, metrics, logger)
 
-	req := httptest.NewRequest(http.MethodGet, "/search?city=paris&checkin=2025-12-01&nights=2&adults=2", nil)
+	req := httptest.NewRequest(http.MethodGet, "/search?city=paris&checkin="+futureDate+"&nights=2&adults=2", nil)
 	req.RemoteAddr = "192.168.1.1:12345"
 	w := httptest.NewRecorder()
 
@@ -370,11 +409,89 @@ func TestHandler_SearchHandler_ProviderError(t *testing.T) {
 		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
 	}
 
-	var errResp map[string]string
-	if err := json.NewDecoder(w.Body).Decode(&errResp); err != nil {
+	var problem handler.Problem
+	if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
 		t.Fatalf("failed to decode error response: %v", err)
 	}
-	if errResp["error"] != "search failed" {
-		t.Errorf("error = %q, want %q", errResp["error"], "search failed")
+	if problem.Detail != "search failed" {
+		t.Errorf("error = %q, want %q", problem.Detail, "search failed")
+	}
+}
+
+// newTestHandler creates a Handler backed by the given providers.
+func newTestHandler(provs ...providers.Provider) (*handler.Handler, func()) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	metrics := obs.NewMetrics(logger)
+	searchCache := cache.NewCache(30 * time.Second)
+	limiter := ratelimit.New(10, time.Minute)
+	aggregator := search.NewAggregator(provs, 2*time.Second, metrics, logger)
+
+	h := handler.New(aggregator, searchCache, limiter, metrics, logger)
+	return h, func() {
+		searchCache.Close()
+		limiter.Close()
+	}
+}
+
+func TestHandler_SearchHandler_Markup(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	metrics := obs.NewMetrics(logger)
+	searchCache := cache.NewCache(30 * time.Second)
+	defer searchCache.Close()
+	limiter := ratelimit.New(10, time.Minute)
+	defer limiter.Close()
+
+	rulesPath := filepath.Join(t.TempDir(), "rules.json")
+	rules := `{
+		"tiers": {"partner-key": "partner"},
+		"rules": [
+			{"id": "base", "percent": 10},
+			{"id": "partner", "tiers": ["partner"], "percent": -10}
+		]
+	}`
+	if err := os.WriteFile(rulesPath, []byte(rules), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	engine, err := markup.NewEngine(rulesPath, 0, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer engine.Close()
+
+	aggregator := search.NewAggregator([]providers.Provider{&mockProvider{}}, 2*time.Second, metrics, logger)
+	h := handler.New(aggregator, searchCache, limiter, metrics, logger, handler.WithMarkup(engine))
+	srv := middleware.APIKeys(http.HandlerFunc(h.SearchHandler))
+
+	tests := []struct {
+		name      string
+		apiKey    string
+		wantPrice float64
+	}{
+		{name: "public tier", wantPrice: 110},
+		// Served from the cache with the net price, then priced for the tier
+		{name: "partner tier", apiKey: "partner-key", wantPrice: 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/search?city=paris&checkin="+futureDate+"&nights=2&adults=2", nil)
+			req.Header.Set("X-API-Key", tt.apiKey)
+			w := httptest.NewRecorder()
+			srv.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, body: %s", w.Code, w.Body.String())
+			}
+			var resp handler.SearchResponse
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if got := resp.Hotels[0]; got.Price != tt.wantPrice || got.PricePerNight != tt.wantPrice/2 {
+				t.Errorf("price = %v (%v per night), want %v", got.Price, got.PricePerNight, tt.wantPrice)
+			}
+			if strings.Contains(w.Body.String(), "net") {
+				t.Errorf("net price leaked in response: %s", w.Body.String())
+			}
+		})
 	}
 }
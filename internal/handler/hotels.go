@@ -0,0 +1,32 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/alex-user-go/hotels/internal/content"
+)
+
+// WithContent sets the hotel content store served by HotelHandler.
+func WithContent(store *content.Store) Option {
+	return func(h *Handler) {
+		h.content = store
+	}
+}
+
+// HotelHandler handles GET /hotels/{id} requests with the content of a hotel.
+func (h *Handler) HotelHandler(w http.ResponseWriter, r *http.Request) {
+	h.metrics.IncRequests()
+
+	if h.content == nil {
+		writeError(w, http.StatusNotFound, content.ErrNotFound.Error())
+		return
+	}
+
+	hotel, err := h.content.Get(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, hotel)
+}
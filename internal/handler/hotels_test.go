@@ -0,0 +1,107 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/content"
+	"github.com/alex-user-go/hotels/internal/handler"
+	"github.com/alex-user-go/hotels/internal/obs"
+	"github.com/alex-user-go/hotels/internal/providers"
+	"github.com/alex-user-go/hotels/internal/search"
+	"github.com/alex-user-go/hotels/internal/search/cache"
+	"github.com/alex-user-go/hotels/internal/search/ratelimit"
+)
+
+// contentProvider returns a hotel missing from the catalog with its content.
+type contentProvider struct{}
+
+func (c *contentProvider) Name() string {
+	return "content"
+}
+
+func (c *contentProvider) Search(ctx context.Context, city, checkin string, nights int, occupancy providers.Occupancy) ([]providers.Hotel, error) {
+	return []providers.Hotel{
+		{HotelID: "H001", Name: "Grand Hotel", Currency: "EUR", Price: 150},
+		{HotelID: "H005", Name: "Seaside Resort", Currency: "EUR", Price: 200, Address: "15 Beach Promenade", Stars: 4},
+	}, nil
+}
+
+func TestHandler_HotelHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	metrics := obs.NewMetrics(logger)
+	searchCache := cache.NewCache(30 * time.Second)
+	defer searchCache.Close()
+	limiter := ratelimit.New(10, time.Minute)
+	defer limiter.Close()
+
+	store, err := content.NewStore([]content.Hotel{
+		{HotelID: "H001", Name: "Grand Hotel", Address: "1 Grand Avenue", Stars: 5, Images: []string{"facade.jpg"}},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	aggregator := search.NewAggregator([]providers.Provider{&contentProvider{}}, 2*time.Second, metrics, logger,
+		search.WithContent(store))
+	h := handler.New(aggregator, searchCache, limiter, metrics, logger, handler.WithContent(store))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /search", h.SearchHandler)
+	mux.HandleFunc("GET /hotels/{id}", h.HotelHandler)
+
+	// Search results are enriched from the catalog
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/search?city=paris&checkin="+futureDate+"&nights=2&adults=2", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("search status = %d, body: %s", w.Code, w.Body.String())
+	}
+	var resp handler.SearchResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if first := resp.Hotels[0]; first.HotelID != "H001" || first.Address != "1 Grand Avenue" || first.Image != "facade.jpg" {
+		t.Errorf("expected enriched H001 first, got %+v", first)
+	}
+
+	tests := []struct {
+		name        string
+		path        string
+		wantStatus  int
+		wantAddress string
+	}{
+		{name: "catalog hotel", path: "/hotels/H001", wantStatus: http.StatusOK, wantAddress: "1 Grand Avenue"},
+		{name: "learned from provider", path: "/hotels/H005", wantStatus: http.StatusOK, wantAddress: "15 Beach Promenade"},
+		{name: "unknown hotel", path: "/hotels/H404", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body: %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+					t.Errorf("Content-Type = %q, want application/problem+json", ct)
+				}
+				return
+			}
+
+			var hotel content.Hotel
+			if err := json.NewDecoder(w.Body).Decode(&hotel); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if hotel.Address != tt.wantAddress {
+				t.Errorf("address = %q, want %q", hotel.Address, tt.wantAddress)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/middleware"
+)
+
+// MultiCityParams holds validated multi-city search parameters.
+// Search holds everything but the city; one aggregated search is run per
+// city in Cities.
+type MultiCityParams struct {
+	Search SearchParams
+	Cities []string
+}
+
+// MultiCityResponse is the response of a multi-city search.
+type MultiCityResponse struct {
+	Search MultiCityInfo  `json:"search"`
+	Stats  MultiCityStats `json:"stats"`
+	Cities []CityResult   `json:"cities"`
+}
+
+// MultiCityInfo contains the multi-city search parameters.
+type MultiCityInfo struct {
+	Cities   []string `json:"cities"`
+	Checkin  string   `json:"checkin"`
+	Nights   int      `json:"nights"`
+	Adults   int      `json:"adults"`
+	Children int      `json:"children"`
+	Currency string   `json:"currency"`
+}
+
+// MultiCityStats contains multi-city search statistics.
+type MultiCityStats struct {
+	CitiesTotal     int   `json:"cities_total"`
+	CitiesSucceeded int   `json:"cities_succeeded"`
+	CitiesFailed    int   `json:"cities_failed"`
+	CacheHits       int   `json:"cache_hits"`
+	DurationMs      int64 `json:"duration_ms"`
+}
+
+// CityResult is the outcome of the search for one city. Exactly one of
+// Result and Error is set.
+type CityResult struct {
+	City   string          `json:"city"`
+	Status int             `json:"status"`
+	Result *SearchResponse `json:"result,omitempty"`
+	Error  *Problem        `json:"error,omitempty"`
+}
+
+// ParseMultiCityParams parses and validates multi-city search parameters.
+// cities is a comma-separated list of at most MaxCities cities; duplicates
+// are searched once. Listing options apply to each city separately.
+func (l Limits) ParseMultiCityParams(r *http.Request) (*MultiCityParams, error) {
+	query := r.URL.Query()
+	errs := &ValidationError{}
+
+	params := &MultiCityParams{
+		Search: SearchParams{
+			Checkin:   strings.TrimSpace(query.Get("checkin")),
+			Nights:    parseRequiredInt(query, "nights", errs),
+			Occupancy: parseOccupancy(query, errs),
+			Currency:  strings.ToUpper(strings.TrimSpace(query.Get("currency"))),
+			List:      parseListOptions(query, errs),
+		},
+	}
+
+	for city := range strings.SplitSeq(query.Get("cities"), ",") {
+		city = strings.TrimSpace(city)
+		if city != "" && !slices.Contains(params.Cities, city) {
+			params.Cities = append(params.Cities, city)
+		}
+	}
+	switch {
+	case len(params.Cities) == 0:
+		errs.add("cities", "cities is required")
+	case l.MaxCities > 0 && len(params.Cities) > l.MaxCities:
+		errs.add("cities", fmt.Sprintf("cities must contain at most %d cities", l.MaxCities))
+	}
+
+	l.validateCheckin("checkin", params.Search.Checkin, errs)
+
+	// A cursor belongs to a single city's listing
+	if params.Search.List.Cursor != "" {
+		errs.add("cursor", "cursor is not supported for multi-city searches")
+	}
+
+	l.validateStay(&params.Search, errs)
+	if len(errs.InvalidParams) > 0 {
+		return nil, errs
+	}
+	return params, nil
+}
+
+// MultiCitySearchHandler handles GET /search/multi requests.
+// One aggregated search per city is run through the cache, so later
+// single-city searches with the same parameters are cache hits.
+func (h *Handler) MultiCitySearchHandler(w http.ResponseWriter, r *http.Request) {
+	startTime := time.Now()
+	h.metrics.IncRequests()
+	requestID := middleware.RequestID(r.Context())
+
+	ip := ExtractIP(r)
+	params, err := h.limits.ParseMultiCityParams(r)
+	if err != nil {
+		h.logger.Debug("invalid request parameters", "request_id", requestID, "error", err, "ip", ip)
+		writeRequestError(w, err)
+		return
+	}
+
+	// Check rate limit, charging every city like a batched search
+	cost := h.batch.cost(len(params.Cities))
+	if !h.rateLimiter.AllowN(ip, cost) {
+		h.logger.Warn("rate limit exceeded", "request_id", requestID, "ip", ip, "cost", cost)
+		writeError(w, http.StatusTooManyRequests, "rate limit exceeded")
+		return
+	}
+
+	cities := h.searchCities(r.Context(), params, startTime)
+
+	stats := MultiCityStats{CitiesTotal: len(cities)}
+	for _, c := range cities {
+		if c.Result == nil {
+			stats.CitiesFailed++
+			continue
+		}
+		stats.CitiesSucceeded++
+		if c.Result.Stats.Cache == "hit" {
+			stats.CacheHits++
+		}
+	}
+	if stats.CitiesSucceeded == 0 {
+		writeError(w, http.StatusInternalServerError, "search failed")
+		return
+	}
+	stats.DurationMs = time.Since(startTime).Milliseconds()
+
+	h.writeJSON(w, http.StatusOK, MultiCityResponse{
+		Search: MultiCityInfo{
+			Cities:   params.Cities,
+			Checkin:  params.Search.Checkin,
+			Nights:   params.Search.Nights,
+			Adults:   params.Search.Occupancy.Adults(),
+			Children: params.Search.Occupancy.Children(),
+			Currency: params.Search.Currency,
+		},
+		Stats:  stats,
+		Cities: cities,
+	})
+}
+
+// searchCities runs one search per city with bounded concurrency.
+// Results are returned in the requested city order.
+func (h *Handler) searchCities(ctx context.Context, params *MultiCityParams, startTime time.Time) []CityResult {
+	results := make([]CityResult, len(params.Cities))
+
+	runBounded(ctx, len(params.Cities), h.fanOut.Concurrency, h.fanOutSlots, func(i int) {
+		search := params.Search
+		search.City = params.Cities[i]
+
+		var problem *Problem
+		if ctx.Err() != nil {
+			p := errorProblem(http.StatusServiceUnavailable, "search cancelled")
+			problem = &p
+		} else {
+			var response *SearchResponse
+			if response, problem = h.searchPage(ctx, &search, startTime); problem == nil {
+				results[i] = CityResult{City: search.City, Status: http.StatusOK, Result: response}
+				return
+			}
+		}
+		results[i] = CityResult{City: search.City, Status: problem.Status, Error: problem}
+	})
+
+	return results
+}
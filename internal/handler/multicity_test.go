@@ -0,0 +1,209 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/handler"
+	"github.com/alex-user-go/hotels/internal/obs"
+	"github.com/alex-user-go/hotels/internal/providers"
+	"github.com/alex-user-go/hotels/internal/search"
+	"github.com/alex-user-go/hotels/internal/search/cache"
+	"github.com/alex-user-go/hotels/internal/search/ratelimit"
+)
+
+func TestHandler_MultiCitySearchHandler(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	metrics := obs.NewMetrics(logger)
+	searchCache := cache.NewCache(30 * time.Second)
+	defer searchCache.Close()
+	limiter := ratelimit.New(10, time.Minute)
+	defer limiter.Close()
+
+	provider := &countingProvider{delay: 20 * time.Millisecond}
+	aggregator := search.NewAggregator([]providers.Provider{provider}, 2*time.Second, metrics, logger)
+	h := handler.New(aggregator, searchCache, limiter, metrics, logger,
+		handler.WithFanOut(handler.FanOutConfig{Concurrency: 2}))
+
+	req := httptest.NewRequest(http.MethodGet,
+		"/search/multi?cities=paris,rome,%20paris%20,berlin,madrid&checkin="+futureDate+"&nights=2&adults=2", nil)
+	req.RemoteAddr = "192.168.1.1:12345"
+	w := httptest.NewRecorder()
+
+	h.MultiCitySearchHandler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d (body: %s)", w.Code, http.StatusOK, w.Body.String())
+	}
+
+	var resp handler.MultiCityResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	wantCities := []string{"paris", "rome", "berlin", "madrid"}
+	if len(resp.Cities) != len(wantCities) {
+		t.Fatalf("got %d cities, want %d", len(resp.Cities), len(wantCities))
+	}
+	for i, city := range wantCities {
+		got := resp.Cities[i]
+		if got.City != city || got.Status != http.StatusOK || got.Result == nil {
+			t.Fatalf("cities[%d] = %+v, want a result for %s", i, got, city)
+		}
+		if got.Result.Search.City != city || got.Result.Hotels[0].Name != "Hotel "+city {
+			t.Errorf("cities[%d] holds results for %s", i, got.Result.Search.City)
+		}
+	}
+	if resp.Stats.CitiesTotal != 4 || resp.Stats.CitiesSucceeded != 4 || resp.Stats.CacheHits != 0 {
+		t.Errorf("unexpected stats: %+v", resp.Stats)
+	}
+
+	// Duplicates are searched once, at most two cities at a time
+	if got := provider.calls.Load(); got != 4 {
+		t.Errorf("provider called %d times, want 4", got)
+	}
+	if got := provider.peak.Load(); got > 2 {
+		t.Errorf("peak concurrency = %d, want at most 2", got)
+	}
+
+	// A later single-city search is served from the per-city cache entry
+	single := httptest.NewRequest(http.MethodGet, "/search?city=rome&checkin="+futureDate+"&nights=2&adults=2", nil)
+	single.RemoteAddr = "192.168.1.2:12345"
+	sw := httptest.NewRecorder()
+	h.SearchHandler(sw, single)
+
+	var singleResp handler.SearchResponse
+	if err := json.NewDecoder(sw.Body).Decode(&singleResp); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if singleResp.Stats.Cache != "hit" {
+		t.Errorf("single search cache = %q, want hit", singleResp.Stats.Cache)
+	}
+	if got := provider.calls.Load(); got != 4 {
+		t.Errorf("provider called %d times after cached search, want 4", got)
+	}
+}
+
+func TestLimits_ParseMultiCityParams(t *testing.T) {
+	limits := handler.DefaultLimits()
+	limits.MaxCities = 3
+
+	tests := []struct {
+		name       string
+		query      string
+		wantCities []string
+		wantParams []string
+	}{
+		{
+			name:       "cities trimmed and deduplicated",
+			query:      "cities=paris,%20rome,,paris&nights=2&adults=2",
+			wantCities: []string{"paris", "rome"},
+		},
+		{
+			name:       "missing cities",
+			query:      "cities=,%20&nights=2&adults=2",
+			wantParams: []string{"cities"},
+		},
+		{
+			name:       "too many cities",
+			query:      "cities=paris,rome,berlin,madrid&nights=2&adults=2",
+			wantParams: []string{"cities"},
+		},
+		{
+			name:       "cursor not supported",
+			query:      "cities=paris&nights=2&adults=2&cursor=abc",
+			wantParams: []string{"cursor"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/search/multi?checkin="+futureDate+"&"+tt.query, nil)
+			params, err := limits.ParseMultiCityParams(req)
+
+			if len(tt.wantParams) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if len(params.Cities) != len(tt.wantCities) {
+					t.Fatalf("cities = %v, want %v", params.Cities, tt.wantCities)
+				}
+				for i, city := range tt.wantCities {
+					if params.Cities[i] != city {
+						t.Errorf("cities = %v, want %v", params.Cities, tt.wantCities)
+						break
+					}
+				}
+				return
+			}
+
+			var verr *handler.ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected *ValidationError, got %v", err)
+			}
+			if len(verr.InvalidParams) != len(tt.wantParams) {
+				t.Fatalf("invalid params = %+v, want %v", verr.InvalidParams, tt.wantParams)
+			}
+			for i, name := range tt.wantParams {
+				if verr.InvalidParams[i].Name != name {
+					t.Errorf("invalid_params[%d] = %s, want %s", i, verr.InvalidParams[i].Name, name)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_MultiCitySearchHandler_Limits(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	metrics := obs.NewMetrics(logger)
+	searchCache := cache.NewCache(30 * time.Second)
+	defer searchCache.Close()
+	limiter := ratelimit.New(3, time.Minute)
+	defer limiter.Close()
+
+	provider := &countingProvider{delay: 20 * time.Millisecond}
+	aggregator := search.NewAggregator([]providers.Provider{provider}, 2*time.Second, metrics, logger)
+	h := handler.New(aggregator, searchCache, limiter, metrics, logger,
+		handler.WithBatch(handler.BatchConfig{MaxItems: 3, Concurrency: 3, Weight: 1}),
+		handler.WithFanOut(handler.FanOutConfig{Concurrency: 3, MaxConcurrent: 2}))
+
+	multi := func(cities, ip string) int {
+		req := httptest.NewRequest(http.MethodGet, "/search/multi?cities="+cities+"&checkin="+futureDate+"&nights=2&adults=2", nil)
+		req.RemoteAddr = ip + ":12345"
+		w := httptest.NewRecorder()
+		h.MultiCitySearchHandler(w, req)
+		return w.Code
+	}
+
+	// Concurrent requests share the fan-out slots
+	requests := []string{"paris,rome,berlin", "madrid,lisbon,vienna", "oslo,prague,dublin"}
+	codes := make([]int, len(requests))
+	var wg sync.WaitGroup
+	for i, cities := range requests {
+		wg.Go(func() {
+			codes[i] = multi(cities, fmt.Sprintf("192.168.1.%d", i+1))
+		})
+	}
+	wg.Wait()
+	for i, code := range codes {
+		if code != http.StatusOK {
+			t.Errorf("request %d status = %d, want %d", i, code, http.StatusOK)
+		}
+	}
+	if got := provider.peak.Load(); got > 2 {
+		t.Errorf("peak concurrency = %d, want at most 2 across requests", got)
+	}
+
+	// Each city costs a token
+	if code := multi("rome,berlin", "192.168.1.1"); code != http.StatusTooManyRequests {
+		t.Errorf("status = %d, want %d once the tokens are spent", code, http.StatusTooManyRequests)
+	}
+}
@@ -0,0 +1,468 @@
+package handler
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/providers"
+	"github.com/alex-user-go/hotels/internal/search/listing"
+	"github.com/alex-user-go/hotels/internal/search/types"
+)
+
+// dateLayout is the format of check-in dates.
+const dateLayout = "2006-01-02"
+
+// DefaultRadiusKm is the radius of a geo search when none is requested.
+const DefaultRadiusKm = 10
+
+// SearchParams holds validated search parameters.
+// A search is either by City or, when Geo is set, by coordinates.
+type SearchParams struct {
+	City      string
+	Checkin   string
+	Nights    int
+	Occupancy providers.Occupancy
+	Currency  string
+	List      listing.Options
+	Geo       *GeoArea
+}
+
+// GeoArea is the area of a geo search: RadiusKm around Location. Cities are
+// the cities covering it, nearest first.
+type GeoArea struct {
+	Location types.Location
+	RadiusKm float64
+	Cities   []string
+}
+
+// CurrencySupporter reports whether a display currency can be served.
+type CurrencySupporter interface {
+	Supports(currency string) bool
+}
+
+// CityLocator finds the cities covering an area.
+type CityLocator interface {
+	Covering(point types.Location, radiusKm float64) []string
+}
+
+// Limits bounds the accepted search parameters.
+type Limits struct {
+	// MaxNights is the longest accepted stay.
+	MaxNights int
+	// MaxAdults is the largest accepted number of adults across all rooms.
+	MaxAdults int
+	// MaxChildren is the largest accepted number of children across all rooms.
+	MaxChildren int
+	// MaxRooms is the largest accepted number of rooms.
+	MaxRooms int
+	// MaxHorizonDays is how many days ahead of today a check-in may be.
+	MaxHorizonDays int
+	// Location is the timezone used to decide what "today" is.
+	Location *time.Location
+	// Now returns the current time; defaults to time.Now.
+	Now func() time.Time
+	// Currencies restricts the accepted display currencies; nil accepts any.
+	Currencies CurrencySupporter
+	// MaxBodyBytes limits JSON request bodies; defaults to DefaultMaxBodyBytes.
+	MaxBodyBytes int64
+	// MaxFlexibleDates caps the check-in dates explored by a flexible-date search.
+	MaxFlexibleDates int
+	// MaxCities caps the cities of a multi-city or geo search.
+	MaxCities int
+	// MaxRadiusKm is the largest accepted geo search radius.
+	MaxRadiusKm float64
+	// Cities resolves geo searches to cities; nil disables geo search.
+	Cities CityLocator
+}
+
+// DefaultLimits returns the default parameter bounds.
+func DefaultLimits() Limits {
+	return Limits{
+		MaxNights:        30,
+		MaxAdults:        10,
+		MaxChildren:      6,
+		MaxRooms:         5,
+		MaxHorizonDays:   365,
+		Location:         time.UTC,
+		MaxBodyBytes:     DefaultMaxBodyBytes,
+		MaxFlexibleDates: 14,
+		MaxCities:        10,
+		MaxRadiusKm:      50,
+	}
+}
+
+// today returns the current date at midnight in the limits' timezone.
+func (l Limits) today() time.Time {
+	now := time.Now
+	if l.Now != nil {
+		now = l.Now
+	}
+	loc := l.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	y, m, d := now().In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// InvalidParam describes a single invalid request parameter.
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// ValidationError lists every invalid parameter of a request.
+type ValidationError struct {
+	InvalidParams []InvalidParam
+}
+
+// Error joins the reasons of all invalid parameters.
+func (e *ValidationError) Error() string {
+	reasons := make([]string, len(e.InvalidParams))
+	for i, p := range e.InvalidParams {
+		reasons[i] = p.Reason
+	}
+	return strings.Join(reasons, "; ")
+}
+
+// add records an invalid parameter.
+func (e *ValidationError) add(name, reason string) {
+	e.InvalidParams = append(e.InvalidParams, InvalidParam{Name: name, Reason: reason})
+}
+
+// has reports whether name has already been recorded as invalid.
+func (e *ValidationError) has(name string) bool {
+	for _, p := range e.InvalidParams {
+		if p.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseSearchParams parses and validates search parameters from the request
+// using the default limits.
+func ParseSearchParams(r *http.Request) (*SearchParams, error) {
+	return DefaultLimits().ParseSearchParams(r)
+}
+
+// ParseSearchParams parses and validates search parameters from the request.
+// All parameters are checked; the returned *ValidationError lists every
+// invalid one.
+func (l Limits) ParseSearchParams(r *http.Request) (*SearchParams, error) {
+	query := r.URL.Query()
+	errs := &ValidationError{}
+
+	params := &SearchParams{
+		City:      strings.TrimSpace(query.Get("city")),
+		Checkin:   strings.TrimSpace(query.Get("checkin")),
+		Nights:    parseRequiredInt(query, "nights", errs),
+		Occupancy: parseOccupancy(query, errs),
+		Currency:  strings.ToUpper(strings.TrimSpace(query.Get("currency"))),
+		List:      parseListOptions(query, errs),
+		Geo:       parseGeoArea(query, errs),
+	}
+
+	l.validate(params, errs)
+	if len(errs.InvalidParams) > 0 {
+		return nil, errs
+	}
+	return params, nil
+}
+
+// validate checks parsed parameters against the limits and fills defaults.
+// Parameters already recorded as invalid are not checked again.
+func (l Limits) validate(p *SearchParams, errs *ValidationError) {
+	// City - required, non-empty, unless searching by coordinates
+	if p.Geo != nil {
+		if p.City != "" {
+			errs.add("city", "city must not be combined with lat and lon")
+		}
+		l.validateGeoArea(p.Geo, errs)
+	} else if p.City == "" {
+		errs.add("city", "city is required")
+	}
+
+	// Checkin - required, YYYY-MM-DD, not in the past, within the booking horizon
+	l.validateCheckin("checkin", p.Checkin, errs)
+
+	l.validateStay(p, errs)
+}
+
+// validateCheckin checks a check-in date parameter and returns the parsed
+// date; ok is false if the date was recorded as invalid.
+func (l Limits) validateCheckin(name, value string, errs *ValidationError) (date time.Time, ok bool) {
+	if value == "" {
+		errs.add(name, name+" is required")
+		return date, false
+	}
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		errs.add(name, name+" must be in YYYY-MM-DD format")
+		return date, false
+	}
+
+	today := l.today()
+	if date.Before(today) {
+		errs.add(name, name+" must not be in the past")
+		return date, false
+	}
+	if l.MaxHorizonDays > 0 && date.After(today.AddDate(0, 0, l.MaxHorizonDays)) {
+		errs.add(name, fmt.Sprintf("%s must be within %d days from today", name, l.MaxHorizonDays))
+		return date, false
+	}
+	return date, true
+}
+
+// validateStay checks everything about a search except its city and dates:
+// nights, occupancy, currency and listing options.
+func (l Limits) validateStay(p *SearchParams, errs *ValidationError) {
+	// Nights - positive, bounded
+	if !errs.has("nights") {
+		if p.Nights <= 0 {
+			errs.add("nights", "nights must be a positive integer")
+		} else if l.MaxNights > 0 && p.Nights > l.MaxNights {
+			errs.add("nights", fmt.Sprintf("nights must be at most %d", l.MaxNights))
+		}
+	}
+
+	// Occupancy - at least one adult per room, bounded totals
+	if !errs.has("adults") && !errs.has("children") && !errs.has("occupancy") && !errs.has("rooms") {
+		l.validateOccupancy(p.Occupancy, errs)
+	}
+
+	// Currency - optional, ISO 4217 code
+	if p.Currency == "" {
+		p.Currency = DefaultCurrency
+	}
+	if !isCurrencyCode(p.Currency) {
+		errs.add("currency", "currency must be a 3-letter ISO 4217 code")
+	} else if l.Currencies != nil && !l.Currencies.Supports(p.Currency) {
+		errs.add("currency", "currency is not supported")
+	}
+
+	validateListOptions(p.List, errs)
+	if p.List.Sort == listing.SortDistance && p.Geo == nil {
+		errs.add("sort", "sort by distance requires lat and lon")
+	}
+}
+
+// validateGeoArea checks the coordinates and radius of a geo search and
+// resolves the cities covering it.
+func (l Limits) validateGeoArea(g *GeoArea, errs *ValidationError) {
+	if !errs.has("lat") && (g.Location.Latitude < -90 || g.Location.Latitude > 90) {
+		errs.add("lat", "lat must be between -90 and 90")
+	}
+	if !errs.has("lon") && (g.Location.Longitude < -180 || g.Location.Longitude > 180) {
+		errs.add("lon", "lon must be between -180 and 180")
+	}
+	if !errs.has("radius_km") {
+		if g.RadiusKm <= 0 {
+			errs.add("radius_km", "radius_km must be a positive number")
+		} else if l.MaxRadiusKm > 0 && g.RadiusKm > l.MaxRadiusKm {
+			errs.add("radius_km", fmt.Sprintf("radius_km must be at most %g", l.MaxRadiusKm))
+		}
+	}
+	if errs.has("lat") || errs.has("lon") || errs.has("radius_km") {
+		return
+	}
+
+	if l.Cities == nil {
+		errs.add("lat", "search by coordinates is not available")
+		return
+	}
+	g.Cities = l.Cities.Covering(g.Location, g.RadiusKm)
+	if len(g.Cities) == 0 {
+		errs.add("radius_km", "no known city within radius_km of lat and lon")
+		return
+	}
+	if l.MaxCities > 0 && len(g.Cities) > l.MaxCities {
+		g.Cities = g.Cities[:l.MaxCities]
+	}
+}
+
+// validateOccupancy checks an occupancy against the limits.
+func (l Limits) validateOccupancy(occ providers.Occupancy, errs *ValidationError) {
+	if err := occ.Validate(); err != nil {
+		errs.add("occupancy", "occupancy is invalid: "+err.Error())
+		return
+	}
+	if l.MaxRooms > 0 && occ.Rooms() > l.MaxRooms {
+		errs.add("occupancy", fmt.Sprintf("occupancy must have at most %d rooms", l.MaxRooms))
+	}
+	if l.MaxAdults > 0 && occ.Adults() > l.MaxAdults {
+		errs.add("adults", fmt.Sprintf("adults must be at most %d", l.MaxAdults))
+	}
+	if l.MaxChildren > 0 && occ.Children() > l.MaxChildren {
+		errs.add("children", fmt.Sprintf("children must be at most %d", l.MaxChildren))
+	}
+}
+
+// parseOccupancy parses the guests of a search. Either "occupancy" (one or
+// more rooms, see providers.Occupancy) or the single-room shorthand "adults"
+// with optional comma-separated "children" ages is accepted.
+func parseOccupancy(query url.Values, errs *ValidationError) providers.Occupancy {
+	if value := strings.TrimSpace(query.Get("occupancy")); value != "" {
+		if query.Has("adults") || query.Has("children") {
+			errs.add("occupancy", "occupancy must not be combined with adults or children")
+			return nil
+		}
+		occ, err := providers.ParseOccupancy(value)
+		if err != nil {
+			errs.add("occupancy", "occupancy is invalid: "+err.Error())
+			return nil
+		}
+		return occ
+	}
+
+	adults := parseRequiredInt(query, "adults", errs)
+	if !errs.has("adults") && adults <= 0 {
+		errs.add("adults", "adults must be a positive integer")
+	}
+	room := providers.Room{Adults: adults}
+
+	if value := strings.TrimSpace(query.Get("children")); value != "" {
+		ages, err := providers.ParseChildAges(value)
+		if err != nil {
+			errs.add("children", "children must be a comma-separated list of ages")
+			return nil
+		}
+		for _, age := range ages {
+			if age < 0 || age > providers.MaxChildAge {
+				errs.add("children", fmt.Sprintf("children ages must be between 0 and %d", providers.MaxChildAge))
+				return nil
+			}
+		}
+		room.ChildAges = ages
+	}
+
+	return providers.Occupancy{room}
+}
+
+// parseGeoArea parses the lat, lon and optional radius_km of a geo search.
+// Returns nil if none of them is set.
+func parseGeoArea(query url.Values, errs *ValidationError) *GeoArea {
+	if !query.Has("lat") && !query.Has("lon") && !query.Has("radius_km") {
+		return nil
+	}
+
+	area := &GeoArea{RadiusKm: DefaultRadiusKm}
+	area.Location.Latitude = parseRequiredFloat(query, "lat", errs)
+	area.Location.Longitude = parseRequiredFloat(query, "lon", errs)
+	if value := strings.TrimSpace(query.Get("radius_km")); value != "" {
+		radius, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(radius) || math.IsInf(radius, 0) {
+			errs.add("radius_km", "radius_km must be a positive number")
+		}
+		area.RadiusKm = radius
+	}
+	return area
+}
+
+// parseRequiredFloat parses a required decimal query parameter.
+// Returns 0 and records the error if it is missing or malformed.
+func parseRequiredFloat(query url.Values, name string, errs *ValidationError) float64 {
+	value := strings.TrimSpace(query.Get(name))
+	if value == "" {
+		errs.add(name, name+" is required")
+		return 0
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		errs.add(name, name+" must be a decimal number")
+		return 0
+	}
+	return f
+}
+
+// parseRequiredInt parses a required integer query parameter.
+// Returns 0 and records the error if it is missing or malformed.
+func parseRequiredInt(query url.Values, name string, errs *ValidationError) int {
+	value := strings.TrimSpace(query.Get(name))
+	if value == "" {
+		errs.add(name, name+" is required")
+		return 0
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		errs.add(name, name+" must be a positive integer")
+		return 0
+	}
+	return n
+}
+
+// parseListOptions parses optional sorting, filtering and pagination parameters.
+func parseListOptions(query url.Values, errs *ValidationError) listing.Options {
+	opts := listing.Options{
+		Sort:     strings.TrimSpace(query.Get("sort")),
+		Name:     strings.TrimSpace(query.Get("name")),
+		Provider: strings.TrimSpace(query.Get("provider")),
+		Cursor:   strings.TrimSpace(query.Get("cursor")),
+	}
+
+	var err error
+	if opts.MinPrice, err = parsePrice(query.Get("min_price")); err != nil {
+		errs.add("min_price", "min_price must be a non-negative number")
+	}
+	if opts.MaxPrice, err = parsePrice(query.Get("max_price")); err != nil {
+		errs.add("max_price", "max_price must be a non-negative number")
+	}
+
+	if limitStr := strings.TrimSpace(query.Get("limit")); limitStr != "" {
+		if opts.Limit, err = strconv.Atoi(limitStr); err != nil || opts.Limit <= 0 {
+			opts.Limit = -1 // Reported as out of range
+		}
+	}
+
+	return opts
+}
+
+// validateListOptions checks sorting, filtering and pagination options.
+func validateListOptions(opts listing.Options, errs *ValidationError) {
+	if opts.Sort != "" && !listing.ValidSort(opts.Sort) {
+		errs.add("sort", "sort must be one of price_asc, price_desc, name, providers, distance")
+	}
+	if opts.MinPrice < 0 && !errs.has("min_price") {
+		errs.add("min_price", "min_price must be a non-negative number")
+	}
+	if opts.MaxPrice < 0 && !errs.has("max_price") {
+		errs.add("max_price", "max_price must be a non-negative number")
+	}
+	if opts.MaxPrice > 0 && opts.MinPrice > opts.MaxPrice {
+		errs.add("min_price", "min_price must not exceed max_price")
+	}
+	if opts.Limit < 0 || opts.Limit > listing.MaxLimit {
+		errs.add("limit", fmt.Sprintf("limit must be an integer between 1 and %d", listing.MaxLimit))
+	}
+}
+
+// parsePrice parses an optional non-negative price; empty means not set.
+func parsePrice(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	price, err := strconv.ParseFloat(s, 64)
+	if err != nil || price < 0 || math.IsNaN(price) || math.IsInf(price, 0) {
+		return 0, fmt.Errorf("invalid price %q", s)
+	}
+	return price, nil
+}
+
+// isCurrencyCode reports whether s looks like an ISO 4217 currency code.
+func isCurrencyCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,187 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/handler"
+)
+
+// supportedCurrencies is a test CurrencySupporter.
+type supportedCurrencies map[string]bool
+
+func (s supportedCurrencies) Supports(currency string) bool {
+	return s[currency]
+}
+
+func TestLimits_ParseSearchParams(t *testing.T) {
+	// 23:30 UTC on 2026-03-10 is already 2026-03-11 in Tokyo
+	now := time.Date(2026, 3, 10, 23, 30, 0, 0, time.UTC)
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	limits := handler.Limits{
+		MaxNights:      14,
+		MaxAdults:      4,
+		MaxHorizonDays: 30,
+		Location:       time.UTC,
+		Now:            func() time.Time { return now },
+		Currencies:     supportedCurrencies{"EUR": true, "USD": true},
+	}
+
+	tests := []struct {
+		name       string
+		limits     func(handler.Limits) handler.Limits
+		query      string
+		wantParams []string
+	}{
+		{
+			name:  "valid today",
+			query: "city=paris&checkin=2026-03-10&nights=14&adults=4&currency=usd",
+		},
+		{
+			name:       "past checkin",
+			query:      "city=paris&checkin=2026-03-09&nights=2&adults=2",
+			wantParams: []string{"checkin"},
+		},
+		{
+			name: "past checkin in configured timezone",
+			limits: func(l handler.Limits) handler.Limits {
+				l.Location = tokyo
+				return l
+			},
+			query:      "city=paris&checkin=2026-03-10&nights=2&adults=2",
+			wantParams: []string{"checkin"},
+		},
+		{
+			name:       "beyond booking horizon",
+			query:      "city=paris&checkin=2026-04-10&nights=2&adults=2",
+			wantParams: []string{"checkin"},
+		},
+		{
+			name:       "too many nights and adults",
+			query:      "city=paris&checkin=2026-03-20&nights=10000&adults=500",
+			wantParams: []string{"nights", "adults"},
+		},
+		{
+			name:  "multi-room occupancy",
+			query: "city=paris&checkin=2026-03-20&nights=2&occupancy=2-5,8|1&currency=USD",
+		},
+		{
+			name:  "adults with children",
+			query: "city=paris&checkin=2026-03-20&nights=2&adults=2&children=3,16&currency=USD",
+		},
+		{
+			name:       "occupancy combined with adults",
+			query:      "city=paris&checkin=2026-03-20&nights=2&adults=2&occupancy=2",
+			wantParams: []string{"occupancy"},
+		},
+		{
+			name:       "malformed occupancy",
+			query:      "city=paris&checkin=2026-03-20&nights=2&occupancy=2|0",
+			wantParams: []string{"occupancy"},
+		},
+		{
+			name:       "child too old",
+			query:      "city=paris&checkin=2026-03-20&nights=2&adults=2&children=18",
+			wantParams: []string{"children"},
+		},
+		{
+			name:       "too many adults across rooms",
+			query:      "city=paris&checkin=2026-03-20&nights=2&occupancy=3|3",
+			wantParams: []string{"adults"},
+		},
+		{
+			name:       "unsupported currency",
+			query:      "city=paris&checkin=2026-03-20&nights=2&adults=2&currency=JPY",
+			wantParams: []string{"currency"},
+		},
+		{
+			name:       "all fields reported at once",
+			query:      "checkin=yesterday&nights=x&currency=EURO&sort=stars&limit=0",
+			wantParams: []string{"adults", "city", "checkin", "nights", "currency", "sort", "limit"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := limits
+			if tt.limits != nil {
+				l = tt.limits(l)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/search?"+tt.query, nil)
+			params, err := l.ParseSearchParams(req)
+
+			if len(tt.wantParams) == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if params.Currency != "USD" {
+					t.Errorf("currency = %q, want USD", params.Currency)
+				}
+				return
+			}
+
+			var verr *handler.ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected *ValidationError, got %v", err)
+			}
+			got := make(map[string]bool)
+			for _, p := range verr.InvalidParams {
+				got[p.Name] = true
+			}
+			for _, name := range tt.wantParams {
+				if !got[name] {
+					t.Errorf("expected %s to be reported invalid, got %+v", name, verr.InvalidParams)
+				}
+			}
+			if len(got) != len(tt.wantParams) {
+				t.Errorf("invalid params = %+v, want %v", verr.InvalidParams, tt.wantParams)
+			}
+		})
+	}
+}
+
+func TestHandler_SearchHandler_ProblemDetails(t *testing.T) {
+	h, cleanup := newTestHandler(&mockProvider{})
+	defer cleanup()
+
+	req := httptest.NewRequest(http.MethodGet, "/search?city=paris&checkin=2000-01-01&nights=0&adults=2", nil)
+	req.RemoteAddr = "192.168.1.1:12345"
+	w := httptest.NewRecorder()
+
+	h.SearchHandler(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("Content-Type = %q, want application/problem+json", ct)
+	}
+
+	var problem handler.Problem
+	if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+		t.Fatalf("failed to decode problem: %v", err)
+	}
+	want := []handler.InvalidParam{
+		{Name: "checkin", Reason: "checkin must not be in the past"},
+		{Name: "nights", Reason: "nights must be a positive integer"},
+	}
+	if len(problem.InvalidParams) != len(want) {
+		t.Fatalf("invalid_params = %+v, want %+v", problem.InvalidParams, want)
+	}
+	for i := range want {
+		if problem.InvalidParams[i] != want[i] {
+			t.Errorf("invalid_params[%d] = %+v, want %+v", i, problem.InvalidParams[i], want[i])
+		}
+	}
+	if problem.Type == "" || problem.Title == "" {
+		t.Errorf("expected type and title to be set, got %+v", problem)
+	}
+}
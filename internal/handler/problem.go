@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+// problemTypeInvalidParams identifies validation problems.
+const problemTypeInvalidParams = "/problems/invalid-parameters"
+
+// Problem is an RFC 9457 problem details response.
+type Problem struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	InvalidParams []InvalidParam `json:"invalid_params,omitempty"`
+}
+
+// errorProblem builds a generic problem for status with message as detail.
+func errorProblem(status int, message string) Problem {
+	return Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: message,
+	}
+}
+
+// validationProblem builds a 400 problem listing every invalid parameter.
+func validationProblem(err *ValidationError) Problem {
+	return Problem{
+		Type:          problemTypeInvalidParams,
+		Title:         "Your request parameters didn't validate.",
+		Status:        http.StatusBadRequest,
+		Detail:        err.Error(),
+		InvalidParams: err.InvalidParams,
+	}
+}
+
+// requestProblem builds a problem for a request parsing error.
+func requestProblem(err error) Problem {
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		return validationProblem(verr)
+	}
+	var rerr *RequestError
+	if errors.As(err, &rerr) {
+		return errorProblem(rerr.Status, rerr.Message)
+	}
+	return errorProblem(http.StatusBadRequest, err.Error())
+}
+
+// writeValidationError writes a 400 problem response listing every invalid parameter.
+func writeValidationError(w http.ResponseWriter, err *ValidationError) {
+	writeProblem(w, validationProblem(err))
+}
+
+// writeError writes an application/problem+json error response.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeProblem(w, errorProblem(status, message))
+}
+
+// RequestError is a request that could not be read, such as a malformed or
+// oversized body. Status is the HTTP status to respond with.
+type RequestError struct {
+	Status  int
+	Message string
+}
+
+// Error returns the message.
+func (e *RequestError) Error() string {
+	return e.Message
+}
+
+// writeRequestError writes a problem response for a request parsing error.
+func writeRequestError(w http.ResponseWriter, err error) {
+	writeProblem(w, requestProblem(err))
+}
+
+func writeProblem(w http.ResponseWriter, p Problem) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
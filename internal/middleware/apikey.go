@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+const apiKeyKey contextKey = "api_key"
+
+// APIKey extracts the caller's API key from context.
+func APIKey(ctx context.Context) string {
+	if key, ok := ctx.Value(apiKeyKey).(string); ok {
+		return key
+	}
+	return ""
+}
+
+// APIKeys adds the API key sent in the X-API-Key header to the request context.
+func APIKeys(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if key := strings.TrimSpace(r.Header.Get("X-API-Key")); key != "" {
+			r = r.WithContext(context.WithValue(r.Context(), apiKeyKey, key))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
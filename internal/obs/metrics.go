@@ -4,7 +4,11 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
+	"sort"
+	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // Metrics tracks application metrics using atomic counters.
@@ -13,12 +17,47 @@ type Metrics struct {
 	cacheHits      atomic.Int64
 	providerErrors atomic.Int64
 	logger         *slog.Logger
+
+	mu                 sync.Mutex
+	priceAnomalies     map[labelPair]int64
+	normalizationDrops map[labelPair]int64
+	budgetSkips        map[string]int64
+	budgets            []budgetGauge
+	bulkheads          []bulkheadGauge
+	timeouts           []timeoutGauge
+}
+
+// budgetGauge reads the remaining outbound budget of a provider over a
+// window.
+type budgetGauge struct {
+	provider, window string
+	read             func() float64
+}
+
+// labelPair is the label values of a counter with two labels.
+type labelPair struct {
+	first, second string
+}
+
+// bulkheadGauge reads the load of a provider's bulkhead.
+type bulkheadGauge struct {
+	provider string
+	read     func() BulkheadStats
+}
+
+// timeoutGauge reads the current timeout of a provider's searches.
+type timeoutGauge struct {
+	provider string
+	read     func() time.Duration
 }
 
 // NewMetrics creates a new Metrics instance.
 func NewMetrics(logger *slog.Logger) *Metrics {
 	return &Metrics{
-		logger: logger,
+		logger:             logger,
+		priceAnomalies:     make(map[labelPair]int64),
+		normalizationDrops: make(map[labelPair]int64),
+		budgetSkips:        make(map[string]int64),
 	}
 }
 
@@ -37,20 +76,179 @@ func (m *Metrics) IncProviderErrors() {
 	m.providerErrors.Add(1)
 }
 
+// IncPriceAnomalies counts an offer with an anomalous price, by provider and
+// the action taken ("flagged" or "dropped").
+func (m *Metrics) IncPriceAnomalies(provider, action string) {
+	m.mu.Lock()
+	m.priceAnomalies[labelPair{first: provider, second: action}]++
+	m.mu.Unlock()
+}
+
+// IncNormalizationDrops counts an offer dropped by a normalization step, by
+// provider and step.
+func (m *Metrics) IncNormalizationDrops(provider, step string) {
+	m.mu.Lock()
+	m.normalizationDrops[labelPair{first: provider, second: step}]++
+	m.mu.Unlock()
+}
+
+// IncBudgetSkips counts a search not sent to a provider because its
+// outbound budget was used up.
+func (m *Metrics) IncBudgetSkips(provider string) {
+	m.mu.Lock()
+	m.budgetSkips[provider]++
+	m.mu.Unlock()
+}
+
+// RegisterBudget exports the remaining outbound budget of a provider over a
+// window ("second" or "day"), as returned by read.
+func (m *Metrics) RegisterBudget(provider, window string, read func() float64) {
+	m.mu.Lock()
+	m.budgets = append(m.budgets, budgetGauge{provider: provider, window: window, read: read})
+	m.mu.Unlock()
+}
+
+// RegisterBulkhead exports the load of a provider's bulkhead, as returned by
+// read.
+func (m *Metrics) RegisterBulkhead(provider string, read func() BulkheadStats) {
+	m.mu.Lock()
+	m.bulkheads = append(m.bulkheads, bulkheadGauge{provider: provider, read: read})
+	m.mu.Unlock()
+}
+
+// RegisterTimeout exports the current timeout of a provider's searches, as
+// returned by read.
+func (m *Metrics) RegisterTimeout(provider string, read func() time.Duration) {
+	m.mu.Lock()
+	m.timeouts = append(m.timeouts, timeoutGauge{provider: provider, read: read})
+	m.mu.Unlock()
+}
+
 // Snapshot returns current metric values.
 func (m *Metrics) Snapshot() MetricsSnapshot {
+	m.mu.Lock()
+	anomalies := make([]PriceAnomalyCount, 0, len(m.priceAnomalies))
+	for _, k := range sortedLabels(m.priceAnomalies) {
+		anomalies = append(anomalies, PriceAnomalyCount{Provider: k.first, Action: k.second, Count: m.priceAnomalies[k]})
+	}
+	drops := make([]NormalizationDropCount, 0, len(m.normalizationDrops))
+	for _, k := range sortedLabels(m.normalizationDrops) {
+		drops = append(drops, NormalizationDropCount{Provider: k.first, Step: k.second, Count: m.normalizationDrops[k]})
+	}
+	skips := make([]BudgetSkipCount, 0, len(m.budgetSkips))
+	for provider, count := range m.budgetSkips {
+		skips = append(skips, BudgetSkipCount{Provider: provider, Count: count})
+	}
+	gauges := slices.Clone(m.budgets)
+	bulkheadGauges := slices.Clone(m.bulkheads)
+	timeoutGauges := slices.Clone(m.timeouts)
+	m.mu.Unlock()
+
+	sort.Slice(skips, func(i, j int) bool { return skips[i].Provider < skips[j].Provider })
+	budgets := make([]BudgetRemaining, len(gauges))
+	for i, g := range gauges {
+		budgets[i] = BudgetRemaining{Provider: g.provider, Window: g.window, Remaining: g.read()}
+	}
+	bulkheads := make([]ProviderBulkhead, len(bulkheadGauges))
+	for i, g := range bulkheadGauges {
+		bulkheads[i] = ProviderBulkhead{Provider: g.provider, BulkheadStats: g.read()}
+	}
+	timeouts := make([]ProviderTimeout, len(timeoutGauges))
+	for i, g := range timeoutGauges {
+		timeouts[i] = ProviderTimeout{Provider: g.provider, Timeout: g.read()}
+	}
+
 	return MetricsSnapshot{
-		Requests:       m.requests.Load(),
-		CacheHits:      m.cacheHits.Load(),
-		ProviderErrors: m.providerErrors.Load(),
+		Requests:           m.requests.Load(),
+		CacheHits:          m.cacheHits.Load(),
+		ProviderErrors:     m.providerErrors.Load(),
+		PriceAnomalies:     anomalies,
+		NormalizationDrops: drops,
+		BudgetSkips:        skips,
+		BudgetRemaining:    budgets,
+		Bulkheads:          bulkheads,
+		Timeouts:           timeouts,
+	}
+}
+
+// sortedLabels returns the label values of a counter in order.
+func sortedLabels(counts map[labelPair]int64) []labelPair {
+	keys := make([]labelPair, 0, len(counts))
+	for k := range counts {
+		keys = append(keys, k)
 	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].first != keys[j].first {
+			return keys[i].first < keys[j].first
+		}
+		return keys[i].second < keys[j].second
+	})
+	return keys
 }
 
 // MetricsSnapshot represents a point-in-time snapshot of metrics.
 type MetricsSnapshot struct {
-	Requests       int64
-	CacheHits      int64
-	ProviderErrors int64
+	Requests           int64
+	CacheHits          int64
+	ProviderErrors     int64
+	PriceAnomalies     []PriceAnomalyCount
+	NormalizationDrops []NormalizationDropCount
+	BudgetSkips        []BudgetSkipCount
+	BudgetRemaining    []BudgetRemaining
+	Bulkheads          []ProviderBulkhead
+	Timeouts           []ProviderTimeout
+}
+
+// PriceAnomalyCount is the number of anomalous offers from a provider that
+// received an action.
+type PriceAnomalyCount struct {
+	Provider string
+	Action   string
+	Count    int64
+}
+
+// NormalizationDropCount is the number of offers from a provider dropped by
+// a normalization step.
+type NormalizationDropCount struct {
+	Provider string
+	Step     string
+	Count    int64
+}
+
+// BudgetSkipCount is the number of searches not sent to a provider because
+// its outbound budget was used up.
+type BudgetSkipCount struct {
+	Provider string
+	Count    int64
+}
+
+// BudgetRemaining is the outbound budget a provider has left over a window.
+type BudgetRemaining struct {
+	Provider  string
+	Window    string
+	Remaining float64
+}
+
+// BulkheadStats is the load of a provider's bulkhead: the searches in
+// flight and waiting for a slot, and the total searches that had to wait
+// or were rejected.
+type BulkheadStats struct {
+	InFlight int64
+	Waiting  int64
+	Queued   int64
+	Rejected int64
+}
+
+// ProviderBulkhead is the load of a provider's bulkhead.
+type ProviderBulkhead struct {
+	Provider string
+	BulkheadStats
+}
+
+// ProviderTimeout is the current timeout of a provider's searches.
+type ProviderTimeout struct {
+	Provider string
+	Timeout  time.Duration
 }
 
 // HealthHandler returns a handler for /healthz requests.
@@ -110,5 +308,140 @@ func (m *Metrics) MetricsHandler() http.HandlerFunc {
 			m.logger.Error("failed to write metrics", "error", err)
 			return
 		}
+
+		if _, err := fmt.Fprintf(w, "# HELP price_anomalies_total Total number of offers with anomalous prices\n"); err != nil {
+			m.logger.Error("failed to write metrics", "error", err)
+			return
+		}
+		if _, err := fmt.Fprintf(w, "# TYPE price_anomalies_total counter\n"); err != nil {
+			m.logger.Error("failed to write metrics", "error", err)
+			return
+		}
+		for _, c := range snapshot.PriceAnomalies {
+			if _, err := fmt.Fprintf(w, "price_anomalies_total{provider=%q,action=%q} %d\n", c.Provider, c.Action, c.Count); err != nil {
+				m.logger.Error("failed to write metrics", "error", err)
+				return
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "# HELP normalization_dropped_total Total number of offers dropped by normalization steps\n"); err != nil {
+			m.logger.Error("failed to write metrics", "error", err)
+			return
+		}
+		if _, err := fmt.Fprintf(w, "# TYPE normalization_dropped_total counter\n"); err != nil {
+			m.logger.Error("failed to write metrics", "error", err)
+			return
+		}
+		for _, c := range snapshot.NormalizationDrops {
+			if _, err := fmt.Fprintf(w, "normalization_dropped_total{provider=%q,step=%q} %d\n", c.Provider, c.Step, c.Count); err != nil {
+				m.logger.Error("failed to write metrics", "error", err)
+				return
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "# HELP provider_budget_skips_total Total number of searches not sent to a provider with an exhausted budget\n"); err != nil {
+			m.logger.Error("failed to write metrics", "error", err)
+			return
+		}
+		if _, err := fmt.Fprintf(w, "# TYPE provider_budget_skips_total counter\n"); err != nil {
+			m.logger.Error("failed to write metrics", "error", err)
+			return
+		}
+		for _, c := range snapshot.BudgetSkips {
+			if _, err := fmt.Fprintf(w, "provider_budget_skips_total{provider=%q} %d\n", c.Provider, c.Count); err != nil {
+				m.logger.Error("failed to write metrics", "error", err)
+				return
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "# HELP provider_budget_remaining Outbound requests a provider's budget has left\n"); err != nil {
+			m.logger.Error("failed to write metrics", "error", err)
+			return
+		}
+		if _, err := fmt.Fprintf(w, "# TYPE provider_budget_remaining gauge\n"); err != nil {
+			m.logger.Error("failed to write metrics", "error", err)
+			return
+		}
+		for _, b := range snapshot.BudgetRemaining {
+			if _, err := fmt.Fprintf(w, "provider_budget_remaining{provider=%q,window=%q} %g\n", b.Provider, b.Window, b.Remaining); err != nil {
+				m.logger.Error("failed to write metrics", "error", err)
+				return
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "# HELP provider_inflight Searches in flight to a provider\n"); err != nil {
+			m.logger.Error("failed to write metrics", "error", err)
+			return
+		}
+		if _, err := fmt.Fprintf(w, "# TYPE provider_inflight gauge\n"); err != nil {
+			m.logger.Error("failed to write metrics", "error", err)
+			return
+		}
+		for _, b := range snapshot.Bulkheads {
+			if _, err := fmt.Fprintf(w, "provider_inflight{provider=%q} %d\n", b.Provider, b.InFlight); err != nil {
+				m.logger.Error("failed to write metrics", "error", err)
+				return
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "# HELP provider_waiting Searches waiting for a free provider slot\n"); err != nil {
+			m.logger.Error("failed to write metrics", "error", err)
+			return
+		}
+		if _, err := fmt.Fprintf(w, "# TYPE provider_waiting gauge\n"); err != nil {
+			m.logger.Error("failed to write metrics", "error", err)
+			return
+		}
+		for _, b := range snapshot.Bulkheads {
+			if _, err := fmt.Fprintf(w, "provider_waiting{provider=%q} %d\n", b.Provider, b.Waiting); err != nil {
+				m.logger.Error("failed to write metrics", "error", err)
+				return
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "# HELP provider_bulkhead_queued_total Total number of searches that waited for a free provider slot\n"); err != nil {
+			m.logger.Error("failed to write metrics", "error", err)
+			return
+		}
+		if _, err := fmt.Fprintf(w, "# TYPE provider_bulkhead_queued_total counter\n"); err != nil {
+			m.logger.Error("failed to write metrics", "error", err)
+			return
+		}
+		for _, b := range snapshot.Bulkheads {
+			if _, err := fmt.Fprintf(w, "provider_bulkhead_queued_total{provider=%q} %d\n", b.Provider, b.Queued); err != nil {
+				m.logger.Error("failed to write metrics", "error", err)
+				return
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "# HELP provider_bulkhead_rejected_total Total number of searches rejected by a full provider bulkhead\n"); err != nil {
+			m.logger.Error("failed to write metrics", "error", err)
+			return
+		}
+		if _, err := fmt.Fprintf(w, "# TYPE provider_bulkhead_rejected_total counter\n"); err != nil {
+			m.logger.Error("failed to write metrics", "error", err)
+			return
+		}
+		for _, b := range snapshot.Bulkheads {
+			if _, err := fmt.Fprintf(w, "provider_bulkhead_rejected_total{provider=%q} %d\n", b.Provider, b.Rejected); err != nil {
+				m.logger.Error("failed to write metrics", "error", err)
+				return
+			}
+		}
+
+		if _, err := fmt.Fprintf(w, "# HELP provider_timeout_seconds Current timeout of a provider's searches\n"); err != nil {
+			m.logger.Error("failed to write metrics", "error", err)
+			return
+		}
+		if _, err := fmt.Fprintf(w, "# TYPE provider_timeout_seconds gauge\n"); err != nil {
+			m.logger.Error("failed to write metrics", "error", err)
+			return
+		}
+		for _, t := range snapshot.Timeouts {
+			if _, err := fmt.Fprintf(w, "provider_timeout_seconds{provider=%q} %g\n", t.Provider, t.Timeout.Seconds()); err != nil {
+				m.logger.Error("failed to write metrics", "error", err)
+				return
+			}
+		}
 	}
 }
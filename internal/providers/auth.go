@@ -0,0 +1,324 @@
+package providers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Authenticator adds a provider's credentials to outgoing requests.
+type Authenticator interface {
+	// Authenticate adds the credentials to req.
+	Authenticate(req *http.Request) error
+}
+
+// APIKeyAuth sends a static API key in a header or a query parameter.
+type APIKeyAuth struct {
+	name    string
+	key     string
+	inQuery bool
+}
+
+// NewAPIKeyAuth creates an APIKeyAuth sending key in the header name, or in
+// the query parameter name if inQuery is set.
+func NewAPIKeyAuth(name, key string, inQuery bool) *APIKeyAuth {
+	return &APIKeyAuth{name: name, key: key, inQuery: inQuery}
+}
+
+// Authenticate adds the API key to req.
+func (a *APIKeyAuth) Authenticate(req *http.Request) error {
+	if !a.inQuery {
+		req.Header.Set(a.name, a.key)
+		return nil
+	}
+	q := req.URL.Query()
+	q.Set(a.name, a.key)
+	req.URL.RawQuery = q.Encode()
+	return nil
+}
+
+// Headers of HMAC-signed requests.
+const (
+	HeaderAuthKey       = "X-Auth-Key"
+	HeaderAuthTimestamp = "X-Auth-Timestamp"
+	HeaderAuthSignature = "X-Auth-Signature"
+)
+
+// HMACAuth signs requests with a shared secret. Each request carries the
+// key ID, the Unix time it was signed at and an HMAC-SHA256 signature of
+// both with the method and URI, so that providers can reject forged and
+// replayed requests.
+type HMACAuth struct {
+	keyID  string
+	secret []byte
+}
+
+// NewHMACAuth creates an HMACAuth signing with the secret identified by
+// keyID.
+func NewHMACAuth(keyID, secret string) *HMACAuth {
+	return &HMACAuth{keyID: keyID, secret: []byte(secret)}
+}
+
+// Authenticate signs req.
+func (a *HMACAuth) Authenticate(req *http.Request) error {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(HeaderAuthKey, a.keyID)
+	req.Header.Set(HeaderAuthTimestamp, timestamp)
+	req.Header.Set(HeaderAuthSignature, HMACSignature(a.secret, req.Method, req.URL.RequestURI(), a.keyID, timestamp))
+	return nil
+}
+
+// HMACSignature returns the hex-encoded HMAC-SHA256 signature of a request:
+// its method, request URI, key ID and timestamp, one per line.
+func HMACSignature(secret []byte, method, requestURI, keyID, timestamp string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(strings.Join([]string{method, requestURI, keyID, timestamp}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// tokenExpiryMargin is how long before it expires an OAuth2 token is
+// refreshed, so that it doesn't expire in flight.
+const tokenExpiryMargin = 30 * time.Second
+
+// OAuth2Auth sends bearer tokens obtained with the OAuth2 client credentials
+// grant. A token is reused until shortly before it expires, or until the
+// provider rejects it; tokens without an expiry are reused until then.
+type OAuth2Auth struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	scopes       []string
+	httpClient   *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+// NewOAuth2Auth creates an OAuth2Auth requesting tokens for scopes from the
+// token endpoint tokenURL. Token requests time out after timeout.
+func NewOAuth2Auth(tokenURL, clientID, clientSecret string, scopes []string, timeout time.Duration) *OAuth2Auth {
+	return &OAuth2Auth{
+		tokenURL:     tokenURL,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		scopes:       scopes,
+		httpClient:   &http.Client{Timeout: timeout},
+	}
+}
+
+// setTransport sends token requests through transport, so that they reach
+// the token endpoint the way the provider's own requests reach the provider.
+func (a *OAuth2Auth) setTransport(transport http.RoundTripper) {
+	a.httpClient.Transport = transport
+}
+
+// Authenticate adds a bearer token to req, requesting a new one if needed.
+func (a *OAuth2Auth) Authenticate(req *http.Request) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.token == "" || (!a.expires.IsZero() && time.Now().After(a.expires.Add(-tokenExpiryMargin))) {
+		if err := a.refresh(req.Context()); err != nil {
+			return err
+		}
+	}
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return nil
+}
+
+// Invalidate drops the cached token, so that the next request gets a new
+// one.
+func (a *OAuth2Auth) Invalidate() {
+	a.mu.Lock()
+	a.token = ""
+	a.mu.Unlock()
+}
+
+// refresh requests a new token. The client credentials are sent with basic
+// authentication.
+func (a *OAuth2Auth) refresh(ctx context.Context) error {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(a.scopes) > 0 {
+		form.Set("scope", strings.Join(a.scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(a.clientID), url.QueryEscape(a.clientSecret))
+
+	resp, err := a.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("token request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close() // Explicitly ignore close error
+	}()
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int    `json:"expires_in"`
+		Error       string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil && resp.StatusCode == http.StatusOK {
+		return fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+		if token.Error != "" {
+			err = fmt.Errorf("%w: %s", err, token.Error)
+		}
+		if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusUnauthorized {
+			return fmt.Errorf("%w: %w", ErrProviderRejected, err)
+		}
+		return fmt.Errorf("%w: %w", ErrProviderUnavailable, err)
+	}
+	if token.AccessToken == "" || (token.TokenType != "" && !strings.EqualFold(token.TokenType, "bearer")) {
+		return fmt.Errorf("invalid token response: token type %q", token.TokenType)
+	}
+
+	a.token = token.AccessToken
+	a.expires = time.Time{}
+	if token.ExpiresIn > 0 {
+		a.expires = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return nil
+}
+
+// Authentication schemes of provider secrets files.
+const (
+	AuthAPIKey = "api_key"
+	AuthHMAC   = "hmac"
+	AuthOAuth2 = "oauth2"
+	AuthMTLS   = "mtls"
+)
+
+// AuthConfig is a provider's secrets file. Type selects the scheme, which
+// determines the fields used:
+//
+//   - api_key: Key, sent in the Header (default X-API-Key) or the Query
+//     parameter
+//   - hmac: KeyID and Secret
+//   - oauth2: TokenURL, ClientID, ClientSecret and optional Scopes
+//   - mtls: CertFile and KeyFile, the client certificate, and an optional
+//     CAFile to verify the provider with instead of the system roots;
+//     relative paths are relative to the secrets file
+type AuthConfig struct {
+	Type         string   `json:"type"`
+	Header       string   `json:"header,omitempty"`
+	Query        string   `json:"query,omitempty"`
+	Key          string   `json:"key,omitempty"`
+	KeyID        string   `json:"key_id,omitempty"`
+	Secret       string   `json:"secret,omitempty"`
+	TokenURL     string   `json:"token_url,omitempty"`
+	ClientID     string   `json:"client_id,omitempty"`
+	ClientSecret string   `json:"client_secret,omitempty"`
+	Scopes       []string `json:"scopes,omitempty"`
+	CertFile     string   `json:"cert_file,omitempty"`
+	KeyFile      string   `json:"key_file,omitempty"`
+	CAFile       string   `json:"ca_file,omitempty"`
+}
+
+// LoadAuth reads a provider secrets file and returns the option that
+// authenticates the provider's requests. Token requests of the oauth2
+// scheme time out after timeout, and go through the provider's transport.
+func LoadAuth(path string, timeout time.Duration) (HTTPOption, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+	var cfg AuthConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse secrets file: %w", err)
+	}
+
+	missing := func(fields ...string) error {
+		return fmt.Errorf("%s authentication requires %s", cfg.Type, strings.Join(fields, ", "))
+	}
+
+	switch cfg.Type {
+	case AuthAPIKey:
+		if cfg.Key == "" {
+			return nil, missing("key")
+		}
+		if cfg.Header != "" && cfg.Query != "" {
+			return nil, errors.New("api_key authentication takes either header or query")
+		}
+		if cfg.Query != "" {
+			return WithAuthenticator(NewAPIKeyAuth(cfg.Query, cfg.Key, true)), nil
+		}
+		header := cfg.Header
+		if header == "" {
+			header = "X-API-Key"
+		}
+		return WithAuthenticator(NewAPIKeyAuth(header, cfg.Key, false)), nil
+
+	case AuthHMAC:
+		if cfg.KeyID == "" || cfg.Secret == "" {
+			return nil, missing("key_id", "secret")
+		}
+		return WithAuthenticator(NewHMACAuth(cfg.KeyID, cfg.Secret)), nil
+
+	case AuthOAuth2:
+		if cfg.TokenURL == "" || cfg.ClientID == "" || cfg.ClientSecret == "" {
+			return nil, missing("token_url", "client_id", "client_secret")
+		}
+		return WithAuthenticator(NewOAuth2Auth(cfg.TokenURL, cfg.ClientID, cfg.ClientSecret, cfg.Scopes, timeout)), nil
+
+	case AuthMTLS:
+		if cfg.CertFile == "" || cfg.KeyFile == "" {
+			return nil, missing("cert_file", "key_file")
+		}
+		dir := filepath.Dir(path)
+		resolve := func(file string) string {
+			if file == "" || filepath.IsAbs(file) {
+				return file
+			}
+			return filepath.Join(dir, file)
+		}
+		tlsConfig, err := LoadClientTLS(resolve(cfg.CertFile), resolve(cfg.KeyFile), resolve(cfg.CAFile))
+		if err != nil {
+			return nil, err
+		}
+		return WithTLSConfig(tlsConfig), nil
+
+	default:
+		return nil, fmt.Errorf("unknown authentication type %q, expected api_key, hmac, oauth2 or mtls", cfg.Type)
+	}
+}
+
+// LoadClientTLS returns a TLS configuration presenting the client
+// certificate in certFile and keyFile. If caFile is set, the server is
+// verified against its certificates instead of the system roots.
+func LoadClientTLS(certFile, keyFile, caFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load client certificate: %w", err)
+	}
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if caFile != "" {
+		if cfg.RootCAs, err = LoadCAFile(caFile); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
@@ -0,0 +1,311 @@
+package providers_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/providers"
+)
+
+// writeAuth writes a secrets file and returns its path.
+func writeAuth(t *testing.T, dir string, cfg providers.AuthConfig) string {
+	t.Helper()
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "secrets.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// searchWithAuth searches a provider at url authenticated with the secrets
+// in cfg.
+func searchWithAuth(t *testing.T, url string, cfg providers.AuthConfig) error {
+	t.Helper()
+	auth, err := providers.LoadAuth(writeAuth(t, t.TempDir(), cfg), time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p := providers.NewHTTPProvider("test", url, time.Second, auth)
+	_, err = p.Search(context.Background(), "paris", "2026-12-01", 2, providers.SingleRoom(2))
+	return err
+}
+
+// authServer serves an empty result to requests accepted by check.
+func authServer(t *testing.T, check func(r *http.Request) bool) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !check(r) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestAPIKeyAuth(t *testing.T) {
+	srv := authServer(t, func(r *http.Request) bool {
+		return r.Header.Get("X-Partner-Key") == "secret" || r.URL.Query().Get("api_key") == "secret"
+	})
+
+	tests := []struct {
+		name    string
+		cfg     providers.AuthConfig
+		wantErr bool
+	}{
+		{name: "header", cfg: providers.AuthConfig{Type: providers.AuthAPIKey, Header: "X-Partner-Key", Key: "secret"}},
+		{name: "query", cfg: providers.AuthConfig{Type: providers.AuthAPIKey, Query: "api_key", Key: "secret"}},
+		{name: "wrong key", cfg: providers.AuthConfig{Type: providers.AuthAPIKey, Header: "X-Partner-Key", Key: "guess"}, wantErr: true},
+		{name: "default header", cfg: providers.AuthConfig{Type: providers.AuthAPIKey, Key: "secret"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := searchWithAuth(t, srv.URL, tt.cfg)
+			if tt.wantErr != (err != nil) {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, providers.ErrProviderRejected) {
+				t.Errorf("error = %v, want ErrProviderRejected", err)
+			}
+		})
+	}
+}
+
+func TestHMACAuth(t *testing.T) {
+	var signed atomic.Bool
+	srv := authServer(t, func(r *http.Request) bool {
+		keyID := r.Header.Get(providers.HeaderAuthKey)
+		timestamp := r.Header.Get(providers.HeaderAuthTimestamp)
+		want := providers.HMACSignature([]byte("shared"), r.Method, r.URL.RequestURI(), keyID, timestamp)
+		ok := keyID == "partner-1" && timestamp != "" && r.Header.Get(providers.HeaderAuthSignature) == want
+		signed.Store(ok)
+		return ok
+	})
+
+	if err := searchWithAuth(t, srv.URL, providers.AuthConfig{Type: providers.AuthHMAC, KeyID: "partner-1", Secret: "shared"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !signed.Load() {
+		t.Error("request was not signed")
+	}
+
+	err := searchWithAuth(t, srv.URL, providers.AuthConfig{Type: providers.AuthHMAC, KeyID: "partner-1", Secret: "wrong"})
+	if !errors.Is(err, providers.ErrProviderRejected) {
+		t.Errorf("error = %v, want ErrProviderRejected", err)
+	}
+}
+
+func TestOAuth2Auth(t *testing.T) {
+	var issued, revoked atomic.Int32
+	expiresIn := 3600
+	tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		if !ok || id != "client" || secret != "s3cret" || r.FormValue("grant_type") != "client_credentials" || r.FormValue("scope") != "search rates" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error": "invalid_client"}`))
+			return
+		}
+		n := issued.Add(1)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": fmt.Sprintf("token-%d", n),
+			"token_type":   "bearer",
+			"expires_in":   expiresIn,
+		})
+	}))
+	defer tokens.Close()
+
+	srv := authServer(t, func(r *http.Request) bool {
+		token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		return token == fmt.Sprintf("token-%d", issued.Load()) && token != fmt.Sprintf("token-%d", revoked.Load())
+	})
+
+	cfg := providers.AuthConfig{Type: providers.AuthOAuth2, TokenURL: tokens.URL, ClientID: "client", ClientSecret: "s3cret", Scopes: []string{"search", "rates"}}
+	auth, err := providers.LoadAuth(writeAuth(t, t.TempDir(), cfg), time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p := providers.NewHTTPProvider("test", srv.URL, time.Second, auth)
+	search := func() error {
+		_, err := p.Search(context.Background(), "paris", "2026-12-01", 2, providers.SingleRoom(2))
+		return err
+	}
+
+	// The token is cached across searches
+	for range 3 {
+		if err := search(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if n := issued.Load(); n != 1 {
+		t.Errorf("issued %d tokens, want 1", n)
+	}
+
+	// A rejected token is replaced on the next search
+	revoked.Store(1)
+	if err := search(); !errors.Is(err, providers.ErrProviderRejected) {
+		t.Fatalf("error = %v, want ErrProviderRejected", err)
+	}
+	if err := search(); err != nil {
+		t.Fatalf("unexpected error after refresh: %v", err)
+	}
+	if n := issued.Load(); n != 2 {
+		t.Errorf("issued %d tokens, want 2", n)
+	}
+
+	// Tokens about to expire are refreshed before use
+	expiresIn = 10
+	auth, _ = providers.LoadAuth(writeAuth(t, t.TempDir(), cfg), time.Second)
+	p = providers.NewHTTPProvider("test", srv.URL, time.Second, auth)
+	_ = search()
+	_ = search()
+	if n := issued.Load(); n != 4 {
+		t.Errorf("issued %d tokens, want 4", n)
+	}
+
+	cfg.ClientSecret = "wrong"
+	if err := searchWithAuth(t, srv.URL, cfg); !errors.Is(err, providers.ErrProviderRejected) || !strings.Contains(err.Error(), "invalid_client") {
+		t.Errorf("error = %v, want ErrProviderRejected with invalid_client", err)
+	}
+}
+
+func TestOAuth2Auth_Transport(t *testing.T) {
+	// The supplier and its token endpoint are only reachable through a proxy
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Host == "auth.supplier.invalid":
+			_, _ = w.Write([]byte(`{"access_token": "proxied", "token_type": "bearer"}`))
+		case r.Header.Get("Authorization") == "Bearer proxied":
+			_, _ = w.Write([]byte(`[]`))
+		default:
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+		}
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	cfg := providers.AuthConfig{Type: providers.AuthOAuth2, TokenURL: "http://auth.supplier.invalid/token", ClientID: "client", ClientSecret: "s3cret"}
+	auth, err := providers.LoadAuth(writeAuth(t, t.TempDir(), cfg), time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p := providers.NewHTTPProvider("test", "http://supplier.invalid", time.Second, auth,
+		providers.WithTransport(providers.TransportConfig{Proxy: proxyURL}))
+	if _, err := p.Search(context.Background(), "paris", "2026-12-01", 2, providers.SingleRoom(2)); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestMTLSAuth(t *testing.T) {
+	dir := t.TempDir()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "hotels"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM := func(name, typ string, der []byte) {
+		if err := os.WriteFile(filepath.Join(dir, name), pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writePEM("client.crt", "CERTIFICATE", der)
+	writePEM("client.key", "EC PRIVATE KEY", keyDER)
+
+	clientCert, _ := x509.ParseCertificate(der)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	srv.StartTLS()
+	defer srv.Close()
+	writePEM("ca.crt", "CERTIFICATE", srv.Certificate().Raw)
+
+	// Relative paths are resolved against the secrets file
+	path := writeAuth(t, dir, providers.AuthConfig{Type: providers.AuthMTLS, CertFile: "client.crt", KeyFile: "client.key", CAFile: "ca.crt"})
+	auth, err := providers.LoadAuth(path, time.Second)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p := providers.NewHTTPProvider("test", srv.URL, time.Second, auth)
+	if _, err := p.Search(context.Background(), "paris", "2026-12-01", 2, providers.SingleRoom(2)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Without the client certificate the handshake fails
+	tlsConfig, err := providers.LoadClientTLS(filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"), filepath.Join(dir, "ca.crt"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tlsConfig.Certificates = nil
+	p = providers.NewHTTPProvider("test", srv.URL, time.Second, providers.WithTLSConfig(tlsConfig))
+	if _, err := p.Search(context.Background(), "paris", "2026-12-01", 2, providers.SingleRoom(2)); err == nil {
+		t.Error("expected error without a client certificate")
+	}
+}
+
+func TestLoadAuth_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     providers.AuthConfig
+		wantErr string
+	}{
+		{name: "unknown type", cfg: providers.AuthConfig{Type: "basic"}, wantErr: "unknown authentication type"},
+		{name: "api key", cfg: providers.AuthConfig{Type: providers.AuthAPIKey}, wantErr: "requires key"},
+		{name: "api key location", cfg: providers.AuthConfig{Type: providers.AuthAPIKey, Key: "k", Header: "X-Key", Query: "key"}, wantErr: "either header or query"},
+		{name: "hmac", cfg: providers.AuthConfig{Type: providers.AuthHMAC, KeyID: "id"}, wantErr: "requires key_id, secret"},
+		{name: "oauth2", cfg: providers.AuthConfig{Type: providers.AuthOAuth2, ClientID: "id"}, wantErr: "requires token_url"},
+		{name: "mtls", cfg: providers.AuthConfig{Type: providers.AuthMTLS, CertFile: "c.crt"}, wantErr: "requires cert_file, key_file"},
+		{name: "mtls files", cfg: providers.AuthConfig{Type: providers.AuthMTLS, CertFile: "c.crt", KeyFile: "c.key"}, wantErr: "failed to load client certificate"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := providers.LoadAuth(writeAuth(t, t.TempDir(), tt.cfg), time.Second)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
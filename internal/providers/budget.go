@@ -0,0 +1,156 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrBudgetExhausted is returned by a BudgetedProvider instead of searching
+// when the provider's outbound budget is used up.
+var ErrBudgetExhausted = errors.New("provider budget exhausted")
+
+// BudgetedProvider limits the searches sent to a provider to the supplier's
+// quota: a rate in requests per second, with bursts, and a number of
+// requests per day. Searches beyond the budget fail with ErrBudgetExhausted
+// without reaching the provider. Days start at midnight UTC.
+type BudgetedProvider struct {
+	provider Provider
+	rate     float64
+	burst    float64
+	daily    int
+	now      func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	used   int
+	day    time.Time
+}
+
+// BudgetOption configures a BudgetedProvider.
+type BudgetOption func(*BudgetedProvider)
+
+// WithRateLimit limits searches to rate per second on average, with bursts
+// of up to burst searches. A burst below one allows one search at a time.
+func WithRateLimit(rate float64, burst int) BudgetOption {
+	return func(p *BudgetedProvider) {
+		p.rate = rate
+		p.burst = max(float64(burst), 1)
+	}
+}
+
+// WithDailyQuota limits searches to quota per day.
+func WithDailyQuota(quota int) BudgetOption {
+	return func(p *BudgetedProvider) {
+		p.daily = quota
+	}
+}
+
+// WithBudgetClock sets the clock budgets are measured against. Defaults to
+// time.Now.
+func WithBudgetClock(now func() time.Time) BudgetOption {
+	return func(p *BudgetedProvider) {
+		p.now = now
+	}
+}
+
+// NewBudgetedProvider creates a BudgetedProvider in front of provider.
+// Without options, searches are not limited.
+func NewBudgetedProvider(provider Provider, opts ...BudgetOption) *BudgetedProvider {
+	p := &BudgetedProvider{provider: provider, now: time.Now}
+	for _, opt := range opts {
+		opt(p)
+	}
+	now := p.now()
+	p.tokens = p.burst
+	p.last = now
+	p.day = startOfDay(now)
+	return p
+}
+
+// Name returns the name of the limited provider.
+func (p *BudgetedProvider) Name() string {
+	return p.provider.Name()
+}
+
+// Search searches the provider if the budget allows it.
+func (p *BudgetedProvider) Search(ctx context.Context, city, checkin string, nights int, occupancy Occupancy) ([]Hotel, error) {
+	if !p.take() {
+		return nil, ErrBudgetExhausted
+	}
+	return p.provider.Search(ctx, city, checkin, nights, occupancy)
+}
+
+// Close closes the limited provider if it holds resources.
+func (p *BudgetedProvider) Close() error {
+	if c, ok := p.provider.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// RateRemaining returns the number of searches that can be sent at once,
+// or -1 if the rate is not limited.
+func (p *BudgetedProvider) RateRemaining() float64 {
+	if p.rate <= 0 {
+		return -1
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.refill(p.now())
+	return math.Floor(p.tokens)
+}
+
+// DailyRemaining returns the number of searches left today, or -1 if there
+// is no daily quota.
+func (p *BudgetedProvider) DailyRemaining() int {
+	if p.daily <= 0 {
+		return -1
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.refill(p.now())
+	return p.daily - p.used
+}
+
+// take consumes one search from the budget, reporting whether it was
+// available. Nothing is consumed when it wasn't.
+func (p *BudgetedProvider) take() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.refill(p.now())
+	if p.rate > 0 && p.tokens < 1 {
+		return false
+	}
+	if p.daily > 0 && p.used >= p.daily {
+		return false
+	}
+	if p.rate > 0 {
+		p.tokens--
+	}
+	p.used++
+	return true
+}
+
+// refill adds the tokens earned since the last refill and starts a new day's
+// quota at midnight.
+func (p *BudgetedProvider) refill(now time.Time) {
+	if elapsed := now.Sub(p.last); elapsed > 0 {
+		p.tokens = min(p.burst, p.tokens+elapsed.Seconds()*p.rate)
+		p.last = now
+	}
+	if day := startOfDay(now); day.After(p.day) {
+		p.day = day
+		p.used = 0
+	}
+}
+
+// startOfDay returns midnight UTC of the day of t.
+func startOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
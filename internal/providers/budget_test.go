@@ -0,0 +1,104 @@
+package providers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/providers"
+)
+
+// countingProvider counts the searches it receives.
+type countingProvider struct {
+	calls int
+}
+
+func (p *countingProvider) Name() string { return "counting" }
+
+func (p *countingProvider) Search(ctx context.Context, city, checkin string, nights int, occupancy providers.Occupancy) ([]providers.Hotel, error) {
+	p.calls++
+	return nil, nil
+}
+
+func TestBudgetedProvider_RateLimit(t *testing.T) {
+	now := time.Date(2026, 12, 1, 12, 0, 0, 0, time.UTC)
+	inner := &countingProvider{}
+	p := providers.NewBudgetedProvider(inner,
+		providers.WithRateLimit(2, 3),
+		providers.WithBudgetClock(func() time.Time { return now }),
+	)
+	search := func() error {
+		_, err := p.Search(context.Background(), "paris", "2026-12-01", 2, providers.SingleRoom(2))
+		return err
+	}
+
+	// The burst is available at once, then searches are skipped
+	for i := range 3 {
+		if err := search(); err != nil {
+			t.Fatalf("search %d: unexpected error: %v", i+1, err)
+		}
+	}
+	if err := search(); !errors.Is(err, providers.ErrBudgetExhausted) {
+		t.Fatalf("error = %v, want ErrBudgetExhausted", err)
+	}
+	if inner.calls != 3 {
+		t.Errorf("provider received %d searches, want 3", inner.calls)
+	}
+	if r := p.RateRemaining(); r != 0 {
+		t.Errorf("rate remaining = %v, want 0", r)
+	}
+
+	// Budget is regained at the rate, up to the burst
+	now = now.Add(500 * time.Millisecond)
+	if err := search(); err != nil {
+		t.Fatalf("unexpected error after refill: %v", err)
+	}
+	now = now.Add(time.Minute)
+	if r := p.RateRemaining(); r != 3 {
+		t.Errorf("rate remaining = %v, want the burst of 3", r)
+	}
+	if r := p.DailyRemaining(); r != -1 {
+		t.Errorf("daily remaining = %d, want -1 without a quota", r)
+	}
+}
+
+func TestBudgetedProvider_DailyQuota(t *testing.T) {
+	now := time.Date(2026, 12, 1, 23, 0, 0, 0, time.UTC)
+	inner := &countingProvider{}
+	p := providers.NewBudgetedProvider(inner,
+		providers.WithDailyQuota(2),
+		providers.WithBudgetClock(func() time.Time { return now }),
+	)
+	search := func() error {
+		_, err := p.Search(context.Background(), "paris", "2026-12-01", 2, providers.SingleRoom(2))
+		return err
+	}
+
+	for range 2 {
+		if err := search(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := search(); !errors.Is(err, providers.ErrBudgetExhausted) {
+		t.Fatalf("error = %v, want ErrBudgetExhausted", err)
+	}
+	if r := p.DailyRemaining(); r != 0 {
+		t.Errorf("daily remaining = %d, want 0", r)
+	}
+
+	// The quota starts over at midnight UTC
+	now = now.Add(time.Hour)
+	if r := p.DailyRemaining(); r != 2 {
+		t.Errorf("daily remaining = %d after midnight, want 2", r)
+	}
+	if err := search(); err != nil {
+		t.Fatalf("unexpected error after midnight: %v", err)
+	}
+	if inner.calls != 3 {
+		t.Errorf("provider received %d searches, want 3", inner.calls)
+	}
+	if r := p.RateRemaining(); r != -1 {
+		t.Errorf("rate remaining = %v, want -1 without a rate limit", r)
+	}
+}
@@ -0,0 +1,102 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+)
+
+// ErrBulkheadFull is returned by a BulkheadProvider instead of searching
+// when the provider has as many searches in flight and waiting as allowed.
+var ErrBulkheadFull = errors.New("provider bulkhead full")
+
+// BulkheadProvider limits the searches in flight to a provider, so that a
+// slow provider can't tie up every goroutine and connection. Searches over
+// the limit wait for a free slot, up to a number of waiting searches; the
+// rest fail with ErrBulkheadFull without reaching the provider. Waiting
+// searches give up when their context is done.
+type BulkheadProvider struct {
+	provider Provider
+	slots    chan struct{}
+	maxQueue int64
+
+	waiting  atomic.Int64
+	queued   atomic.Int64
+	rejected atomic.Int64
+}
+
+// BulkheadStats describes the load of a BulkheadProvider. Queued and
+// Rejected count searches since the provider was created.
+type BulkheadStats struct {
+	InFlight int64
+	Waiting  int64
+	Queued   int64
+	Rejected int64
+}
+
+// NewBulkheadProvider creates a BulkheadProvider in front of provider
+// allowing maxInFlight searches at once, at least one, and maxQueue more to
+// wait.
+func NewBulkheadProvider(provider Provider, maxInFlight, maxQueue int) *BulkheadProvider {
+	return &BulkheadProvider{
+		provider: provider,
+		slots:    make(chan struct{}, max(maxInFlight, 1)),
+		maxQueue: int64(max(maxQueue, 0)),
+	}
+}
+
+// Name returns the name of the limited provider.
+func (p *BulkheadProvider) Name() string {
+	return p.provider.Name()
+}
+
+// Search searches the provider once a slot is free.
+func (p *BulkheadProvider) Search(ctx context.Context, city, checkin string, nights int, occupancy Occupancy) ([]Hotel, error) {
+	select {
+	case p.slots <- struct{}{}:
+	default:
+		if err := p.wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+	defer func() { <-p.slots }()
+
+	return p.provider.Search(ctx, city, checkin, nights, occupancy)
+}
+
+// wait waits for a free slot, if there is room to wait.
+func (p *BulkheadProvider) wait(ctx context.Context) error {
+	if p.waiting.Add(1) > p.maxQueue {
+		p.waiting.Add(-1)
+		p.rejected.Add(1)
+		return ErrBulkheadFull
+	}
+	defer p.waiting.Add(-1)
+	p.queued.Add(1)
+
+	select {
+	case p.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// Stats returns the current load of the provider.
+func (p *BulkheadProvider) Stats() BulkheadStats {
+	return BulkheadStats{
+		InFlight: int64(len(p.slots)),
+		Waiting:  p.waiting.Load(),
+		Queued:   p.queued.Load(),
+		Rejected: p.rejected.Load(),
+	}
+}
+
+// Close closes the limited provider if it holds resources.
+func (p *BulkheadProvider) Close() error {
+	if c, ok := p.provider.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package providers_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/providers"
+)
+
+// blockingProvider holds searches until released.
+type blockingProvider struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *blockingProvider) Name() string { return "blocking" }
+
+func (p *blockingProvider) Search(ctx context.Context, city, checkin string, nights int, occupancy providers.Occupancy) ([]providers.Hotel, error) {
+	p.started <- struct{}{}
+	<-p.release
+	return nil, nil
+}
+
+func TestBulkheadProvider(t *testing.T) {
+	inner := &blockingProvider{started: make(chan struct{}, 10), release: make(chan struct{})}
+	p := providers.NewBulkheadProvider(inner, 2, 1)
+
+	search := func(ctx context.Context) <-chan error {
+		done := make(chan error, 1)
+		go func() {
+			_, err := p.Search(ctx, "paris", "2026-12-01", 2, providers.SingleRoom(2))
+			done <- err
+		}()
+		return done
+	}
+	waitFor := func(cond func(providers.BulkheadStats) bool) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for !cond(p.Stats()) {
+			if time.Now().After(deadline) {
+				t.Fatalf("unexpected stats: %+v", p.Stats())
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// Two searches run, a third waits and a fourth is rejected
+	first, second := search(context.Background()), search(context.Background())
+	<-inner.started
+	<-inner.started
+	third := search(context.Background())
+	waitFor(func(s providers.BulkheadStats) bool { return s.Waiting == 1 })
+	if err := <-search(context.Background()); !errors.Is(err, providers.ErrBulkheadFull) {
+		t.Fatalf("error = %v, want ErrBulkheadFull", err)
+	}
+	if s := p.Stats(); s != (providers.BulkheadStats{InFlight: 2, Waiting: 1, Queued: 1, Rejected: 1}) {
+		t.Errorf("stats = %+v, want 2 in flight, 1 waiting, 1 queued and 1 rejected", s)
+	}
+
+	// The waiting search runs once a slot is free
+	inner.release <- struct{}{}
+	<-inner.started
+	for range 2 {
+		inner.release <- struct{}{}
+	}
+	for _, done := range []<-chan error{first, second, third} {
+		if err := <-done; err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+	if s := p.Stats(); s.InFlight != 0 || s.Waiting != 0 {
+		t.Errorf("stats = %+v, want nothing in flight or waiting", s)
+	}
+}
+
+func TestBulkheadProvider_WaitCanceled(t *testing.T) {
+	inner := &blockingProvider{started: make(chan struct{}, 1), release: make(chan struct{})}
+	p := providers.NewBulkheadProvider(inner, 1, 5)
+	defer close(inner.release)
+
+	go func() { _, _ = p.Search(context.Background(), "paris", "2026-12-01", 2, providers.SingleRoom(2)) }()
+	<-inner.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := p.Search(ctx, "paris", "2026-12-01", 2, providers.SingleRoom(2)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want DeadlineExceeded", err)
+	}
+	if s := p.Stats(); s.Waiting != 0 || s.Queued != 1 || s.Rejected != 0 {
+		t.Errorf("stats = %+v, want 1 queued search that gave up", s)
+	}
+}
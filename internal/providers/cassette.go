@@ -0,0 +1,343 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cassette holds the searches recorded from a provider.
+type Cassette struct {
+	Provider     string        `json:"provider"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded search: the request, what the provider
+// answered and how long it took.
+type Interaction struct {
+	Request   RecordedRequest `json:"request"`
+	Hotels    []Hotel         `json:"hotels,omitempty"`
+	Error     *RecordedError  `json:"error,omitempty"`
+	LatencyMS float64         `json:"latency_ms"`
+}
+
+// RecordedRequest is the request of a recorded search.
+type RecordedRequest struct {
+	City      string `json:"city"`
+	Checkin   string `json:"checkin"`
+	Nights    int    `json:"nights"`
+	Adults    int    `json:"adults"`
+	Occupancy string `json:"occupancy"`
+}
+
+// RecordedError is the error of a recorded search. Kind keeps the error's
+// class so that the replayed error matches the same sentinel errors.
+type RecordedError struct {
+	Message string `json:"message"`
+	Kind    string `json:"kind,omitempty"`
+}
+
+// Kinds of recorded errors.
+const (
+	ErrorKindUnavailable = "unavailable"
+	ErrorKindRejected    = "rejected"
+	ErrorKindDeadline    = "deadline"
+	ErrorKindCanceled    = "canceled"
+)
+
+// errorKinds maps kinds of recorded errors to the errors they stand for,
+// most specific first.
+var errorKinds = []struct {
+	kind string
+	err  error
+}{
+	{ErrorKindUnavailable, ErrProviderUnavailable},
+	{ErrorKindRejected, ErrProviderRejected},
+	{ErrorKindDeadline, context.DeadlineExceeded},
+	{ErrorKindCanceled, context.Canceled},
+}
+
+// LoadCassette reads a cassette file.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette: %w", err)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to path. The file is replaced atomically.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name()) // Already renamed on success
+	}()
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// Recorder wraps a provider and records its searches, including errors and
+// latencies, to a cassette file. The cassette is written on Save and Close.
+type Recorder struct {
+	provider Provider
+	path     string
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder creates a Recorder that records the searches of provider to
+// the cassette file at path.
+func NewRecorder(provider Provider, path string) *Recorder {
+	return &Recorder{
+		provider: provider,
+		path:     path,
+		cassette: Cassette{Provider: provider.Name()},
+	}
+}
+
+// Name returns the name of the recorded provider.
+func (r *Recorder) Name() string {
+	return r.provider.Name()
+}
+
+// Search searches the recorded provider and records the search.
+func (r *Recorder) Search(ctx context.Context, city, checkin string, nights int, occupancy Occupancy) ([]Hotel, error) {
+	start := time.Now()
+	hotels, err := r.provider.Search(ctx, city, checkin, nights, occupancy)
+	latency := time.Since(start)
+
+	interaction := Interaction{
+		Request: RecordedRequest{
+			City:      city,
+			Checkin:   checkin,
+			Nights:    nights,
+			Adults:    occupancy.Adults(),
+			Occupancy: occupancy.String(),
+		},
+		Hotels:    slices.Clone(hotels),
+		LatencyMS: float64(latency.Microseconds()) / 1000,
+	}
+	if err != nil {
+		interaction.Error = &RecordedError{Message: err.Error(), Kind: errorKind(err)}
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	return hotels, err
+}
+
+// Save writes the searches recorded so far to the cassette file.
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette.Save(r.path)
+}
+
+// Close writes the cassette file and closes the recorded provider if it
+// holds resources.
+func (r *Recorder) Close() error {
+	err := r.Save()
+	if c, ok := r.provider.(io.Closer); ok {
+		err = errors.Join(err, c.Close())
+	}
+	return err
+}
+
+// errorKind returns the kind of a search error, or "" if it has none.
+func errorKind(err error) string {
+	for _, k := range errorKinds {
+		if errors.Is(err, k.err) {
+			return k.kind
+		}
+	}
+	return ""
+}
+
+// kindError returns the error a kind of recorded error stands for, or nil.
+func kindError(kind string) error {
+	for _, k := range errorKinds {
+		if k.kind == kind {
+			return k.err
+		}
+	}
+	return nil
+}
+
+// Request fields replays can be matched on.
+const (
+	MatchCity    = "city"
+	MatchCheckin = "checkin"
+	MatchNights  = "nights"
+	MatchAdults  = "adults"
+)
+
+// ErrNoInteraction is returned by a ReplayProvider for a search that
+// matches no recorded interaction.
+var ErrNoInteraction = errors.New("no recorded interaction matches the search")
+
+// ReplayProvider serves the searches recorded in a cassette. A search is
+// answered with the recorded interactions whose request matches it on the
+// configured fields, in the order they were recorded; once all of them have
+// been served, they are served again from the first. Recorded errors are
+// returned with their message and kind, and recorded latencies are waited
+// out.
+type ReplayProvider struct {
+	name         string
+	interactions []Interaction
+	match        []string
+	latency      float64
+
+	mu   sync.Mutex
+	next map[string]int
+}
+
+// ReplayOption configures a ReplayProvider.
+type ReplayOption func(*ReplayProvider)
+
+// WithMatch sets the request fields searches are matched on. Defaults to
+// city, check-in date, nights and adults.
+func WithMatch(fields ...string) ReplayOption {
+	return func(p *ReplayProvider) {
+		p.match = fields
+	}
+}
+
+// WithLatencyScale scales the recorded latencies; 0 replays without delay.
+// Defaults to 1.
+func WithLatencyScale(scale float64) ReplayOption {
+	return func(p *ReplayProvider) {
+		p.latency = scale
+	}
+}
+
+// NewReplayProvider creates a ReplayProvider named name serving the
+// interactions of cassette.
+func NewReplayProvider(name string, cassette *Cassette, opts ...ReplayOption) (*ReplayProvider, error) {
+	p := &ReplayProvider{
+		name:         name,
+		interactions: cassette.Interactions,
+		match:        []string{MatchCity, MatchCheckin, MatchNights, MatchAdults},
+		latency:      1,
+		next:         make(map[string]int),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	for _, field := range p.match {
+		switch field {
+		case MatchCity, MatchCheckin, MatchNights, MatchAdults:
+		default:
+			return nil, fmt.Errorf("unknown match field %q", field)
+		}
+	}
+	return p, nil
+}
+
+// Name returns the provider name.
+func (p *ReplayProvider) Name() string {
+	return p.name
+}
+
+// Search serves the next recorded interaction matching the search.
+func (p *ReplayProvider) Search(ctx context.Context, city, checkin string, nights int, occupancy Occupancy) ([]Hotel, error) {
+	req := RecordedRequest{City: city, Checkin: checkin, Nights: nights, Adults: occupancy.Adults()}
+	key := p.key(req)
+
+	var matches []int
+	for i, in := range p.interactions {
+		if p.key(in.Request) == key {
+			matches = append(matches, i)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoInteraction, key)
+	}
+
+	p.mu.Lock()
+	n := p.next[key]
+	p.next[key] = n + 1
+	p.mu.Unlock()
+	in := p.interactions[matches[n%len(matches)]]
+
+	if delay := time.Duration(in.LatencyMS * p.latency * float64(time.Millisecond)); delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return nil, context.Cause(ctx)
+		}
+	}
+
+	if in.Error != nil {
+		return nil, &replayedError{message: in.Error.Message, kind: kindError(in.Error.Kind)}
+	}
+	return slices.Clone(in.Hotels), nil
+}
+
+// key returns the matched fields of a request.
+func (p *ReplayProvider) key(req RecordedRequest) string {
+	parts := make([]string, len(p.match))
+	for i, field := range p.match {
+		switch field {
+		case MatchCity:
+			parts[i] = "city=" + strings.ToLower(strings.TrimSpace(req.City))
+		case MatchCheckin:
+			parts[i] = "checkin=" + req.Checkin
+		case MatchNights:
+			parts[i] = fmt.Sprintf("nights=%d", req.Nights)
+		case MatchAdults:
+			parts[i] = fmt.Sprintf("adults=%d", req.Adults)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// replayedError is a recorded error served again. It unwraps to the error
+// its kind stands for.
+type replayedError struct {
+	message string
+	kind    error
+}
+
+func (e *replayedError) Error() string {
+	return e.message
+}
+
+func (e *replayedError) Unwrap() error {
+	return e.kind
+}
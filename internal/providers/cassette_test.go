@@ -0,0 +1,188 @@
+package providers_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/providers"
+)
+
+// sequenceProvider answers searches with the next of its results.
+type sequenceProvider struct {
+	results []sequenceResult
+	calls   int
+}
+
+type sequenceResult struct {
+	hotels []providers.Hotel
+	err    error
+	delay  time.Duration
+}
+
+func (p *sequenceProvider) Name() string { return "sequence" }
+
+func (p *sequenceProvider) Search(ctx context.Context, city, checkin string, nights int, occupancy providers.Occupancy) ([]providers.Hotel, error) {
+	r := p.results[p.calls%len(p.results)]
+	p.calls++
+	time.Sleep(r.delay)
+	return r.hotels, r.err
+}
+
+// recordCassette records the searches of a sequence provider and returns
+// the cassette file.
+func recordCassette(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	inner := &sequenceProvider{results: []sequenceResult{
+		{hotels: []providers.Hotel{{HotelID: "H1", Name: "Grand Hotel", Price: 100}}, delay: 20 * time.Millisecond},
+		{err: fmt.Errorf("request failed: %w", providers.ErrProviderUnavailable)},
+		{hotels: []providers.Hotel{{HotelID: "H1", Name: "Grand Hotel", Price: 120}}},
+	}}
+	rec := providers.NewRecorder(inner, path)
+
+	ctx := context.Background()
+	for range 3 {
+		_, _ = rec.Search(ctx, "Paris", "2026-12-01", 2, providers.SingleRoom(2))
+	}
+	_, _ = rec.Search(ctx, "rome", "2026-12-01", 2, providers.Occupancy{{Adults: 1}, {Adults: 1}})
+
+	if err := rec.Close(); err != nil {
+		t.Fatalf("failed to save cassette: %v", err)
+	}
+	return path
+}
+
+func TestRecorder(t *testing.T) {
+	cassette, err := providers.LoadCassette(recordCassette(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if cassette.Provider != "sequence" || len(cassette.Interactions) != 4 {
+		t.Fatalf("unexpected cassette: %+v", cassette)
+	}
+	first := cassette.Interactions[0]
+	want := providers.RecordedRequest{City: "Paris", Checkin: "2026-12-01", Nights: 2, Adults: 2, Occupancy: "2"}
+	if first.Request != want || len(first.Hotels) != 1 || first.LatencyMS < 20 {
+		t.Errorf("unexpected interaction: %+v", first)
+	}
+	failed := cassette.Interactions[1]
+	if failed.Error == nil || failed.Error.Kind != providers.ErrorKindUnavailable || failed.Error.Message != "request failed: provider unavailable" {
+		t.Errorf("unexpected recorded error: %+v", failed.Error)
+	}
+	if last := cassette.Interactions[3].Request; last.Adults != 2 || last.Occupancy != "1|1" {
+		t.Errorf("unexpected request: %+v", last)
+	}
+}
+
+func TestReplayProvider_Search(t *testing.T) {
+	cassette, err := providers.LoadCassette(recordCassette(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p, err := providers.NewReplayProvider("replay", cassette, providers.WithLatencyScale(0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Matching searches are answered in recorded order, then from the start
+	ctx := context.Background()
+	var prices []float64
+	var errs int
+	for range 4 {
+		hotels, err := p.Search(ctx, "paris", "2026-12-01", 2, providers.SingleRoom(2))
+		switch {
+		case errors.Is(err, providers.ErrProviderUnavailable):
+			errs++
+			if err.Error() != "request failed: provider unavailable" {
+				t.Errorf("replayed error = %q, want the recorded message", err)
+			}
+		case err != nil:
+			t.Fatalf("unexpected error: %v", err)
+		default:
+			prices = append(prices, hotels[0].Price)
+		}
+	}
+	if !slices.Equal(prices, []float64{100, 120, 100}) || errs != 1 {
+		t.Errorf("prices = %v with %d errors, want [100 120 100] with 1", prices, errs)
+	}
+
+	// Adults are matched in total, whatever the rooms
+	if hotels, err := p.Search(ctx, "rome", "2026-12-01", 2, providers.SingleRoom(2)); err != nil || len(hotels) != 1 {
+		t.Errorf("got %d hotels, error %v, want the rome interaction", len(hotels), err)
+	}
+
+	tests := []struct {
+		name           string
+		city, checkin  string
+		nights, adults int
+	}{
+		{name: "other checkin", city: "paris", checkin: "2026-12-02", nights: 2, adults: 2},
+		{name: "other nights", city: "paris", checkin: "2026-12-01", nights: 3, adults: 2},
+		{name: "other adults", city: "paris", checkin: "2026-12-01", nights: 2, adults: 3},
+		{name: "other city", city: "london", checkin: "2026-12-01", nights: 2, adults: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := p.Search(ctx, tt.city, tt.checkin, tt.nights, providers.SingleRoom(tt.adults))
+			if !errors.Is(err, providers.ErrNoInteraction) {
+				t.Errorf("error = %v, want ErrNoInteraction", err)
+			}
+		})
+	}
+}
+
+func TestReplayProvider_Match(t *testing.T) {
+	cassette, err := providers.LoadCassette(recordCassette(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	p, err := providers.NewReplayProvider("replay", cassette,
+		providers.WithMatch(providers.MatchCity),
+		providers.WithLatencyScale(0),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	hotels, err := p.Search(context.Background(), "paris", "2027-01-15", 5, providers.SingleRoom(1))
+	if err != nil || len(hotels) != 1 || hotels[0].Price != 100 {
+		t.Errorf("got %v, error %v, want the first paris interaction", hotels, err)
+	}
+
+	if _, err := providers.NewReplayProvider("replay", cassette, providers.WithMatch("rooms")); err == nil {
+		t.Error("expected error for an unknown match field")
+	}
+}
+
+func TestReplayProvider_Latency(t *testing.T) {
+	cassette := &providers.Cassette{Interactions: []providers.Interaction{{
+		Request:   providers.RecordedRequest{City: "paris", Checkin: "2026-12-01", Nights: 2, Adults: 2},
+		Hotels:    []providers.Hotel{{HotelID: "H1"}},
+		LatencyMS: 100,
+	}}}
+	p, err := providers.NewReplayProvider("replay", cassette, providers.WithLatencyScale(0.5))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	start := time.Now()
+	if _, err := p.Search(context.Background(), "paris", "2026-12-01", 2, providers.SingleRoom(2)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("replay took %v, want at least the scaled 50ms", elapsed)
+	}
+
+	// A deadline shorter than the recorded latency times out the replay
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := p.Search(ctx, "paris", "2026-12-01", 2, providers.SingleRoom(2)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,166 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/alex-user-go/hotels/internal/providers/hotelpb"
+)
+
+// GRPCProvider queries a gRPC endpoint implementing the HotelSearch service
+// in hotelpb. One connection is opened lazily and reused by all searches
+// until Close.
+//
+// Each call's deadline is the earlier of the caller's and the provider
+// timeout. Failures are classified by status code: codes a retry may
+// resolve wrap ErrProviderUnavailable, codes blaming the request wrap
+// ErrProviderRejected, and NotFound counts as no hotels. The gRPC status
+// stays available through status.FromError.
+type GRPCProvider struct {
+	name       string
+	timeout    time.Duration
+	priceBasis PriceBasis
+	dialOpts   []grpc.DialOption
+	conn       *grpc.ClientConn
+	client     hotelpb.HotelSearchClient
+}
+
+// GRPCOption configures a GRPCProvider.
+type GRPCOption func(*GRPCProvider)
+
+// WithGRPCPriceBasis declares what the provider's prices cover when its
+// hotels don't say so themselves. Defaults to PricePerStay.
+func WithGRPCPriceBasis(basis PriceBasis) GRPCOption {
+	return func(p *GRPCProvider) {
+		p.priceBasis = basis
+	}
+}
+
+// WithDialOptions adds options used to create the connection, e.g.
+// transport credentials. Connections are insecure by default.
+func WithDialOptions(opts ...grpc.DialOption) GRPCOption {
+	return func(p *GRPCProvider) {
+		p.dialOpts = append(p.dialOpts, opts...)
+	}
+}
+
+// NewGRPCProvider creates a new GRPCProvider for target, a gRPC target such
+// as "localhost:9006" or "dns:///hotels.example.com:443". No connection is
+// made until the first search.
+func NewGRPCProvider(name, target string, timeout time.Duration, opts ...GRPCOption) (*GRPCProvider, error) {
+	p := &GRPCProvider{
+		name:       name,
+		timeout:    timeout,
+		priceBasis: PricePerStay,
+		dialOpts:   []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())},
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	conn, err := grpc.NewClient(target, p.dialOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target: %w", err)
+	}
+	p.conn = conn
+	p.client = hotelpb.NewHotelSearchClient(conn)
+	return p, nil
+}
+
+// Name returns the provider name.
+func (p *GRPCProvider) Name() string {
+	return p.name
+}
+
+// Close closes the connection.
+func (p *GRPCProvider) Close() error {
+	return p.conn.Close()
+}
+
+// Search searches for hotels with a HotelSearch.Search call.
+func (p *GRPCProvider) Search(ctx context.Context, city, checkin string, nights int, occupancy Occupancy) ([]Hotel, error) {
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	req := &hotelpb.SearchRequest{
+		City:    city,
+		Checkin: checkin,
+		Nights:  int32(nights),
+		Rooms:   make([]*hotelpb.Room, len(occupancy)),
+	}
+	for i, room := range occupancy {
+		req.Rooms[i] = &hotelpb.Room{Adults: int32(room.Adults)}
+		for _, age := range room.ChildAges {
+			req.Rooms[i].ChildAges = append(req.Rooms[i].ChildAges, int32(age))
+		}
+	}
+
+	resp, err := p.client.Search(ctx, req)
+	if status.Code(err) == codes.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, classifyStatus(err)
+	}
+
+	hotels := make([]Hotel, 0, len(resp.GetHotels()))
+	for _, h := range resp.GetHotels() {
+		hotels = append(hotels, p.hotel(h))
+	}
+	return hotels, nil
+}
+
+// hotel converts a hotel from its wire form.
+func (p *GRPCProvider) hotel(h *hotelpb.Hotel) Hotel {
+	hotel := Hotel{
+		HotelID:    h.GetHotelId(),
+		Name:       h.GetName(),
+		City:       h.GetCity(),
+		Currency:   h.GetCurrency(),
+		Price:      h.GetPrice(),
+		Taxes:      h.GetTaxes(),
+		Fees:       h.GetFees(),
+		Nights:     int(h.GetNights()),
+		PriceBasis: p.priceBasis,
+		Address:    h.GetAddress(),
+		Stars:      h.GetStars(),
+		Amenities:  h.GetAmenities(),
+		Images:     h.GetImages(),
+	}
+	switch h.GetPriceBasis() {
+	case hotelpb.PriceBasis_PRICE_BASIS_PER_STAY:
+		hotel.PriceBasis = PricePerStay
+	case hotelpb.PriceBasis_PRICE_BASIS_PER_NIGHT:
+		hotel.PriceBasis = PricePerNight
+	}
+	if loc := h.GetLocation(); loc != nil {
+		lat, lon := loc.GetLatitude(), loc.GetLongitude()
+		hotel.Latitude, hotel.Longitude = &lat, &lon
+	}
+	return hotel
+}
+
+// classifyStatus wraps a failed call's error with the provider error its
+// status code stands for.
+func classifyStatus(err error) error {
+	switch status.Code(err) {
+	case codes.Canceled:
+		return fmt.Errorf("request failed: %w: %w", context.Canceled, err)
+	case codes.DeadlineExceeded, codes.Unavailable, codes.ResourceExhausted,
+		codes.Aborted, codes.Internal, codes.Unknown, codes.DataLoss:
+		return fmt.Errorf("request failed: %w: %w", ErrProviderUnavailable, err)
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange, codes.Unimplemented,
+		codes.Unauthenticated, codes.PermissionDenied, codes.AlreadyExists:
+		return fmt.Errorf("request failed: %w: %w", ErrProviderRejected, err)
+	}
+	return fmt.Errorf("request failed: %w", err)
+}
@@ -0,0 +1,180 @@
+package providers_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/alex-user-go/hotels/internal/providers"
+	"github.com/alex-user-go/hotels/internal/providers/hotelpb"
+)
+
+// searchServer is a HotelSearch server answering with search.
+type searchServer struct {
+	hotelpb.UnimplementedHotelSearchServer
+	search func(ctx context.Context, req *hotelpb.SearchRequest) (*hotelpb.SearchResponse, error)
+}
+
+func (s *searchServer) Search(ctx context.Context, req *hotelpb.SearchRequest) (*hotelpb.SearchResponse, error) {
+	return s.search(ctx, req)
+}
+
+// countingListener counts the connections it accepts.
+type countingListener struct {
+	net.Listener
+	accepted atomic.Int32
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err == nil {
+		l.accepted.Add(1)
+	}
+	return conn, err
+}
+
+// newGRPCProvider starts a HotelSearch server and returns a provider
+// connected to it along with the server's listener.
+func newGRPCProvider(t *testing.T, timeout time.Duration, search func(context.Context, *hotelpb.SearchRequest) (*hotelpb.SearchResponse, error)) (*providers.GRPCProvider, *countingListener) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counting := &countingListener{Listener: lis}
+
+	srv := grpc.NewServer()
+	hotelpb.RegisterHotelSearchServer(srv, &searchServer{search: search})
+	go func() { _ = srv.Serve(counting) }()
+	t.Cleanup(srv.Stop)
+
+	p, err := providers.NewGRPCProvider("test", lis.Addr().String(), timeout, providers.WithGRPCPriceBasis(providers.PricePerNight))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = p.Close() })
+	return p, counting
+}
+
+func TestGRPCProvider_Search(t *testing.T) {
+	var got *hotelpb.SearchRequest
+	p, lis := newGRPCProvider(t, time.Second, func(ctx context.Context, req *hotelpb.SearchRequest) (*hotelpb.SearchResponse, error) {
+		got = req
+		return &hotelpb.SearchResponse{Hotels: []*hotelpb.Hotel{
+			{
+				HotelId: "G-1", Name: "Grand Hotel", City: "paris", Currency: "USD", Price: 300, Taxes: 30, Nights: 2,
+				PriceBasis: hotelpb.PriceBasis_PRICE_BASIS_PER_STAY,
+				Location:   &hotelpb.Location{Latitude: 48.87, Longitude: 2.33},
+				Amenities:  []string{"spa"},
+			},
+			{HotelId: "G-2", Name: "Budget Stay", Currency: "USD", Price: 60},
+		}}, nil
+	})
+
+	occupancy := providers.Occupancy{{Adults: 2, ChildAges: []int{5}}, {Adults: 1}}
+	for range 3 {
+		hotels, err := p.Search(context.Background(), "paris", "2026-12-01", 2, occupancy)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(hotels) != 2 {
+			t.Fatalf("expected 2 hotels, got %d", len(hotels))
+		}
+
+		h := hotels[0]
+		if h.HotelID != "G-1" || h.Price != 300 || h.Taxes != 30 || h.Nights != 2 || h.PriceBasis != providers.PricePerStay {
+			t.Errorf("unexpected hotel: %+v", h)
+		}
+		if h.Latitude == nil || *h.Latitude != 48.87 || h.Longitude == nil || *h.Longitude != 2.33 {
+			t.Errorf("unexpected location: %v, %v", h.Latitude, h.Longitude)
+		}
+		if h := hotels[1]; h.PriceBasis != providers.PricePerNight || h.Latitude != nil {
+			t.Errorf("price basis = %q, location = %v, want the provider default and none", h.PriceBasis, h.Latitude)
+		}
+	}
+
+	if got.GetCity() != "paris" || got.GetNights() != 2 || len(got.GetRooms()) != 2 ||
+		got.GetRooms()[0].GetAdults() != 2 || len(got.GetRooms()[0].GetChildAges()) != 1 {
+		t.Errorf("unexpected request: %v", got)
+	}
+	if n := lis.accepted.Load(); n != 1 {
+		t.Errorf("opened %d connections, want 1 reused", n)
+	}
+}
+
+func TestGRPCProvider_Search_Deadline(t *testing.T) {
+	var remaining atomic.Int64
+	p, _ := newGRPCProvider(t, 100*time.Millisecond, func(ctx context.Context, req *hotelpb.SearchRequest) (*hotelpb.SearchResponse, error) {
+		if deadline, ok := ctx.Deadline(); ok {
+			remaining.Store(int64(time.Until(deadline)))
+		}
+		<-ctx.Done()
+		return nil, status.FromContextError(ctx.Err()).Err()
+	})
+
+	// The caller's earlier deadline wins over the provider timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := p.Search(ctx, "paris", "2026-12-01", 2, providers.SingleRoom(2))
+	if !errors.Is(err, providers.ErrProviderUnavailable) || status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("error = %v, want unavailable with DeadlineExceeded", err)
+	}
+	if r := time.Duration(remaining.Load()); r <= 0 || r > 50*time.Millisecond {
+		t.Errorf("server saw %v left, want at most the caller's 50ms", r)
+	}
+
+	// Without one, the provider timeout applies
+	start := time.Now()
+	if _, err := p.Search(context.Background(), "paris", "2026-12-01", 2, providers.SingleRoom(2)); status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("error = %v, want DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("search took %v, want about the 100ms timeout", elapsed)
+	}
+}
+
+func TestGRPCProvider_Search_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      codes.Code
+		want      error
+		wantEmpty bool
+	}{
+		{name: "unavailable", code: codes.Unavailable, want: providers.ErrProviderUnavailable},
+		{name: "resource exhausted", code: codes.ResourceExhausted, want: providers.ErrProviderUnavailable},
+		{name: "internal", code: codes.Internal, want: providers.ErrProviderUnavailable},
+		{name: "invalid argument", code: codes.InvalidArgument, want: providers.ErrProviderRejected},
+		{name: "unauthenticated", code: codes.Unauthenticated, want: providers.ErrProviderRejected},
+		{name: "unimplemented", code: codes.Unimplemented, want: providers.ErrProviderRejected},
+		{name: "not found", code: codes.NotFound, wantEmpty: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, _ := newGRPCProvider(t, time.Second, func(ctx context.Context, req *hotelpb.SearchRequest) (*hotelpb.SearchResponse, error) {
+				return nil, status.Error(tt.code, "test failure")
+			})
+
+			hotels, err := p.Search(context.Background(), "paris", "2026-12-01", 2, providers.SingleRoom(2))
+			if tt.wantEmpty {
+				if err != nil || len(hotels) != 0 {
+					t.Fatalf("got %d hotels, error %v, want none without error", len(hotels), err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.want) {
+				t.Errorf("error = %v, want %v", err, tt.want)
+			}
+			if status.Code(err) != tt.code {
+				t.Errorf("status code = %v, want %v", status.Code(err), tt.code)
+			}
+		})
+	}
+}
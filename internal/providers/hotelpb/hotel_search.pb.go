@@ -0,0 +1,549 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: hotel_search.proto
+
+// Hotel search API for partners that expose gRPC instead of HTTP/JSON.
+
+package hotelpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// PriceBasis tells what a price covers.
+type PriceBasis int32
+
+const (
+	// The basis declared for the provider.
+	PriceBasis_PRICE_BASIS_UNSPECIFIED PriceBasis = 0
+	PriceBasis_PRICE_BASIS_PER_STAY    PriceBasis = 1
+	PriceBasis_PRICE_BASIS_PER_NIGHT   PriceBasis = 2
+)
+
+// Enum value maps for PriceBasis.
+var (
+	PriceBasis_name = map[int32]string{
+		0: "PRICE_BASIS_UNSPECIFIED",
+		1: "PRICE_BASIS_PER_STAY",
+		2: "PRICE_BASIS_PER_NIGHT",
+	}
+	PriceBasis_value = map[string]int32{
+		"PRICE_BASIS_UNSPECIFIED": 0,
+		"PRICE_BASIS_PER_STAY":    1,
+		"PRICE_BASIS_PER_NIGHT":   2,
+	}
+)
+
+func (x PriceBasis) Enum() *PriceBasis {
+	p := new(PriceBasis)
+	*p = x
+	return p
+}
+
+func (x PriceBasis) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PriceBasis) Descriptor() protoreflect.EnumDescriptor {
+	return file_hotel_search_proto_enumTypes[0].Descriptor()
+}
+
+func (PriceBasis) Type() protoreflect.EnumType {
+	return &file_hotel_search_proto_enumTypes[0]
+}
+
+func (x PriceBasis) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PriceBasis.Descriptor instead.
+func (PriceBasis) EnumDescriptor() ([]byte, []int) {
+	return file_hotel_search_proto_rawDescGZIP(), []int{0}
+}
+
+type SearchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	City  string                 `protobuf:"bytes,1,opt,name=city,proto3" json:"city,omitempty"`
+	// Check-in date as YYYY-MM-DD.
+	Checkin string `protobuf:"bytes,2,opt,name=checkin,proto3" json:"checkin,omitempty"`
+	Nights  int32  `protobuf:"varint,3,opt,name=nights,proto3" json:"nights,omitempty"`
+	// One entry per room.
+	Rooms         []*Room `protobuf:"bytes,4,rep,name=rooms,proto3" json:"rooms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_hotel_search_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_hotel_search_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_hotel_search_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *SearchRequest) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetCheckin() string {
+	if x != nil {
+		return x.Checkin
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetNights() int32 {
+	if x != nil {
+		return x.Nights
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetRooms() []*Room {
+	if x != nil {
+		return x.Rooms
+	}
+	return nil
+}
+
+// Room describes the guests staying in one room.
+type Room struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Adults        int32                  `protobuf:"varint,1,opt,name=adults,proto3" json:"adults,omitempty"`
+	ChildAges     []int32                `protobuf:"varint,2,rep,packed,name=child_ages,json=childAges,proto3" json:"child_ages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Room) Reset() {
+	*x = Room{}
+	mi := &file_hotel_search_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Room) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Room) ProtoMessage() {}
+
+func (x *Room) ProtoReflect() protoreflect.Message {
+	mi := &file_hotel_search_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Room.ProtoReflect.Descriptor instead.
+func (*Room) Descriptor() ([]byte, []int) {
+	return file_hotel_search_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Room) GetAdults() int32 {
+	if x != nil {
+		return x.Adults
+	}
+	return 0
+}
+
+func (x *Room) GetChildAges() []int32 {
+	if x != nil {
+		return x.ChildAges
+	}
+	return nil
+}
+
+type SearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hotels        []*Hotel               `protobuf:"bytes,1,rep,name=hotels,proto3" json:"hotels,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_hotel_search_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_hotel_search_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_hotel_search_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SearchResponse) GetHotels() []*Hotel {
+	if x != nil {
+		return x.Hotels
+	}
+	return nil
+}
+
+// Hotel is an offer. Price includes taxes and fees; all three are quoted on
+// price_basis. The fields after price_basis are optional content.
+type Hotel struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	HotelId       string                 `protobuf:"bytes,1,opt,name=hotel_id,json=hotelId,proto3" json:"hotel_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	City          string                 `protobuf:"bytes,3,opt,name=city,proto3" json:"city,omitempty"`
+	Currency      string                 `protobuf:"bytes,4,opt,name=currency,proto3" json:"currency,omitempty"`
+	Price         float64                `protobuf:"fixed64,5,opt,name=price,proto3" json:"price,omitempty"`
+	Taxes         float64                `protobuf:"fixed64,6,opt,name=taxes,proto3" json:"taxes,omitempty"`
+	Fees          float64                `protobuf:"fixed64,7,opt,name=fees,proto3" json:"fees,omitempty"`
+	Nights        int32                  `protobuf:"varint,8,opt,name=nights,proto3" json:"nights,omitempty"`
+	PriceBasis    PriceBasis             `protobuf:"varint,9,opt,name=price_basis,json=priceBasis,proto3,enum=hotels.search.v1.PriceBasis" json:"price_basis,omitempty"`
+	Address       string                 `protobuf:"bytes,10,opt,name=address,proto3" json:"address,omitempty"`
+	Stars         float64                `protobuf:"fixed64,11,opt,name=stars,proto3" json:"stars,omitempty"`
+	Location      *Location              `protobuf:"bytes,12,opt,name=location,proto3" json:"location,omitempty"`
+	Amenities     []string               `protobuf:"bytes,13,rep,name=amenities,proto3" json:"amenities,omitempty"`
+	Images        []string               `protobuf:"bytes,14,rep,name=images,proto3" json:"images,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Hotel) Reset() {
+	*x = Hotel{}
+	mi := &file_hotel_search_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Hotel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Hotel) ProtoMessage() {}
+
+func (x *Hotel) ProtoReflect() protoreflect.Message {
+	mi := &file_hotel_search_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Hotel.ProtoReflect.Descriptor instead.
+func (*Hotel) Descriptor() ([]byte, []int) {
+	return file_hotel_search_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Hotel) GetHotelId() string {
+	if x != nil {
+		return x.HotelId
+	}
+	return ""
+}
+
+func (x *Hotel) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Hotel) GetCity() string {
+	if x != nil {
+		return x.City
+	}
+	return ""
+}
+
+func (x *Hotel) GetCurrency() string {
+	if x != nil {
+		return x.Currency
+	}
+	return ""
+}
+
+func (x *Hotel) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *Hotel) GetTaxes() float64 {
+	if x != nil {
+		return x.Taxes
+	}
+	return 0
+}
+
+func (x *Hotel) GetFees() float64 {
+	if x != nil {
+		return x.Fees
+	}
+	return 0
+}
+
+func (x *Hotel) GetNights() int32 {
+	if x != nil {
+		return x.Nights
+	}
+	return 0
+}
+
+func (x *Hotel) GetPriceBasis() PriceBasis {
+	if x != nil {
+		return x.PriceBasis
+	}
+	return PriceBasis_PRICE_BASIS_UNSPECIFIED
+}
+
+func (x *Hotel) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Hotel) GetStars() float64 {
+	if x != nil {
+		return x.Stars
+	}
+	return 0
+}
+
+func (x *Hotel) GetLocation() *Location {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *Hotel) GetAmenities() []string {
+	if x != nil {
+		return x.Amenities
+	}
+	return nil
+}
+
+func (x *Hotel) GetImages() []string {
+	if x != nil {
+		return x.Images
+	}
+	return nil
+}
+
+type Location struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Latitude      float64                `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"`
+	Longitude     float64                `protobuf:"fixed64,2,opt,name=longitude,proto3" json:"longitude,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Location) Reset() {
+	*x = Location{}
+	mi := &file_hotel_search_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Location) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Location) ProtoMessage() {}
+
+func (x *Location) ProtoReflect() protoreflect.Message {
+	mi := &file_hotel_search_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Location.ProtoReflect.Descriptor instead.
+func (*Location) Descriptor() ([]byte, []int) {
+	return file_hotel_search_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Location) GetLatitude() float64 {
+	if x != nil {
+		return x.Latitude
+	}
+	return 0
+}
+
+func (x *Location) GetLongitude() float64 {
+	if x != nil {
+		return x.Longitude
+	}
+	return 0
+}
+
+var File_hotel_search_proto protoreflect.FileDescriptor
+
+const file_hotel_search_proto_rawDesc = "" +
+	"\n" +
+	"\x12hotel_search.proto\x12\x10hotels.search.v1\"\x83\x01\n" +
+	"\rSearchRequest\x12\x12\n" +
+	"\x04city\x18\x01 \x01(\tR\x04city\x12\x18\n" +
+	"\acheckin\x18\x02 \x01(\tR\acheckin\x12\x16\n" +
+	"\x06nights\x18\x03 \x01(\x05R\x06nights\x12,\n" +
+	"\x05rooms\x18\x04 \x03(\v2\x16.hotels.search.v1.RoomR\x05rooms\"=\n" +
+	"\x04Room\x12\x16\n" +
+	"\x06adults\x18\x01 \x01(\x05R\x06adults\x12\x1d\n" +
+	"\n" +
+	"child_ages\x18\x02 \x03(\x05R\tchildAges\"A\n" +
+	"\x0eSearchResponse\x12/\n" +
+	"\x06hotels\x18\x01 \x03(\v2\x17.hotels.search.v1.HotelR\x06hotels\"\x9b\x03\n" +
+	"\x05Hotel\x12\x19\n" +
+	"\bhotel_id\x18\x01 \x01(\tR\ahotelId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04city\x18\x03 \x01(\tR\x04city\x12\x1a\n" +
+	"\bcurrency\x18\x04 \x01(\tR\bcurrency\x12\x14\n" +
+	"\x05price\x18\x05 \x01(\x01R\x05price\x12\x14\n" +
+	"\x05taxes\x18\x06 \x01(\x01R\x05taxes\x12\x12\n" +
+	"\x04fees\x18\a \x01(\x01R\x04fees\x12\x16\n" +
+	"\x06nights\x18\b \x01(\x05R\x06nights\x12=\n" +
+	"\vprice_basis\x18\t \x01(\x0e2\x1c.hotels.search.v1.PriceBasisR\n" +
+	"priceBasis\x12\x18\n" +
+	"\aaddress\x18\n" +
+	" \x01(\tR\aaddress\x12\x14\n" +
+	"\x05stars\x18\v \x01(\x01R\x05stars\x126\n" +
+	"\blocation\x18\f \x01(\v2\x1a.hotels.search.v1.LocationR\blocation\x12\x1c\n" +
+	"\tamenities\x18\r \x03(\tR\tamenities\x12\x16\n" +
+	"\x06images\x18\x0e \x03(\tR\x06images\"D\n" +
+	"\bLocation\x12\x1a\n" +
+	"\blatitude\x18\x01 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x02 \x01(\x01R\tlongitude*^\n" +
+	"\n" +
+	"PriceBasis\x12\x1b\n" +
+	"\x17PRICE_BASIS_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14PRICE_BASIS_PER_STAY\x10\x01\x12\x19\n" +
+	"\x15PRICE_BASIS_PER_NIGHT\x10\x022Z\n" +
+	"\vHotelSearch\x12K\n" +
+	"\x06Search\x12\x1f.hotels.search.v1.SearchRequest\x1a .hotels.search.v1.SearchResponseB;Z9github.com/alex-user-go/hotels/internal/providers/hotelpbb\x06proto3"
+
+var (
+	file_hotel_search_proto_rawDescOnce sync.Once
+	file_hotel_search_proto_rawDescData []byte
+)
+
+func file_hotel_search_proto_rawDescGZIP() []byte {
+	file_hotel_search_proto_rawDescOnce.Do(func() {
+		file_hotel_search_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_hotel_search_proto_rawDesc), len(file_hotel_search_proto_rawDesc)))
+	})
+	return file_hotel_search_proto_rawDescData
+}
+
+var file_hotel_search_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_hotel_search_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_hotel_search_proto_goTypes = []any{
+	(PriceBasis)(0),        // 0: hotels.search.v1.PriceBasis
+	(*SearchRequest)(nil),  // 1: hotels.search.v1.SearchRequest
+	(*Room)(nil),           // 2: hotels.search.v1.Room
+	(*SearchResponse)(nil), // 3: hotels.search.v1.SearchResponse
+	(*Hotel)(nil),          // 4: hotels.search.v1.Hotel
+	(*Location)(nil),       // 5: hotels.search.v1.Location
+}
+var file_hotel_search_proto_depIdxs = []int32{
+	2, // 0: hotels.search.v1.SearchRequest.rooms:type_name -> hotels.search.v1.Room
+	4, // 1: hotels.search.v1.SearchResponse.hotels:type_name -> hotels.search.v1.Hotel
+	0, // 2: hotels.search.v1.Hotel.price_basis:type_name -> hotels.search.v1.PriceBasis
+	5, // 3: hotels.search.v1.Hotel.location:type_name -> hotels.search.v1.Location
+	1, // 4: hotels.search.v1.HotelSearch.Search:input_type -> hotels.search.v1.SearchRequest
+	3, // 5: hotels.search.v1.HotelSearch.Search:output_type -> hotels.search.v1.SearchResponse
+	5, // [5:6] is the sub-list for method output_type
+	4, // [4:5] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_hotel_search_proto_init() }
+func file_hotel_search_proto_init() {
+	if File_hotel_search_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_hotel_search_proto_rawDesc), len(file_hotel_search_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_hotel_search_proto_goTypes,
+		DependencyIndexes: file_hotel_search_proto_depIdxs,
+		EnumInfos:         file_hotel_search_proto_enumTypes,
+		MessageInfos:      file_hotel_search_proto_msgTypes,
+	}.Build()
+	File_hotel_search_proto = out.File
+	file_hotel_search_proto_goTypes = nil
+	file_hotel_search_proto_depIdxs = nil
+}
@@ -0,0 +1,131 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: hotel_search.proto
+
+// Hotel search API for partners that expose gRPC instead of HTTP/JSON.
+
+package hotelpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	HotelSearch_Search_FullMethodName = "/hotels.search.v1.HotelSearch/Search"
+)
+
+// HotelSearchClient is the client API for HotelSearch service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// HotelSearch returns the offers a partner has for a stay.
+type HotelSearchClient interface {
+	// Search returns the available hotels in a city. An unknown city is not
+	// an error; it has no hotels.
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+}
+
+type hotelSearchClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewHotelSearchClient(cc grpc.ClientConnInterface) HotelSearchClient {
+	return &hotelSearchClient{cc}
+}
+
+func (c *hotelSearchClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, HotelSearch_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// HotelSearchServer is the server API for HotelSearch service.
+// All implementations must embed UnimplementedHotelSearchServer
+// for forward compatibility.
+//
+// HotelSearch returns the offers a partner has for a stay.
+type HotelSearchServer interface {
+	// Search returns the available hotels in a city. An unknown city is not
+	// an error; it has no hotels.
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	mustEmbedUnimplementedHotelSearchServer()
+}
+
+// UnimplementedHotelSearchServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedHotelSearchServer struct{}
+
+func (UnimplementedHotelSearchServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedHotelSearchServer) mustEmbedUnimplementedHotelSearchServer() {}
+func (UnimplementedHotelSearchServer) testEmbeddedByValue()                     {}
+
+// UnsafeHotelSearchServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to HotelSearchServer will
+// result in compilation errors.
+type UnsafeHotelSearchServer interface {
+	mustEmbedUnimplementedHotelSearchServer()
+}
+
+func RegisterHotelSearchServer(s grpc.ServiceRegistrar, srv HotelSearchServer) {
+	// If the following call pancis, it indicates UnimplementedHotelSearchServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&HotelSearch_ServiceDesc, srv)
+}
+
+func _HotelSearch_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HotelSearchServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HotelSearch_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HotelSearchServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// HotelSearch_ServiceDesc is the grpc.ServiceDesc for HotelSearch service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var HotelSearch_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "hotels.search.v1.HotelSearch",
+	HandlerType: (*HotelSearchServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Search",
+			Handler:    _HotelSearch_Search_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "hotel_search.proto",
+}
@@ -2,6 +2,7 @@ package providers
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,31 +11,107 @@ import (
 	"time"
 )
 
+// maxErrorBody is how much of an error response is kept in the error.
+const maxErrorBody = 1 << 10
+
 // HTTPProvider queries a real HTTP endpoint for hotel data.
 type HTTPProvider struct {
 	name       string
 	baseURL    string
+	priceBasis PriceBasis
+	schema     *Schema
+	auth       Authenticator
 	httpClient *http.Client
+
+	tlsConfig        *tls.Config
+	transport        *TransportConfig
+	maxResponseBytes int64
+}
+
+// HTTPOption configures an HTTPProvider.
+type HTTPOption func(*HTTPProvider)
+
+// WithPriceBasis declares what the provider's prices cover when its hotels
+// don't say so themselves. Defaults to PricePerStay.
+func WithPriceBasis(basis PriceBasis) HTTPOption {
+	return func(p *HTTPProvider) {
+		p.priceBasis = basis
+	}
 }
 
-// NewHTTPProvider creates a new HTTPProvider.
-func NewHTTPProvider(name, baseURL string, timeout time.Duration) *HTTPProvider {
-	return &HTTPProvider{
-		name:    name,
-		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: timeout,
-		},
+// WithSchema sets the schema used to read the provider's responses. Without
+// one, responses must be a JSON array of Hotel.
+func WithSchema(schema *Schema) HTTPOption {
+	return func(p *HTTPProvider) {
+		p.schema = schema
 	}
 }
 
+// WithAuthenticator sets how the provider's requests are authenticated.
+func WithAuthenticator(auth Authenticator) HTTPOption {
+	return func(p *HTTPProvider) {
+		p.auth = auth
+	}
+}
+
+// WithTLSConfig sets the TLS configuration of connections to the provider,
+// such as a client certificate for mutual TLS.
+func WithTLSConfig(cfg *tls.Config) HTTPOption {
+	return func(p *HTTPProvider) {
+		p.tlsConfig = cfg
+	}
+}
+
+// WithTransport tunes the connections to the provider. The TLS minimum
+// version and CA bundle in cfg override those of WithTLSConfig.
+func WithTransport(cfg TransportConfig) HTTPOption {
+	return func(p *HTTPProvider) {
+		p.transport = &cfg
+	}
+}
+
+// WithMaxResponseBytes limits the size of the provider's responses, once
+// decompressed; zero or less allows any size. Defaults to
+// DefaultMaxResponseBytes.
+func WithMaxResponseBytes(n int64) HTTPOption {
+	return func(p *HTTPProvider) {
+		p.maxResponseBytes = n
+	}
+}
+
+// NewHTTPProvider creates a new HTTPProvider. Each provider has its own
+// connection pool.
+func NewHTTPProvider(name, baseURL string, timeout time.Duration, opts ...HTTPOption) *HTTPProvider {
+	p := &HTTPProvider{
+		name:             name,
+		baseURL:          baseURL,
+		priceBasis:       PricePerStay,
+		maxResponseBytes: DefaultMaxResponseBytes,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.httpClient = &http.Client{
+		Timeout:   timeout,
+		Transport: newTransport(p.transport, p.tlsConfig),
+	}
+	// Token requests need the same proxy, CA bundle and client certificate
+	if a, ok := p.auth.(interface{ setTransport(http.RoundTripper) }); ok {
+		a.setTransport(p.httpClient.Transport)
+	}
+	return p
+}
+
 // Name returns the provider name.
 func (p *HTTPProvider) Name() string {
 	return p.name
 }
 
 // Search searches for hotels by making an HTTP GET request.
-func (p *HTTPProvider) Search(ctx context.Context, city, checkin string, nights, adults int) ([]Hotel, error) {
+// The total number of adults is always sent as "adults" for providers that
+// don't understand multi-room occupancy; the full occupancy is sent as
+// "occupancy" in its string form.
+func (p *HTTPProvider) Search(ctx context.Context, city, checkin string, nights int, occupancy Occupancy) ([]Hotel, error) {
 	// Build URL with query parameters
 	u, err := url.Parse(p.baseURL + "/search")
 	if err != nil {
@@ -45,7 +122,9 @@ func (p *HTTPProvider) Search(ctx context.Context, city, checkin string, nights,
 	q.Set("city", city)
 	q.Set("checkin", checkin)
 	q.Set("nights", fmt.Sprintf("%d", nights))
-	q.Set("adults", fmt.Sprintf("%d", adults))
+	q.Set("adults", fmt.Sprintf("%d", occupancy.Adults()))
+	q.Set("rooms", fmt.Sprintf("%d", occupancy.Rooms()))
+	q.Set("occupancy", occupancy.String())
 	u.RawQuery = q.Encode()
 
 	// Create request with context
@@ -53,6 +132,12 @@ func (p *HTTPProvider) Search(ctx context.Context, city, checkin string, nights,
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	if p.auth != nil {
+		if err := p.auth.Authenticate(req); err != nil {
+			return nil, fmt.Errorf("failed to authenticate request: %w", err)
+		}
+	}
 
 	// Execute request
 	resp, err := p.httpClient.Do(req)
@@ -63,17 +148,62 @@ func (p *HTTPProvider) Search(ctx context.Context, city, checkin string, nights,
 		_ = resp.Body.Close() // Explicitly ignore close error
 	}()
 
-	// Check status code
+	// Responses are decoded here, since setting Accept-Encoding stops the
+	// transport from decoding gzip itself.
+	body, err := decodeBody(resp, p.maxResponseBytes)
+	if err == nil {
+		defer func() {
+			_ = body.Close() // Explicitly ignore close error
+		}()
+	}
+
+	// Check status code. Rejected credentials won't work on retry; a cached
+	// token is dropped so that the next request gets a new one. Only the
+	// start of an error body is kept, as sent if it can't be decoded.
 	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("provider returned status %d: %s", resp.StatusCode, string(body))
+		if err != nil {
+			body = resp.Body
+		}
+		msg, _ := io.ReadAll(io.LimitReader(body, maxErrorBody))
+		err := fmt.Errorf("provider returned status %d: %s", resp.StatusCode, string(msg))
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			if inv, ok := p.auth.(interface{ Invalidate() }); ok && resp.StatusCode == http.StatusUnauthorized {
+				inv.Invalidate()
+			}
+			return nil, fmt.Errorf("%w: %w", ErrProviderRejected, err)
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
 	// Parse JSON response
-	var hotels []Hotel
-	if err := json.NewDecoder(resp.Body).Decode(&hotels); err != nil {
+	hotels, err := p.decode(body)
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
 
+	for i := range hotels {
+		if hotels[i].PriceBasis == "" {
+			hotels[i].PriceBasis = p.priceBasis
+		}
+	}
+
 	return hotels, nil
 }
+
+// decode reads the hotels from a JSON response body.
+func (p *HTTPProvider) decode(body io.Reader) ([]Hotel, error) {
+	if p.schema == nil {
+		var hotels []Hotel
+		err := json.NewDecoder(body).Decode(&hotels)
+		return hotels, err
+	}
+
+	var doc any
+	if err := json.NewDecoder(body).Decode(&doc); err != nil {
+		return nil, err
+	}
+	return p.schema.Extract(doc)
+}
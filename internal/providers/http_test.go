@@ -0,0 +1,88 @@
+package providers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/providers"
+)
+
+func TestHTTPProvider_Search_QueryEncoding(t *testing.T) {
+	var got url.Values
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.URL.Query()
+		_ = json.NewEncoder(w).Encode([]providers.Hotel{
+			{HotelID: "H001", Name: "Hotel A", Currency: "EUR", Price: 100},
+		})
+	}))
+	defer srv.Close()
+
+	p := providers.NewHTTPProvider("test", srv.URL, time.Second)
+	occ := providers.Occupancy{
+		{Adults: 2, ChildAges: []int{5, 8}},
+		{Adults: 1},
+	}
+
+	hotels, err := p.Search(context.Background(), "paris", "2026-12-01", 2, occ)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(hotels) != 1 {
+		t.Fatalf("expected 1 hotel, got %d", len(hotels))
+	}
+
+	want := map[string]string{
+		"city":      "paris",
+		"checkin":   "2026-12-01",
+		"nights":    "2",
+		"adults":    "3",
+		"rooms":     "2",
+		"occupancy": "2-5,8|1",
+	}
+	for key, value := range want {
+		if got.Get(key) != value {
+			t.Errorf("query %s = %q, want %q", key, got.Get(key), value)
+		}
+	}
+}
+
+func TestHTTPProvider_Search_ErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	p := providers.NewHTTPProvider("test", srv.URL, time.Second)
+	if _, err := p.Search(context.Background(), "paris", "2026-12-01", 2, providers.SingleRoom(2)); err == nil {
+		t.Fatal("expected error for non-200 status, got nil")
+	}
+}
+
+func TestHTTPProvider_Search_PriceBasis(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"hotel_id": "H001", "name": "Hotel A", "currency": "EUR", "price": 100},
+			{"hotel_id": "H002", "name": "Hotel B", "currency": "EUR", "price": 300, "price_basis": "per_stay"}
+		]`))
+	}))
+	defer srv.Close()
+
+	p := providers.NewHTTPProvider("test", srv.URL, time.Second, providers.WithPriceBasis(providers.PricePerNight))
+	hotels, err := p.Search(context.Background(), "paris", "2026-12-01", 3, providers.SingleRoom(2))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The declared basis applies only where the payload doesn't state one
+	if hotels[0].PriceBasis != providers.PricePerNight {
+		t.Errorf("hotels[0].price_basis = %q, want %q", hotels[0].PriceBasis, providers.PricePerNight)
+	}
+	if hotels[1].PriceBasis != providers.PricePerStay {
+		t.Errorf("hotels[1].price_basis = %q, want %q", hotels[1].PriceBasis, providers.PricePerStay)
+	}
+}
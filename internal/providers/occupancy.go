@@ -0,0 +1,156 @@
+package providers
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// MaxChildAge is the oldest age that still counts as a child.
+const MaxChildAge = 17
+
+// Room describes the guests staying in one room.
+type Room struct {
+	Adults    int   `json:"adults"`
+	ChildAges []int `json:"child_ages,omitempty"`
+}
+
+// Occupancy describes the guests of a search, one entry per room.
+//
+// Its string form lists rooms separated by "|"; each room is the number of
+// adults optionally followed by "-" and comma-separated child ages, e.g.
+// "2-5,8|1" is two rooms: two adults with children aged 5 and 8, and one adult.
+type Occupancy []Room
+
+// SingleRoom returns an occupancy of one room with the given number of adults.
+func SingleRoom(adults int) Occupancy {
+	return Occupancy{{Adults: adults}}
+}
+
+// Rooms returns the number of rooms.
+func (o Occupancy) Rooms() int {
+	return len(o)
+}
+
+// Adults returns the total number of adults across all rooms.
+func (o Occupancy) Adults() int {
+	total := 0
+	for _, r := range o {
+		total += r.Adults
+	}
+	return total
+}
+
+// Children returns the total number of children across all rooms.
+func (o Occupancy) Children() int {
+	total := 0
+	for _, r := range o {
+		total += len(r.ChildAges)
+	}
+	return total
+}
+
+// String returns the string form of the occupancy, with rooms and child ages
+// in the order given. A single room with adults only encodes as just the
+// number of adults.
+func (o Occupancy) String() string {
+	rooms := make([]string, len(o))
+	for i, r := range o {
+		var b strings.Builder
+		b.WriteString(strconv.Itoa(r.Adults))
+		for j, age := range r.ChildAges {
+			if j == 0 {
+				b.WriteByte('-')
+			} else {
+				b.WriteByte(',')
+			}
+			b.WriteString(strconv.Itoa(age))
+		}
+		rooms[i] = b.String()
+	}
+	return strings.Join(rooms, "|")
+}
+
+// Canonical returns a copy of the occupancy with the child ages of each room
+// sorted, and the rooms sorted by adults then child ages, so that the same
+// guests always encode the same way.
+func (o Occupancy) Canonical() Occupancy {
+	rooms := make(Occupancy, len(o))
+	for i, r := range o {
+		rooms[i] = Room{Adults: r.Adults, ChildAges: slices.Sorted(slices.Values(r.ChildAges))}
+	}
+	slices.SortFunc(rooms, func(a, b Room) int {
+		if c := cmp.Compare(a.Adults, b.Adults); c != 0 {
+			return c
+		}
+		return slices.Compare(a.ChildAges, b.ChildAges)
+	})
+	return rooms
+}
+
+// Validate checks that every room has at least one adult and that child
+// ages are within range.
+func (o Occupancy) Validate() error {
+	if len(o) == 0 {
+		return errors.New("at least one room is required")
+	}
+	for i, r := range o {
+		if r.Adults <= 0 {
+			return fmt.Errorf("room %d must have at least one adult", i+1)
+		}
+		for _, age := range r.ChildAges {
+			if age < 0 || age > MaxChildAge {
+				return fmt.Errorf("room %d has child age %d outside 0-%d", i+1, age, MaxChildAge)
+			}
+		}
+	}
+	return nil
+}
+
+// ParseOccupancy parses the string form produced by Occupancy.String.
+func ParseOccupancy(s string) (Occupancy, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, errors.New("empty occupancy")
+	}
+
+	parts := strings.Split(s, "|")
+	occ := make(Occupancy, 0, len(parts))
+	for _, part := range parts {
+		adultsStr, agesStr, hasChildren := strings.Cut(strings.TrimSpace(part), "-")
+
+		adults, err := strconv.Atoi(adultsStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid adults %q", adultsStr)
+		}
+
+		room := Room{Adults: adults}
+		if hasChildren {
+			ages, err := ParseChildAges(agesStr)
+			if err != nil {
+				return nil, err
+			}
+			room.ChildAges = ages
+		}
+		occ = append(occ, room)
+	}
+
+	return occ, occ.Validate()
+}
+
+// ParseChildAges parses a comma-separated list of child ages.
+func ParseChildAges(s string) ([]int, error) {
+	fields := strings.Split(s, ",")
+	ages := make([]int, 0, len(fields))
+	for _, f := range fields {
+		age, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil {
+			return nil, fmt.Errorf("invalid child age %q", f)
+		}
+		ages = append(ages, age)
+	}
+	return ages, nil
+}
@@ -0,0 +1,102 @@
+package providers_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alex-user-go/hotels/internal/providers"
+)
+
+func TestParseOccupancy(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    providers.Occupancy
+		wantErr bool
+	}{
+		{
+			name:  "single room adults only",
+			input: "2",
+			want:  providers.SingleRoom(2),
+		},
+		{
+			name:  "rooms with children",
+			input: "2-5,8|1",
+			want: providers.Occupancy{
+				{Adults: 2, ChildAges: []int{5, 8}},
+				{Adults: 1},
+			},
+		},
+		{name: "empty", input: "", wantErr: true},
+		{name: "non-integer adults", input: "two", wantErr: true},
+		{name: "room without adults", input: "2|0-4", wantErr: true},
+		{name: "child too old", input: "2-18", wantErr: true},
+		{name: "invalid child age", input: "2-x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := providers.ParseOccupancy(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseOccupancy() = %+v, want %+v", got, tt.want)
+			}
+			if got.String() != tt.input {
+				t.Errorf("String() = %q, want %q", got.String(), tt.input)
+			}
+		})
+	}
+}
+
+func TestOccupancy_Totals(t *testing.T) {
+	occ := providers.Occupancy{
+		{Adults: 2, ChildAges: []int{5, 8}},
+		{Adults: 1, ChildAges: []int{0}},
+	}
+
+	if occ.Rooms() != 2 {
+		t.Errorf("Rooms() = %d, want 2", occ.Rooms())
+	}
+	if occ.Adults() != 3 {
+		t.Errorf("Adults() = %d, want 3", occ.Adults())
+	}
+	if occ.Children() != 3 {
+		t.Errorf("Children() = %d, want 3", occ.Children())
+	}
+}
+
+func TestOccupancy_Canonical(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "single room", input: "2", want: "2"},
+		{name: "child ages", input: "2-8,5", want: "2-5,8"},
+		{name: "rooms", input: "2-5|1", want: "1|2-5"},
+		{name: "rooms with the same adults", input: "2-9|2-3,1", want: "2-1,3|2-9"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			occ, err := providers.ParseOccupancy(tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := occ.Canonical().String(); got != tt.want {
+				t.Errorf("Canonical() = %q, want %q", got, tt.want)
+			}
+			if occ.String() != tt.input {
+				t.Errorf("Canonical() modified the occupancy: %q", occ.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package providers
+
+import "fmt"
+
+// PriceBasis describes what a provider's Price, Taxes and Fees cover.
+type PriceBasis string
+
+const (
+	// PricePerStay means amounts cover the whole stay.
+	PricePerStay PriceBasis = "per_stay"
+	// PricePerNight means amounts cover a single night.
+	PricePerNight PriceBasis = "per_night"
+)
+
+// Valid reports whether b is a known price basis.
+func (b PriceBasis) Valid() bool {
+	return b == PricePerStay || b == PricePerNight
+}
+
+// ParsePriceBasis parses a price basis name.
+func ParsePriceBasis(s string) (PriceBasis, error) {
+	b := PriceBasis(s)
+	if !b.Valid() {
+		return "", fmt.Errorf("invalid price basis %q: must be %s or %s", s, PricePerStay, PricePerNight)
+	}
+	return b, nil
+}
@@ -6,20 +6,40 @@ import (
 )
 
 // Hotel represents a hotel from a provider.
+// Price includes Taxes and Fees, which are optional; all three are quoted on
+// PriceBasis, which defaults to the basis declared for the provider.
+// The content fields after PriceBasis are optional; providers that send them
+// fill gaps in the hotel content catalog.
 type Hotel struct {
-	HotelID  string  `json:"hotel_id"`
-	Name     string  `json:"name"`
-	City     string  `json:"city"`
-	Currency string  `json:"currency"`
-	Price    float64 `json:"price"`
-	Nights   int     `json:"nights"`
+	HotelID    string     `json:"hotel_id"`
+	Name       string     `json:"name"`
+	City       string     `json:"city"`
+	Currency   string     `json:"currency"`
+	Price      float64    `json:"price"`
+	Taxes      float64    `json:"taxes,omitempty"`
+	Fees       float64    `json:"fees,omitempty"`
+	Nights     int        `json:"nights"`
+	PriceBasis PriceBasis `json:"price_basis,omitempty"`
+	Address    string     `json:"address,omitempty"`
+	Stars      float64    `json:"stars,omitempty"`
+	Latitude   *float64   `json:"latitude,omitempty"`
+	Longitude  *float64   `json:"longitude,omitempty"`
+	Amenities  []string   `json:"amenities,omitempty"`
+	Images     []string   `json:"images,omitempty"`
 }
 
 // Provider defines the interface for hotel providers.
 type Provider interface {
+	// Name returns the provider name.
+	Name() string
+
 	// Search searches for hotels.
-	Search(ctx context.Context, city, checkin string, nights, adults int) ([]Hotel, error)
+	Search(ctx context.Context, city, checkin string, nights int, occupancy Occupancy) ([]Hotel, error)
 }
 
 // ErrProviderUnavailable is returned when a provider is unavailable.
 var ErrProviderUnavailable = errors.New("provider unavailable")
+
+// ErrProviderRejected is returned when a provider refuses a request, so that
+// sending it again won't help.
+var ErrProviderRejected = errors.New("provider rejected request")
@@ -0,0 +1,234 @@
+package providers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Schema maps a provider's response document to hotels. Each field is a
+// path of dot-separated keys; numeric segments index into lists. Hotels is
+// the path to the list of hotels from the document root, empty if the root
+// is the list; every other path is relative to a hotel in that list. Only
+// HotelID, Name and Price are required; unmapped fields stay empty.
+//
+// Values are converted leniently: numbers may be sent as strings, and list
+// fields accept a single value. Hotels with missing or malformed values are
+// still returned so that normalization can reject them. A path through a
+// null node resolves to null, so an empty list may be sent as null.
+type Schema struct {
+	Hotels     string `json:"hotels"`
+	HotelID    string `json:"hotel_id"`
+	Name       string `json:"name"`
+	City       string `json:"city,omitempty"`
+	Currency   string `json:"currency,omitempty"`
+	Price      string `json:"price"`
+	Taxes      string `json:"taxes,omitempty"`
+	Fees       string `json:"fees,omitempty"`
+	Nights     string `json:"nights,omitempty"`
+	PriceBasis string `json:"price_basis,omitempty"`
+	Address    string `json:"address,omitempty"`
+	Stars      string `json:"stars,omitempty"`
+	Latitude   string `json:"latitude,omitempty"`
+	Longitude  string `json:"longitude,omitempty"`
+	Amenities  string `json:"amenities,omitempty"`
+	Images     string `json:"images,omitempty"`
+}
+
+// ErrSchemaMismatch is returned when a response document doesn't have the
+// shape a schema expects.
+var ErrSchemaMismatch = errors.New("response does not match schema")
+
+// LoadSchema reads a schema file.
+func LoadSchema(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file: %w", err)
+	}
+
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse schema file: %w", err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// Validate checks that the required fields are mapped.
+func (s *Schema) Validate() error {
+	var missing []string
+	if strings.TrimSpace(s.HotelID) == "" {
+		missing = append(missing, "hotel_id")
+	}
+	if strings.TrimSpace(s.Name) == "" {
+		missing = append(missing, "name")
+	}
+	if strings.TrimSpace(s.Price) == "" {
+		missing = append(missing, "price")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("schema must map %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Extract reads the hotels from a decoded document: a tree of
+// map[string]any, []any and scalar values, as produced by decoding JSON into
+// an any.
+func (s *Schema) Extract(doc any) ([]Hotel, error) {
+	list, ok := lookup(doc, s.Hotels)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q not found", ErrSchemaMismatch, s.Hotels)
+	}
+	if list == nil {
+		return nil, nil
+	}
+	items, ok := list.([]any)
+	if !ok {
+		// A single hotel object counts as a list of one
+		if _, isObject := list.(map[string]any); !isObject {
+			return nil, fmt.Errorf("%w: %q is not a list", ErrSchemaMismatch, s.Hotels)
+		}
+		items = []any{list}
+	}
+
+	hotels := make([]Hotel, 0, len(items))
+	for _, item := range items {
+		h := Hotel{
+			HotelID:    s.text(item, s.HotelID),
+			Name:       s.text(item, s.Name),
+			City:       s.text(item, s.City),
+			Currency:   s.text(item, s.Currency),
+			Price:      s.number(item, s.Price),
+			Taxes:      s.number(item, s.Taxes),
+			Fees:       s.number(item, s.Fees),
+			Nights:     int(s.number(item, s.Nights)),
+			PriceBasis: PriceBasis(s.text(item, s.PriceBasis)),
+			Address:    s.text(item, s.Address),
+			Stars:      s.number(item, s.Stars),
+			Latitude:   s.optionalNumber(item, s.Latitude),
+			Longitude:  s.optionalNumber(item, s.Longitude),
+			Amenities:  s.texts(item, s.Amenities),
+			Images:     s.texts(item, s.Images),
+		}
+		hotels = append(hotels, h)
+	}
+	return hotels, nil
+}
+
+// text returns the value at path as a string, or "" if it is missing or
+// not a scalar.
+func (s *Schema) text(item any, path string) string {
+	if path == "" {
+		return ""
+	}
+	v, _ := lookup(item, path)
+	return scalarText(v)
+}
+
+// number returns the value at path as a number, or 0 if it is missing or
+// not numeric.
+func (s *Schema) number(item any, path string) float64 {
+	if n := s.optionalNumber(item, path); n != nil {
+		return *n
+	}
+	return 0
+}
+
+// optionalNumber returns the value at path as a number, or nil if it is
+// missing or not numeric.
+func (s *Schema) optionalNumber(item any, path string) *float64 {
+	if path == "" {
+		return nil
+	}
+	v, _ := lookup(item, path)
+	switch v := textValue(v).(type) {
+	case float64:
+		return &v
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return &f
+		}
+	}
+	return nil
+}
+
+// texts returns the values at path as strings. A single value is a list of one.
+func (s *Schema) texts(item any, path string) []string {
+	if path == "" {
+		return nil
+	}
+	v, ok := lookup(item, path)
+	if !ok {
+		return nil
+	}
+	values, isList := v.([]any)
+	if !isList {
+		values = []any{v}
+	}
+	var texts []string
+	for _, value := range values {
+		if t := scalarText(value); t != "" {
+			texts = append(texts, t)
+		}
+	}
+	return texts
+}
+
+// scalarText formats a scalar value as a string.
+func scalarText(v any) string {
+	switch v := textValue(v).(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return ""
+}
+
+// textValue returns the text of an XML element that has attributes, which
+// decodes to a node holding the text under xmlTextKey. Other values are
+// returned as they are.
+func textValue(v any) any {
+	if node, ok := v.(map[string]any); ok {
+		return node[xmlTextKey]
+	}
+	return v
+}
+
+// lookup follows a dot-separated path from v. An empty path returns v, and
+// a path through a null node returns null.
+func lookup(v any, path string) (any, bool) {
+	if path == "" {
+		return v, true
+	}
+	for key := range strings.SplitSeq(path, ".") {
+		switch node := v.(type) {
+		case nil:
+			return nil, true
+		case map[string]any:
+			next, ok := node[key]
+			if !ok {
+				return nil, false
+			}
+			v = next
+		case []any:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return v, true
+}
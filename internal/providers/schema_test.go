@@ -0,0 +1,140 @@
+package providers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/providers"
+)
+
+const nestedResponse = `{
+	"status": "OK",
+	"data": {
+		"results": [
+			{
+				"property": {"code": "P-1", "title": "Grand Hotel", "rating": "5", "facilities": ["spa", "pool"], "geo": [48.87, 2.33]},
+				"offer": {"total": {"amount": "312.50", "currency": "GBP"}, "tax": 52.08, "stay_nights": 2}
+			},
+			{
+				"property": {"code": "P-2", "title": "Budget Stay", "facilities": "wifi"},
+				"offer": {"total": {"amount": "n/a", "currency": "GBP"}}
+			}
+		]
+	}
+}`
+
+var nestedSchema = providers.Schema{
+	Hotels:    "data.results",
+	HotelID:   "property.code",
+	Name:      "property.title",
+	Stars:     "property.rating",
+	Amenities: "property.facilities",
+	Latitude:  "property.geo.0",
+	Longitude: "property.geo.1",
+	Currency:  "offer.total.currency",
+	Price:     "offer.total.amount",
+	Taxes:     "offer.tax",
+	Nights:    "offer.stay_nights",
+}
+
+func TestHTTPProvider_Search_Schema(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(nestedResponse))
+	}))
+	defer srv.Close()
+
+	schema := nestedSchema
+	p := providers.NewHTTPProvider("test", srv.URL, time.Second, providers.WithSchema(&schema))
+	hotels, err := p.Search(context.Background(), "paris", "2026-12-01", 2, providers.SingleRoom(2))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(hotels) != 2 {
+		t.Fatalf("expected 2 hotels, got %d", len(hotels))
+	}
+
+	h := hotels[0]
+	if h.HotelID != "P-1" || h.Name != "Grand Hotel" || h.Currency != "GBP" || h.Price != 312.5 || h.Taxes != 52.08 || h.Nights != 2 {
+		t.Errorf("unexpected hotel: %+v", h)
+	}
+	if h.Stars != 5 || !slices.Equal(h.Amenities, []string{"spa", "pool"}) {
+		t.Errorf("unexpected content: stars %v, amenities %v", h.Stars, h.Amenities)
+	}
+	if h.Latitude == nil || *h.Latitude != 48.87 || h.Longitude == nil || *h.Longitude != 2.33 {
+		t.Errorf("unexpected location: %v, %v", h.Latitude, h.Longitude)
+	}
+	if h.PriceBasis != providers.PricePerStay {
+		t.Errorf("price basis = %q, want the provider default", h.PriceBasis)
+	}
+
+	// Malformed values are left for normalization to reject
+	if h := hotels[1]; h.Price != 0 || h.Latitude != nil || !slices.Equal(h.Amenities, []string{"wifi"}) {
+		t.Errorf("unexpected lenient values: %+v", h)
+	}
+}
+
+func TestSchema_Extract(t *testing.T) {
+	schema := providers.Schema{Hotels: "data.results", HotelID: "id", Name: "name", Price: "price"}
+
+	tests := []struct {
+		name    string
+		doc     any
+		want    int
+		wantErr bool
+	}{
+		{name: "list", doc: map[string]any{"data": map[string]any{"results": []any{map[string]any{"id": "H1"}}}}, want: 1},
+		{name: "single object", doc: map[string]any{"data": map[string]any{"results": map[string]any{"id": "H1"}}}, want: 1},
+		{name: "null list", doc: map[string]any{"data": map[string]any{"results": nil}}, want: 0},
+		{name: "null parent", doc: map[string]any{"data": nil}, want: 0},
+		{name: "missing list", doc: map[string]any{"error": "bad request"}, wantErr: true},
+		{name: "not a list", doc: map[string]any{"data": map[string]any{"results": "none"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hotels, err := schema.Extract(tt.doc)
+			if tt.wantErr {
+				if !errors.Is(err, providers.ErrSchemaMismatch) {
+					t.Fatalf("error = %v, want ErrSchemaMismatch", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(hotels) != tt.want {
+				t.Errorf("got %d hotels, want %d", len(hotels), tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadSchema(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	schema, err := providers.LoadSchema(write("ok.json", `{"hotels": "items", "hotel_id": "id", "name": "title", "price": "rate.total"}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if schema.Hotels != "items" || schema.Price != "rate.total" {
+		t.Errorf("unexpected schema: %+v", schema)
+	}
+
+	if _, err := providers.LoadSchema(write("partial.json", `{"hotel_id": "id"}`)); err == nil {
+		t.Error("expected error for a schema without name and price")
+	}
+}
@@ -0,0 +1,387 @@
+package providers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SnapshotProvider serves hotels from local snapshot files instead of a
+// remote supplier, for demos, offline development and as a last-resort
+// fallback. Its path is a snapshot file or a directory of them; files other
+// than .json and .csv in a directory are ignored.
+//
+// Each snapshot record is a hotel offer for a city and check-in date. A
+// record without a date is offered for any date the city has no dated
+// records for. A per-stay record with nights is only offered for stays of
+// that length, since its price wouldn't cover any other. JSON files hold a list of Hotel objects with an extra "date"
+// field. CSV files have a header row naming the columns, which use the same
+// names as the JSON fields; list fields are separated by "|".
+//
+// The files are reloaded when they change. If a reload fails, the previous
+// snapshot is kept.
+type SnapshotProvider struct {
+	name       string
+	path       string
+	interval   time.Duration
+	priceBasis PriceBasis
+	logger     *slog.Logger
+	done       chan struct{}
+
+	mu      sync.RWMutex
+	offers  map[snapshotKey][]Hotel
+	version string
+}
+
+// snapshotKey identifies the offers for a city, normalized, on a check-in
+// date. An empty date matches any date.
+type snapshotKey struct {
+	city, date string
+}
+
+// snapshotRecord is a hotel in a JSON snapshot file.
+type snapshotRecord struct {
+	Date string `json:"date,omitempty"`
+	Hotel
+}
+
+// SnapshotOption configures a SnapshotProvider.
+type SnapshotOption func(*SnapshotProvider)
+
+// WithSnapshotPriceBasis declares what snapshot prices cover when records
+// don't say so themselves. Defaults to PricePerNight, so that a snapshot
+// serves stays of any length.
+func WithSnapshotPriceBasis(basis PriceBasis) SnapshotOption {
+	return func(p *SnapshotProvider) {
+		p.priceBasis = basis
+	}
+}
+
+// NewSnapshotProvider creates a SnapshotProvider and loads its snapshot.
+// The files are checked for changes every interval; zero disables reloads.
+func NewSnapshotProvider(name, path string, interval time.Duration, logger *slog.Logger, opts ...SnapshotOption) (*SnapshotProvider, error) {
+	p := &SnapshotProvider{
+		name:       name,
+		path:       path,
+		interval:   interval,
+		priceBasis: PricePerNight,
+		logger:     logger,
+		done:       make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	if err := p.Reload(); err != nil {
+		return nil, err
+	}
+
+	// Start background reload
+	if interval > 0 {
+		go p.reloadLoop()
+	}
+
+	return p, nil
+}
+
+// Name returns the provider name.
+func (p *SnapshotProvider) Name() string {
+	return p.name
+}
+
+// Close stops reloading the snapshot.
+func (p *SnapshotProvider) Close() error {
+	close(p.done)
+	return nil
+}
+
+// Search returns the snapshot offers for the city, check-in date and stay
+// length. The occupancy is not taken into account.
+func (p *SnapshotProvider) Search(ctx context.Context, city, checkin string, nights int, _ Occupancy) ([]Hotel, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, context.Cause(ctx)
+	}
+
+	city = normalizeSnapshotCity(city)
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	offers := p.match(snapshotKey{city: city, date: checkin}, nights)
+	if len(offers) == 0 {
+		offers = p.match(snapshotKey{city: city}, nights)
+	}
+	return offers, nil
+}
+
+// match returns a copy of the offers for key that can be quoted for a stay
+// of nights. Must be called with p.mu held.
+func (p *SnapshotProvider) match(key snapshotKey, nights int) []Hotel {
+	return slices.DeleteFunc(slices.Clone(p.offers[key]), func(h Hotel) bool {
+		return h.PriceBasis == PricePerStay && h.Nights > 0 && h.Nights != nights
+	})
+}
+
+// Reload reads the snapshot files.
+func (p *SnapshotProvider) Reload() error {
+	files, version, err := snapshotFiles(p.path)
+	if err != nil {
+		return err
+	}
+
+	offers := make(map[snapshotKey][]Hotel)
+	for _, file := range files {
+		records, err := loadSnapshotFile(file)
+		if err != nil {
+			return fmt.Errorf("invalid snapshot file %s: %w", file, err)
+		}
+		for _, r := range records {
+			if r.PriceBasis == "" {
+				r.PriceBasis = p.priceBasis
+			}
+			key := snapshotKey{city: normalizeSnapshotCity(r.City), date: r.Date}
+			offers[key] = append(offers[key], r.Hotel)
+		}
+	}
+
+	p.mu.Lock()
+	p.offers = offers
+	p.version = version
+	p.mu.Unlock()
+
+	return nil
+}
+
+func (p *SnapshotProvider) reloadLoop() {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	var failed string
+
+	for {
+		select {
+		case <-ticker.C:
+			_, version, err := snapshotFiles(p.path)
+			if err != nil {
+				p.logger.Error("failed to check snapshot", "provider", p.name, "error", err)
+				continue
+			}
+			p.mu.RLock()
+			changed := version != p.version
+			p.mu.RUnlock()
+			if !changed || version == failed {
+				continue
+			}
+			if err := p.Reload(); err != nil {
+				failed = version
+				p.logger.Error("failed to reload snapshot", "provider", p.name, "error", err)
+				continue
+			}
+			p.logger.Info("snapshot reloaded", "provider", p.name, "path", p.path)
+		case <-p.done:
+			return
+		}
+	}
+}
+
+// snapshotFiles lists the snapshot files at path, a file or a directory,
+// and returns a version that changes whenever any of them does.
+func snapshotFiles(path string) ([]string, string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{path}, snapshotVersion(path, info), nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var files []string
+	var version strings.Builder
+	for _, e := range entries {
+		ext := strings.ToLower(filepath.Ext(e.Name()))
+		if e.IsDir() || (ext != ".json" && ext != ".csv") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read snapshot: %w", err)
+		}
+		file := filepath.Join(path, e.Name())
+		files = append(files, file)
+		version.WriteString(snapshotVersion(file, info))
+	}
+	return files, version.String(), nil
+}
+
+func snapshotVersion(file string, info os.FileInfo) string {
+	return fmt.Sprintf("%s:%d:%d;", file, info.Size(), info.ModTime().UnixNano())
+}
+
+// loadSnapshotFile reads the records of a JSON or CSV snapshot file.
+func loadSnapshotFile(path string) ([]snapshotRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = f.Close() // Read-only, nothing to flush
+	}()
+
+	var records []snapshotRecord
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		records, err = readSnapshotCSV(f)
+	} else {
+		err = json.NewDecoder(f).Decode(&records)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i, r := range records {
+		if strings.TrimSpace(r.City) == "" {
+			return nil, fmt.Errorf("record %d: missing city", i+1)
+		}
+		if r.Date != "" {
+			if _, err := time.Parse(time.DateOnly, r.Date); err != nil {
+				return nil, fmt.Errorf("record %d: invalid date %q, expected YYYY-MM-DD", i+1, r.Date)
+			}
+		}
+		if r.PriceBasis != "" && !r.PriceBasis.Valid() {
+			return nil, fmt.Errorf("record %d: invalid price basis %q", i+1, r.PriceBasis)
+		}
+	}
+	return records, nil
+}
+
+// readSnapshotCSV reads snapshot records from CSV. The header row names the
+// columns; city, hotel_id, name and price are required.
+func readSnapshotCSV(r io.Reader) ([]snapshotRecord, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(snapshotColumns, name) {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range []string{"city", "hotel_id", "name", "price"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	var records []snapshotRecord
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		record, err := parseSnapshotRow(columns, row)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+}
+
+// snapshotColumns are the columns a CSV snapshot may have.
+var snapshotColumns = []string{
+	"city", "date", "hotel_id", "name", "currency", "price", "taxes", "fees", "nights", "price_basis",
+	"address", "stars", "latitude", "longitude", "amenities", "images",
+}
+
+// parseSnapshotRow converts a CSV row into a record. Empty cells leave the
+// field unset.
+func parseSnapshotRow(columns map[string]int, row []string) (snapshotRecord, error) {
+	cell := func(name string) string {
+		if i, ok := columns[name]; ok && i < len(row) {
+			return strings.TrimSpace(row[i])
+		}
+		return ""
+	}
+	var firstErr error
+	number := func(name string) *float64 {
+		value := cell(name)
+		if value == "" {
+			return nil
+		}
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("invalid %s %q", name, value)
+			}
+			return nil
+		}
+		return &f
+	}
+	list := func(name string) []string {
+		var values []string
+		for v := range strings.SplitSeq(cell(name), "|") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		return values
+	}
+	orZero := func(f *float64) float64 {
+		if f == nil {
+			return 0
+		}
+		return *f
+	}
+
+	r := snapshotRecord{
+		Date: cell("date"),
+		Hotel: Hotel{
+			HotelID:    cell("hotel_id"),
+			Name:       cell("name"),
+			City:       cell("city"),
+			Currency:   cell("currency"),
+			Price:      orZero(number("price")),
+			Taxes:      orZero(number("taxes")),
+			Fees:       orZero(number("fees")),
+			Nights:     int(orZero(number("nights"))),
+			PriceBasis: PriceBasis(cell("price_basis")),
+			Address:    cell("address"),
+			Stars:      orZero(number("stars")),
+			Latitude:   number("latitude"),
+			Longitude:  number("longitude"),
+			Amenities:  list("amenities"),
+			Images:     list("images"),
+		},
+	}
+	return r, firstErr
+}
+
+// normalizeSnapshotCity normalizes a city name for lookups.
+func normalizeSnapshotCity(city string) string {
+	return strings.ToLower(strings.TrimSpace(city))
+}
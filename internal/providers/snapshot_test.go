@@ -0,0 +1,215 @@
+package providers_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/providers"
+)
+
+const snapshotJSON = `[
+	{"city": "Paris", "date": "2026-12-24", "hotel_id": "H1", "name": "Grand Hotel", "currency": "EUR", "price": 240},
+	{"city": "paris", "hotel_id": "H1", "name": "Grand Hotel", "currency": "EUR", "price": 150},
+	{"city": "paris", "hotel_id": "H2", "name": "Budget Stay", "currency": "EUR", "price": 70, "price_basis": "per_stay", "nights": 2}
+]`
+
+const snapshotCSV = `city,date,hotel_id,name,currency,price,taxes,latitude,longitude,amenities
+london,,H3,Luxury Palace,GBP,290,48.33,51.5072,-0.1276,spa|pool
+london,2026-12-31,H3,Luxury Palace,GBP,410,,,,
+`
+
+const snapshotStaysJSON = `[
+	{"city": "rome", "date": "2026-12-24", "hotel_id": "H4", "name": "Forum Hotel", "currency": "EUR", "price": 300, "price_basis": "per_stay", "nights": 2},
+	{"city": "rome", "date": "2026-12-24", "hotel_id": "H5", "name": "Tiber Inn", "currency": "EUR", "price": 90},
+	{"city": "rome", "hotel_id": "H4", "name": "Forum Hotel", "currency": "EUR", "price": 140}
+]`
+
+func writeSnapshot(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func hotelIDs(hotels []providers.Hotel) []string {
+	ids := make([]string, len(hotels))
+	for i, h := range hotels {
+		ids[i] = h.HotelID
+	}
+	return ids
+}
+
+func TestSnapshotProvider_Search(t *testing.T) {
+	dir := t.TempDir()
+	writeSnapshot(t, dir, "paris.json", snapshotJSON)
+	writeSnapshot(t, dir, "rates.csv", snapshotCSV)
+	writeSnapshot(t, dir, "README.txt", "not a snapshot")
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	p, err := providers.NewSnapshotProvider("snapshot", dir, 0, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() { _ = p.Close() }()
+
+	tests := []struct {
+		name    string
+		city    string
+		checkin string
+		want    []string
+		price   float64
+	}{
+		{name: "dated records", city: "paris", checkin: "2026-12-24", want: []string{"H1"}, price: 240},
+		{name: "undated records", city: " PARIS ", checkin: "2026-12-01", want: []string{"H1", "H2"}, price: 150},
+		{name: "csv", city: "london", checkin: "2026-12-01", want: []string{"H3"}, price: 290},
+		{name: "csv dated", city: "london", checkin: "2026-12-31", want: []string{"H3"}, price: 410},
+		{name: "unknown city", city: "rome", checkin: "2026-12-01", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hotels, err := p.Search(context.Background(), tt.city, tt.checkin, 2, providers.SingleRoom(2))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := hotelIDs(hotels); !slices.Equal(got, tt.want) {
+				t.Fatalf("hotels = %v, want %v", got, tt.want)
+			}
+			if len(hotels) > 0 && hotels[0].Price != tt.price {
+				t.Errorf("price = %v, want %v", hotels[0].Price, tt.price)
+			}
+		})
+	}
+
+	hotels, _ := p.Search(context.Background(), "paris", "2026-12-01", 2, providers.SingleRoom(2))
+	if hotels[0].PriceBasis != providers.PricePerNight || hotels[1].PriceBasis != providers.PricePerStay || hotels[1].Nights != 2 {
+		t.Errorf("unexpected price basis: %+v", hotels)
+	}
+
+	hotels, _ = p.Search(context.Background(), "london", "2026-12-01", 2, providers.SingleRoom(2))
+	h := hotels[0]
+	if h.Taxes != 48.33 || h.Latitude == nil || *h.Latitude != 51.5072 || !slices.Equal(h.Amenities, []string{"spa", "pool"}) {
+		t.Errorf("unexpected csv hotel: %+v", h)
+	}
+}
+
+func TestSnapshotProvider_Search_Nights(t *testing.T) {
+	dir := t.TempDir()
+	writeSnapshot(t, dir, "rome.json", snapshotStaysJSON)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	p, err := providers.NewSnapshotProvider("snapshot", dir, 0, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() { _ = p.Close() }()
+
+	tests := []struct {
+		name    string
+		checkin string
+		nights  int
+		want    []string
+	}{
+		{name: "per-stay record for its stay", checkin: "2026-12-24", nights: 2, want: []string{"H4", "H5"}},
+		{name: "per-stay record for another stay", checkin: "2026-12-24", nights: 3, want: []string{"H5"}},
+		{name: "undated per-night record", checkin: "2026-12-01", nights: 3, want: []string{"H4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hotels, err := p.Search(context.Background(), "rome", tt.checkin, tt.nights, providers.SingleRoom(2))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := hotelIDs(hotels); !slices.Equal(got, tt.want) {
+				t.Errorf("hotels = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSnapshotProvider_Reload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "snapshot.json")
+	writeSnapshot(t, dir, "snapshot.json", `[{"city": "paris", "hotel_id": "H1", "name": "Grand Hotel", "price": 150}]`)
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	p, err := providers.NewSnapshotProvider("snapshot", path, 10*time.Millisecond, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer func() { _ = p.Close() }()
+
+	search := func() []string {
+		hotels, err := p.Search(context.Background(), "paris", "2026-12-01", 2, providers.SingleRoom(2))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return hotelIDs(hotels)
+	}
+	waitFor := func(want []string) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for !slices.Equal(search(), want) {
+			if time.Now().After(deadline) {
+				t.Fatalf("hotels = %v, want %v", search(), want)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	writeSnapshot(t, dir, "snapshot.json", `[
+		{"city": "paris", "hotel_id": "H1", "name": "Grand Hotel", "price": 150},
+		{"city": "paris", "hotel_id": "H2", "name": "Budget Stay", "price": 70}
+	]`)
+	waitFor([]string{"H1", "H2"})
+
+	// A broken file keeps the previous snapshot
+	writeSnapshot(t, dir, "snapshot.json", `[{"city": "paris", "hotel_id": `)
+	time.Sleep(50 * time.Millisecond)
+	if got := search(); !slices.Equal(got, []string{"H1", "H2"}) {
+		t.Errorf("hotels = %v after a failed reload, want the previous snapshot", got)
+	}
+
+	writeSnapshot(t, dir, "snapshot.json", `[{"city": "paris", "hotel_id": "H3", "name": "Seaside Resort", "price": 200}]`)
+	waitFor([]string{"H3"})
+}
+
+func TestNewSnapshotProvider_Invalid(t *testing.T) {
+	tests := []struct {
+		name, file, content string
+		wantErr             string
+	}{
+		{name: "malformed json", file: "s.json", content: `{"city": "paris"}`, wantErr: "cannot unmarshal"},
+		{name: "missing city", file: "s.json", content: `[{"hotel_id": "H1", "name": "Grand Hotel", "price": 150}]`, wantErr: "missing city"},
+		{name: "invalid date", file: "s.json", content: `[{"city": "paris", "date": "24/12/2026", "hotel_id": "H1"}]`, wantErr: "invalid date"},
+		{name: "invalid price basis", file: "s.json", content: `[{"city": "paris", "hotel_id": "H1", "price_basis": "weekly"}]`, wantErr: "invalid price basis"},
+		{name: "unknown column", file: "s.csv", content: "city,hotel_id,name,price,rate\nparis,H1,Grand Hotel,150,1\n", wantErr: `unknown column "rate"`},
+		{name: "missing column", file: "s.csv", content: "city,hotel_id,name\nparis,H1,Grand Hotel\n", wantErr: `missing column "price"`},
+		{name: "invalid number", file: "s.csv", content: "city,hotel_id,name,price\nparis,H1,Grand Hotel,cheap\n", wantErr: `line 2: invalid price "cheap"`},
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeSnapshot(t, dir, tt.file, tt.content)
+
+			_, err := providers.NewSnapshotProvider("snapshot", dir, 0, logger)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := providers.NewSnapshotProvider("snapshot", filepath.Join(t.TempDir(), "missing"), 0, logger); err == nil {
+		t.Error("expected error for a missing path")
+	}
+}
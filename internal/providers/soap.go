@@ -0,0 +1,93 @@
+package providers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SOAPFault is a fault returned by a SOAP endpoint. Both SOAP 1.1 and 1.2
+// faults are read into it. Faults match ErrProviderRejected or
+// ErrProviderUnavailable with errors.Is.
+type SOAPFault struct {
+	// Code is the fault code without its namespace prefix, e.g. "Client" or
+	// "Receiver".
+	Code string
+	// Subcode is the SOAP 1.2 subcode without its namespace prefix, if any.
+	Subcode string
+	Reason  string
+	// Detail is the fault detail decoded like the rest of the response, or
+	// nil if the fault has none.
+	Detail any
+}
+
+// Error implements the error interface.
+func (f *SOAPFault) Error() string {
+	code := f.Code
+	if f.Subcode != "" {
+		code += "/" + f.Subcode
+	}
+	return fmt.Sprintf("soap fault %s: %s", code, f.Reason)
+}
+
+// Client reports whether the fault blames the request rather than the
+// provider, in which case sending it again won't help.
+func (f *SOAPFault) Client() bool {
+	return f.Code == "Client" || f.Code == "Sender"
+}
+
+// Unwrap classifies the fault: client faults match ErrProviderRejected and
+// all others ErrProviderUnavailable.
+func (f *SOAPFault) Unwrap() error {
+	if f.Client() {
+		return ErrProviderRejected
+	}
+	return ErrProviderUnavailable
+}
+
+// FindSOAPFault returns the fault in a document decoded by DecodeXML, or nil
+// if the document is not a SOAP fault.
+func FindSOAPFault(doc any) *SOAPFault {
+	v, ok := lookup(doc, "Envelope.Body.Fault")
+	node, isNode := v.(map[string]any)
+	if !ok || !isNode {
+		return nil
+	}
+
+	// SOAP 1.2 nests the code and reason; SOAP 1.1 has flat elements
+	if _, ok := node["Code"]; ok {
+		return &SOAPFault{
+			Code:    localName(scalarText(valueAt(node, "Code.Value"))),
+			Subcode: localName(scalarText(valueAt(node, "Code.Subcode.Value"))),
+			Reason:  firstText(valueAt(node, "Reason.Text")),
+			Detail:  node["Detail"],
+		}
+	}
+	return &SOAPFault{
+		Code:   localName(scalarText(node["faultcode"])),
+		Reason: scalarText(node["faultstring"]),
+		Detail: node["detail"],
+	}
+}
+
+// valueAt returns the value at path, or nil if it is missing.
+func valueAt(v any, path string) any {
+	v, _ = lookup(v, path)
+	return v
+}
+
+// firstText returns the text of v, or of its first element if it is a list,
+// as when a SOAP 1.2 reason is given in several languages.
+func firstText(v any) string {
+	if list, ok := v.([]any); ok && len(list) > 0 {
+		v = list[0]
+	}
+	return scalarText(v)
+}
+
+// localName strips the namespace prefix from a qualified name.
+func localName(name string) string {
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+	return strings.TrimSpace(name)
+}
@@ -0,0 +1,204 @@
+package providers
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultMaxResponseBytes is the largest response body read from a provider
+// unless configured otherwise.
+const DefaultMaxResponseBytes = 10 << 20
+
+// ErrResponseTooLarge is returned when a provider's response body, once
+// decompressed, exceeds the allowed size.
+var ErrResponseTooLarge = errors.New("response body too large")
+
+// TransportConfig tunes the connections to a provider. Zero values keep the
+// defaults of http.DefaultTransport.
+type TransportConfig struct {
+	// MaxIdleConnsPerHost is the number of idle connections kept open to
+	// the provider.
+	MaxIdleConnsPerHost int
+	// IdleConnTimeout is how long idle connections are kept open.
+	IdleConnTimeout time.Duration
+	// ResponseHeaderTimeout is how long to wait for the response headers
+	// once the request is sent.
+	ResponseHeaderTimeout time.Duration
+	// TLSMinVersion is the oldest TLS version accepted, such as
+	// tls.VersionTLS13.
+	TLSMinVersion uint16
+	// RootCAs verifies the provider's certificate instead of the system
+	// roots.
+	RootCAs *x509.CertPool
+	// Proxy is the proxy requests go through. Without one, the proxy is
+	// taken from the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment
+	// variables.
+	Proxy *url.URL
+	// DisableHTTP2 restricts connections to HTTP/1.1.
+	DisableHTTP2 bool
+}
+
+// newTransport builds the transport of a provider from its settings and
+// TLS configuration, either of which may be nil.
+func newTransport(cfg *TransportConfig, tlsConfig *tls.Config) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if tlsConfig != nil {
+		t.TLSClientConfig = tlsConfig.Clone()
+	}
+	if cfg == nil {
+		return t
+	}
+
+	if cfg.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = cfg.MaxIdleConnsPerHost
+		t.MaxIdleConns = max(t.MaxIdleConns, cfg.MaxIdleConnsPerHost)
+	}
+	if cfg.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = cfg.IdleConnTimeout
+	}
+	if cfg.ResponseHeaderTimeout > 0 {
+		t.ResponseHeaderTimeout = cfg.ResponseHeaderTimeout
+	}
+	if cfg.TLSMinVersion != 0 || cfg.RootCAs != nil {
+		if t.TLSClientConfig == nil {
+			t.TLSClientConfig = &tls.Config{}
+		}
+		if cfg.TLSMinVersion != 0 {
+			t.TLSClientConfig.MinVersion = cfg.TLSMinVersion
+		}
+		if cfg.RootCAs != nil {
+			t.TLSClientConfig.RootCAs = cfg.RootCAs
+		}
+	}
+	if cfg.Proxy != nil {
+		t.Proxy = http.ProxyURL(cfg.Proxy)
+	}
+	if cfg.DisableHTTP2 {
+		// Once used, the default transport's TLS configuration offers h2
+		// itself, which the clone would still negotiate.
+		t.ForceAttemptHTTP2 = false
+		t.Protocols = new(http.Protocols)
+		t.Protocols.SetHTTP1(true)
+		if t.TLSClientConfig != nil {
+			t.TLSClientConfig.NextProtos = slices.DeleteFunc(slices.Clone(t.TLSClientConfig.NextProtos), func(proto string) bool {
+				return proto == "h2"
+			})
+		}
+	}
+	return t
+}
+
+// ParseTLSVersion parses a TLS version such as "1.2" or "1.3".
+func ParseTLSVersion(s string) (uint16, error) {
+	switch strings.TrimPrefix(strings.ToLower(s), "tls") {
+	case "1.0":
+		return tls.VersionTLS10, nil
+	case "1.1":
+		return tls.VersionTLS11, nil
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("unknown TLS version %q, expected 1.0, 1.1, 1.2 or 1.3", s)
+	}
+}
+
+// LoadCAFile reads a PEM bundle of CA certificates.
+func LoadCAFile(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates in CA file %s", path)
+	}
+	return pool, nil
+}
+
+// acceptEncoding lists the response encodings decodeBody understands.
+const acceptEncoding = "gzip, deflate"
+
+// decodeBody returns the decompressed body of a response, failing with
+// ErrResponseTooLarge once more than limit bytes are read from it, unless
+// limit is zero or less. Deflate bodies may be zlib-wrapped, as the standard
+// says, or raw.
+func decodeBody(resp *http.Response, limit int64) (io.ReadCloser, error) {
+	var body io.ReadCloser = resp.Body
+	switch encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding"))); encoding {
+	case "", "identity":
+	case "gzip", "x-gzip":
+		r, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		body = r
+	case "deflate":
+		br := bufio.NewReader(resp.Body)
+		header, err := br.Peek(2)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deflate body: %w", err)
+		}
+		if isZlibHeader(header) {
+			r, err := zlib.NewReader(br)
+			if err != nil {
+				return nil, fmt.Errorf("invalid deflate body: %w", err)
+			}
+			body = r
+		} else {
+			body = flate.NewReader(br)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+	if limit <= 0 {
+		return body, nil
+	}
+	return &limitedBody{r: body, remaining: limit}, nil
+}
+
+// isZlibHeader reports whether b starts a zlib stream: deflate compression
+// and a header checksum that's a multiple of 31.
+func isZlibHeader(b []byte) bool {
+	return b[0]&0x0f == 8 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0
+}
+
+// limitedBody fails with ErrResponseTooLarge once more than the allowed
+// bytes are read.
+type limitedBody struct {
+	r         io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, ErrResponseTooLarge
+	}
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.r.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n + int(b.remaining), ErrResponseTooLarge
+	}
+	return n, err
+}
+
+func (b *limitedBody) Close() error {
+	return b.r.Close()
+}
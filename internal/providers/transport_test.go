@@ -0,0 +1,284 @@
+package providers_test
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/tls"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/providers"
+)
+
+const transportBody = `[{"hotel_id":"H001","name":"Hotel A","currency":"EUR","price":100}]`
+
+func TestHTTPProvider_Search_Encoding(t *testing.T) {
+	compress := func(newWriter func(io.Writer) io.WriteCloser) []byte {
+		var buf bytes.Buffer
+		w := newWriter(&buf)
+		_, _ = w.Write([]byte(transportBody))
+		_ = w.Close()
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+	}{
+		{name: "identity", body: []byte(transportBody)},
+		{name: "gzip", encoding: "gzip", body: compress(func(w io.Writer) io.WriteCloser { return gzip.NewWriter(w) })},
+		{name: "zlib deflate", encoding: "deflate", body: compress(func(w io.Writer) io.WriteCloser { return zlib.NewWriter(w) })},
+		{name: "raw deflate", encoding: "deflate", body: compress(func(w io.Writer) io.WriteCloser {
+			fw, _ := flate.NewWriter(w, flate.DefaultCompression)
+			return fw
+		})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var accept string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				accept = r.Header.Get("Accept-Encoding")
+				if tt.encoding != "" {
+					w.Header().Set("Content-Encoding", tt.encoding)
+				}
+				_, _ = w.Write(tt.body)
+			}))
+			defer srv.Close()
+
+			p := providers.NewHTTPProvider("test", srv.URL, time.Second)
+			hotels, err := p.Search(context.Background(), "paris", "2026-12-01", 2, providers.SingleRoom(2))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(hotels) != 1 || hotels[0].HotelID != "H001" {
+				t.Errorf("hotels = %+v, want H001", hotels)
+			}
+			if accept != "gzip, deflate" {
+				t.Errorf("Accept-Encoding = %q, want gzip, deflate", accept)
+			}
+		})
+	}
+}
+
+func TestHTTPProvider_Search_MaxResponseBytes(t *testing.T) {
+	// A small gzip body that decompresses past the limit
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, _ = gz.Write([]byte(`[{"hotel_id":"H001","name":"` + strings.Repeat("a", 4096) + `"}]`))
+	_ = gz.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		_, _ = w.Write(buf.Bytes())
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name    string
+		limit   int64
+		wantErr bool
+	}{
+		{name: "over limit", limit: 1024, wantErr: true},
+		{name: "under limit", limit: 8192},
+		{name: "unlimited", limit: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := providers.NewHTTPProvider("test", srv.URL, time.Second, providers.WithMaxResponseBytes(tt.limit))
+			_, err := p.Search(context.Background(), "paris", "2026-12-01", 2, providers.SingleRoom(2))
+			if tt.wantErr && !errors.Is(err, providers.ErrResponseTooLarge) {
+				t.Errorf("error = %v, want ErrResponseTooLarge", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestXMLProvider_Search_MaxResponseBytes(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, _ = gz.Write([]byte(soapResponse))
+	_ = gz.Close()
+
+	tests := []struct {
+		name    string
+		limit   int64
+		wantErr bool
+	}{
+		{name: "over limit", limit: 64, wantErr: true},
+		{name: "under limit", limit: int64(len(soapResponse))},
+		{name: "unlimited", limit: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newXMLProvider(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Encoding", "gzip")
+				_, _ = w.Write(buf.Bytes())
+			}, providers.WithXMLMaxResponseBytes(tt.limit))
+			hotels, err := p.Search(context.Background(), "paris", "2026-12-01", 2, providers.SingleRoom(2))
+			if tt.wantErr {
+				if !errors.Is(err, providers.ErrResponseTooLarge) {
+					t.Errorf("error = %v, want ErrResponseTooLarge", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(hotels) != 2 {
+				t.Errorf("expected 2 hotels, got %d", len(hotels))
+			}
+		})
+	}
+}
+
+func TestXMLProvider_Search_Proxy(t *testing.T) {
+	var target string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target = r.URL.String()
+		_, _ = w.Write([]byte(soapResponse))
+	}))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	schema := xmlSchema
+	request := template.Must(template.New("request").Parse(requestTemplate))
+	p := providers.NewXMLProvider("test", "http://supplier.invalid/soap", request, &schema, time.Second,
+		providers.WithXMLTransport(providers.TransportConfig{Proxy: proxyURL}))
+	if _, err := p.Search(context.Background(), "paris", "2026-12-01", 2, providers.SingleRoom(2)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if target != "http://supplier.invalid/soap" {
+		t.Errorf("proxied request = %q, want the supplier URL", target)
+	}
+}
+
+func TestHTTPProvider_Search_HTTP2(t *testing.T) {
+	var proto string
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proto = r.Proto
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	// The test server's certificate is trusted through a CA bundle file
+	caFile := filepath.Join(t.TempDir(), "ca.crt")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	roots, err := providers.LoadCAFile(caFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		cfg       providers.TransportConfig
+		wantProto string
+	}{
+		{name: "default", cfg: providers.TransportConfig{RootCAs: roots}, wantProto: "HTTP/2.0"},
+		{name: "disabled", cfg: providers.TransportConfig{RootCAs: roots, DisableHTTP2: true}, wantProto: "HTTP/1.1"},
+		{name: "TLS 1.3", cfg: providers.TransportConfig{RootCAs: roots, TLSMinVersion: tls.VersionTLS13}, wantProto: "HTTP/2.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := providers.NewHTTPProvider("test", srv.URL, time.Second, providers.WithTransport(tt.cfg))
+			if _, err := p.Search(context.Background(), "paris", "2026-12-01", 2, providers.SingleRoom(2)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if proto != tt.wantProto {
+				t.Errorf("protocol = %s, want %s", proto, tt.wantProto)
+			}
+		})
+	}
+
+	// Without the CA bundle the certificate isn't trusted
+	p := providers.NewHTTPProvider("test", srv.URL, time.Second)
+	if _, err := p.Search(context.Background(), "paris", "2026-12-01", 2, providers.SingleRoom(2)); err == nil {
+		t.Error("expected error without the CA bundle")
+	}
+}
+
+func TestHTTPProvider_Search_Proxy(t *testing.T) {
+	var target string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target = r.URL.String()
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	p := providers.NewHTTPProvider("test", "http://supplier.invalid", time.Second,
+		providers.WithTransport(providers.TransportConfig{Proxy: proxyURL}))
+	if _, err := p.Search(context.Background(), "paris", "2026-12-01", 2, providers.SingleRoom(2)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(target, "http://supplier.invalid/search?") {
+		t.Errorf("proxied request = %q, want the supplier URL", target)
+	}
+}
+
+func TestHTTPProvider_Search_ResponseHeaderTimeout(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	p := providers.NewHTTPProvider("test", srv.URL, 5*time.Second,
+		providers.WithTransport(providers.TransportConfig{ResponseHeaderTimeout: 50 * time.Millisecond}))
+	start := time.Now()
+	if _, err := p.Search(context.Background(), "paris", "2026-12-01", 2, providers.SingleRoom(2)); err == nil {
+		t.Fatal("expected error when the headers are late")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("search took %v, want the response header timeout", elapsed)
+	}
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    uint16
+		wantErr bool
+	}{
+		{in: "1.2", want: tls.VersionTLS12},
+		{in: "TLS1.3", want: tls.VersionTLS13},
+		{in: "1.4", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := providers.ParseTLSVersion(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTLSVersion(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+		}
+		if got != tt.want {
+			t.Errorf("ParseTLSVersion(%q) = %x, want %x", tt.in, got, tt.want)
+		}
+	}
+}
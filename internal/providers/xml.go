@@ -0,0 +1,307 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// xmlTextKey holds the text of an XML element that also has attributes or
+// child elements.
+const xmlTextKey = "#text"
+
+// XMLRequest is the data request templates are executed with. Text fields
+// are escaped for XML and can be used in text and attributes as they are.
+type XMLRequest struct {
+	City    string
+	Checkin string
+	Nights  int
+	Adults  int
+	Rooms   int
+	// Occupancy is the string form of Guests.
+	Occupancy string
+	Guests    Occupancy
+}
+
+// XMLProvider queries an XML or SOAP endpoint for hotel data. Requests are
+// built from a template and POSTed to the endpoint; responses are decoded
+// with DecodeXML and read with a Schema. SOAP faults are returned as
+// *SOAPFault.
+type XMLProvider struct {
+	name       string
+	endpoint   string
+	request    *template.Template
+	schema     *Schema
+	soapAction string
+	priceBasis PriceBasis
+	httpClient *http.Client
+
+	transport        *TransportConfig
+	maxResponseBytes int64
+}
+
+// XMLOption configures an XMLProvider.
+type XMLOption func(*XMLProvider)
+
+// WithSOAPAction sets the SOAPAction header sent with every request.
+func WithSOAPAction(action string) XMLOption {
+	return func(p *XMLProvider) {
+		p.soapAction = action
+	}
+}
+
+// WithXMLPriceBasis declares what the provider's prices cover when its
+// hotels don't say so themselves. Defaults to PricePerStay.
+func WithXMLPriceBasis(basis PriceBasis) XMLOption {
+	return func(p *XMLProvider) {
+		p.priceBasis = basis
+	}
+}
+
+// WithXMLTransport tunes the connections to the provider.
+func WithXMLTransport(cfg TransportConfig) XMLOption {
+	return func(p *XMLProvider) {
+		p.transport = &cfg
+	}
+}
+
+// WithXMLMaxResponseBytes caps the size of a decompressed response body;
+// zero or less means no limit. Defaults to DefaultMaxResponseBytes.
+func WithXMLMaxResponseBytes(n int64) XMLOption {
+	return func(p *XMLProvider) {
+		p.maxResponseBytes = n
+	}
+}
+
+// NewXMLProvider creates a new XMLProvider. Requests are built by executing
+// request with an XMLRequest and sent to endpoint as is.
+func NewXMLProvider(name, endpoint string, request *template.Template, schema *Schema, timeout time.Duration, opts ...XMLOption) *XMLProvider {
+	p := &XMLProvider{
+		name:             name,
+		endpoint:         endpoint,
+		request:          request,
+		schema:           schema,
+		priceBasis:       PricePerStay,
+		maxResponseBytes: DefaultMaxResponseBytes,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.httpClient = &http.Client{
+		Timeout:   timeout,
+		Transport: newTransport(p.transport, nil),
+	}
+	return p
+}
+
+// LoadRequestTemplate reads a request template file.
+func LoadRequestTemplate(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request template: %w", err)
+	}
+
+	tmpl, err := template.New(path).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse request template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// Name returns the provider name.
+func (p *XMLProvider) Name() string {
+	return p.name
+}
+
+// Search searches for hotels by POSTing an XML request.
+func (p *XMLProvider) Search(ctx context.Context, city, checkin string, nights int, occupancy Occupancy) ([]Hotel, error) {
+	var body bytes.Buffer
+	err := p.request.Execute(&body, XMLRequest{
+		City:      escapeXML(city),
+		Checkin:   escapeXML(checkin),
+		Nights:    nights,
+		Adults:    occupancy.Adults(),
+		Rooms:     occupancy.Rooms(),
+		Occupancy: escapeXML(occupancy.String()),
+		Guests:    occupancy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, &body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/xml; charset=utf-8")
+	req.Header.Set("Accept-Encoding", acceptEncoding)
+	if p.soapAction != "" {
+		req.Header.Set("SOAPAction", `"`+p.soapAction+`"`)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close() // Explicitly ignore close error
+	}()
+
+	// Only the start of an error body is kept, as sent if it can't be
+	// decoded
+	respBody, err := decodeBody(resp, p.maxResponseBytes)
+	if err != nil {
+		if resp.StatusCode != http.StatusOK {
+			msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+			return nil, fmt.Errorf("provider returned status %d: %s", resp.StatusCode, string(msg))
+		}
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	defer func() {
+		_ = respBody.Close() // Explicitly ignore close error
+	}()
+
+	data, err := io.ReadAll(respBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// SOAP faults usually come with a 500 status, but not always
+	doc, decodeErr := DecodeXML(bytes.NewReader(data))
+	if decodeErr == nil {
+		if fault := FindSOAPFault(doc); fault != nil {
+			return nil, fault
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("provider returned status %d: %s", resp.StatusCode, string(data[:min(len(data), maxErrorBody)]))
+	}
+	if decodeErr != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", decodeErr)
+	}
+
+	hotels, err := p.schema.Extract(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	for i := range hotels {
+		if hotels[i].PriceBasis == "" {
+			hotels[i].PriceBasis = p.priceBasis
+		}
+	}
+
+	return hotels, nil
+}
+
+// escapeXML escapes s for use in XML text and attribute values.
+func escapeXML(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s)) // strings.Builder doesn't fail
+	return b.String()
+}
+
+// DecodeXML decodes an XML document into the tree Schema.Extract reads: the
+// document is an object holding its root element by name. Elements are
+// keyed by local name, without namespace prefixes, and attributes by their
+// local name prefixed with "@". Elements with only text decode to the text;
+// elements with attributes or children decode to objects, with any text
+// under "#text". Empty elements decode to null, and repeated elements to a
+// list. Values are kept as strings; the schema converts them.
+func DecodeXML(r io.Reader) (any, error) {
+	type frame struct {
+		name string
+		node map[string]any
+		text strings.Builder
+	}
+
+	dec := xml.NewDecoder(r)
+	dec.CharsetReader = charsetReader
+	var stack []*frame
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("no root element")
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			f := &frame{name: t.Name.Local, node: make(map[string]any)}
+			for _, attr := range t.Attr {
+				if attr.Name.Space == "xmlns" || attr.Name.Local == "xmlns" {
+					continue
+				}
+				f.node["@"+attr.Name.Local] = attr.Value
+			}
+			stack = append(stack, f)
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].text.Write(t)
+			}
+		case xml.EndElement:
+			f := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			var value any
+			text := strings.TrimSpace(f.text.String())
+			switch {
+			case len(f.node) > 0:
+				if text != "" {
+					f.node[xmlTextKey] = text
+				}
+				value = f.node
+			case text != "":
+				value = text
+			}
+
+			if len(stack) == 0 {
+				return map[string]any{f.name: value}, nil
+			}
+			addChild(stack[len(stack)-1].node, f.name, value)
+		}
+	}
+}
+
+// addChild adds a decoded element to its parent, collecting repeated
+// elements into a list.
+func addChild(parent map[string]any, name string, value any) {
+	existing, ok := parent[name]
+	if !ok {
+		parent[name] = value
+		return
+	}
+	if list, isList := existing.([]any); isList {
+		parent[name] = append(list, value)
+		return
+	}
+	parent[name] = []any{existing, value}
+}
+
+// charsetReader converts the Latin-1 documents some legacy suppliers send
+// to UTF-8. UTF-8 documents don't go through it.
+func charsetReader(charset string, input io.Reader) (io.Reader, error) {
+	switch strings.ToLower(charset) {
+	case "iso-8859-1", "latin1", "latin-1":
+		data, err := io.ReadAll(input)
+		if err != nil {
+			return nil, err
+		}
+		runes := make([]rune, len(data))
+		for i, c := range data {
+			runes[i] = rune(c)
+		}
+		return strings.NewReader(string(runes)), nil
+	}
+	return nil, fmt.Errorf("unsupported charset %q", charset)
+}
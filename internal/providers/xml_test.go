@@ -0,0 +1,216 @@
+package providers_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/providers"
+)
+
+const requestTemplate = `<Search><City>{{.City}}</City><Nights>{{.Nights}}</Nights>` +
+	`{{range .Guests}}<Room adults="{{.Adults}}">{{range .ChildAges}}<Child age="{{.}}"/>{{end}}</Room>{{end}}</Search>`
+
+const soapResponse = `<?xml version="1.0" encoding="UTF-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+	<soap:Body>
+		<r:SearchResponse xmlns:r="urn:test">
+			<r:Hotels>
+				<r:Hotel id="X-1">
+					<r:Name>Grand Hotel</r:Name>
+					<r:Rate currency="CHF" nights="2"><r:Total>250.50</r:Total></r:Rate>
+					<r:Amenity>spa</r:Amenity>
+					<r:Amenity>pool</r:Amenity>
+				</r:Hotel>
+				<r:Hotel id="X-2">
+					<r:Name>Budget Stay</r:Name>
+					<r:Rate currency="CHF" nights="2"><r:Total>90</r:Total></r:Rate>
+				</r:Hotel>
+			</r:Hotels>
+		</r:SearchResponse>
+	</soap:Body>
+</soap:Envelope>`
+
+var xmlSchema = providers.Schema{
+	Hotels:    "Envelope.Body.SearchResponse.Hotels.Hotel",
+	HotelID:   "@id",
+	Name:      "Name",
+	Currency:  "Rate.@currency",
+	Price:     "Rate.Total",
+	Nights:    "Rate.@nights",
+	Amenities: "Amenity",
+}
+
+func newXMLProvider(t *testing.T, handler http.HandlerFunc, opts ...providers.XMLOption) *providers.XMLProvider {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	schema := xmlSchema
+	request := template.Must(template.New("request").Parse(requestTemplate))
+	opts = append([]providers.XMLOption{
+		providers.WithSOAPAction("urn:test#Search"),
+		providers.WithXMLPriceBasis(providers.PricePerNight),
+	}, opts...)
+	return providers.NewXMLProvider("test", srv.URL+"/soap", request, &schema, time.Second, opts...)
+}
+
+func TestXMLProvider_Search(t *testing.T) {
+	var body, action, path string
+	p := newXMLProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body, action, path = string(data), r.Header.Get("SOAPAction"), r.URL.Path
+		_, _ = w.Write([]byte(soapResponse))
+	})
+
+	occupancy := providers.Occupancy{{Adults: 2, ChildAges: []int{5}}, {Adults: 1}}
+	hotels, err := p.Search(context.Background(), "Zürich & <Co>", "2026-12-01", 2, occupancy)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	wantBody := `<Search><City>Zürich &amp; &lt;Co&gt;</City><Nights>2</Nights>` +
+		`<Room adults="2"><Child age="5"/></Room><Room adults="1"></Room></Search>`
+	if body != wantBody {
+		t.Errorf("request body = %s, want %s", body, wantBody)
+	}
+	if action != `"urn:test#Search"` || path != "/soap" {
+		t.Errorf("SOAPAction = %s, path = %s", action, path)
+	}
+
+	if len(hotels) != 2 {
+		t.Fatalf("expected 2 hotels, got %d", len(hotels))
+	}
+	h := hotels[0]
+	if h.HotelID != "X-1" || h.Name != "Grand Hotel" || h.Currency != "CHF" || h.Price != 250.5 || h.Nights != 2 {
+		t.Errorf("unexpected hotel: %+v", h)
+	}
+	if !reflect.DeepEqual(h.Amenities, []string{"spa", "pool"}) {
+		t.Errorf("amenities = %v", h.Amenities)
+	}
+	if h.PriceBasis != providers.PricePerNight {
+		t.Errorf("price basis = %q, want the provider default", h.PriceBasis)
+	}
+}
+
+func TestXMLProvider_Search_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		response   string
+		wantFault  *providers.SOAPFault
+		wantClient bool
+	}{
+		{
+			name:   "soap 1.1 fault",
+			status: http.StatusInternalServerError,
+			response: `<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/"><soap:Body><soap:Fault>` +
+				`<faultcode>soap:Client</faultcode><faultstring>unknown city</faultstring><detail><Field>City</Field></detail>` +
+				`</soap:Fault></soap:Body></soap:Envelope>`,
+			wantFault:  &providers.SOAPFault{Code: "Client", Reason: "unknown city", Detail: map[string]any{"Field": "City"}},
+			wantClient: true,
+		},
+		{
+			name:   "soap 1.2 fault with status 200",
+			status: http.StatusOK,
+			response: `<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope"><env:Body><env:Fault>` +
+				`<env:Code><env:Value>env:Receiver</env:Value><env:Subcode><env:Value>m:Overloaded</env:Value></env:Subcode></env:Code>` +
+				`<env:Reason><env:Text xml:lang="en">try later</env:Text><env:Text xml:lang="de">später</env:Text></env:Reason>` +
+				`</env:Fault></env:Body></env:Envelope>`,
+			wantFault: &providers.SOAPFault{Code: "Receiver", Subcode: "Overloaded", Reason: "try later"},
+		},
+		{name: "non-xml error", status: http.StatusServiceUnavailable, response: "down for maintenance"},
+		{name: "long error", status: http.StatusBadGateway, response: strings.Repeat("x", 1<<20)},
+		{name: "unexpected document", status: http.StatusOK, response: `<Envelope><Body><Other/></Body></Envelope>`},
+		{name: "malformed xml", status: http.StatusOK, response: `<Envelope><Body>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newXMLProvider(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.response))
+			})
+
+			_, err := p.Search(context.Background(), "paris", "2026-12-01", 2, providers.SingleRoom(2))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if len(err.Error()) > 2<<10 {
+				t.Errorf("error is %d bytes long, want the body truncated", len(err.Error()))
+			}
+
+			var fault *providers.SOAPFault
+			isFault := errors.As(err, &fault)
+			if tt.wantFault == nil {
+				if isFault {
+					t.Errorf("unexpected SOAP fault: %v", fault)
+				}
+				return
+			}
+			if !isFault {
+				t.Fatalf("error = %v, want a SOAP fault", err)
+			}
+			if !reflect.DeepEqual(fault, tt.wantFault) {
+				t.Errorf("fault = %+v, want %+v", fault, tt.wantFault)
+			}
+			if fault.Client() != tt.wantClient {
+				t.Errorf("Client() = %v, want %v", fault.Client(), tt.wantClient)
+			}
+			wantSentinel := providers.ErrProviderUnavailable
+			if tt.wantClient {
+				wantSentinel = providers.ErrProviderRejected
+			}
+			if !errors.Is(err, wantSentinel) {
+				t.Errorf("error = %v, want it to match %v", err, wantSentinel)
+			}
+		})
+	}
+}
+
+func TestDecodeXML(t *testing.T) {
+	tests := []struct {
+		name string
+		doc  string
+		want any
+	}{
+		{
+			name: "text, attributes and repeated elements",
+			doc:  `<a:Root xmlns:a="urn:a" version="2"><a:Item>one</a:Item><a:Item>two</a:Item><a:Price currency="EUR">10</a:Price></a:Root>`,
+			want: map[string]any{"Root": map[string]any{
+				"@version": "2",
+				"Item":     []any{"one", "two"},
+				"Price":    map[string]any{"@currency": "EUR", "#text": "10"},
+			}},
+		},
+		{
+			name: "empty elements",
+			doc:  `<Root><List/><Name>  </Name></Root>`,
+			want: map[string]any{"Root": map[string]any{"List": nil, "Name": nil}},
+		},
+		{
+			name: "latin-1",
+			doc:  "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><Name>Z\xfcrich</Name>",
+			want: map[string]any{"Name": "Zürich"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := providers.DecodeXML(strings.NewReader(tt.doc))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,56 +2,206 @@ package search
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"log/slog"
+	"math"
+	"net/http"
 	"sort"
-	"strings"
 	"sync"
 	"time"
 
 	"github.com/alex-user-go/hotels/internal/obs"
 	"github.com/alex-user-go/hotels/internal/providers"
+	"github.com/alex-user-go/hotels/internal/search/anomaly"
+	"github.com/alex-user-go/hotels/internal/search/normalize"
 	"github.com/alex-user-go/hotels/internal/search/types"
 )
 
+// CurrencyConverter converts amounts between currencies.
+type CurrencyConverter interface {
+	Convert(amount float64, from, to string) (float64, error)
+}
+
+// HotelResolver resolves provider-specific hotel IDs to canonical IDs.
+type HotelResolver interface {
+	// Resolve returns the canonical ID and display name of a provider hotel.
+	Resolve(provider, hotelID, name, city string) (string, string)
+}
+
+// ContentStore holds descriptive hotel content keyed by canonical hotel ID.
+type ContentStore interface {
+	// Learn records the content a provider sent with an offer.
+	Learn(hotelID string, hotel providers.Hotel)
+	// Enrich adds the known content of a hotel to a result.
+	Enrich(h *types.Hotel)
+}
+
+// PriceScreen detects offers whose price is out of line with comparable offers.
+type PriceScreen interface {
+	// Screen returns the offers to keep, with anomalous ones marked Suspect,
+	// and a finding for every anomalous offer.
+	Screen(city string, nights int, occupancy providers.Occupancy, offers []types.Hotel) ([]types.Hotel, []anomaly.Finding)
+}
+
+// TimeoutPolicy derives provider timeouts from their recent latencies.
+type TimeoutPolicy interface {
+	// Observe records how long a search of provider took.
+	Observe(provider string, latency time.Duration)
+	// Latency returns the latency quantile timeouts are derived from and
+	// the number of latencies it was computed from.
+	Latency(provider string) (time.Duration, int)
+	// Timeout returns the timeout of the provider's next search, at most
+	// limit.
+	Timeout(provider string, limit time.Duration) time.Duration
+}
+
 // Aggregator aggregates results from multiple providers.
 type Aggregator struct {
 	providers []providers.Provider
 	timeout   time.Duration
+	timeouts  map[string]time.Duration
+	adaptive  TimeoutPolicy
+	converter CurrencyConverter
+	resolver  HotelResolver
+	content   ContentStore
+	screen    PriceScreen
+	pipelines map[string]*normalize.Pipeline
+	pipeline  *normalize.Pipeline
 	metrics   *obs.Metrics
 	logger    *slog.Logger
 }
 
+// Option configures an Aggregator.
+type Option func(*Aggregator)
+
+// WithConverter sets the converter used to bring offers into the requested currency.
+func WithConverter(converter CurrencyConverter) Option {
+	return func(a *Aggregator) {
+		a.converter = converter
+	}
+}
+
+// WithResolver sets the resolver used to identify the same hotel across
+// providers. Without one, providers are assumed to share hotel IDs.
+func WithResolver(resolver HotelResolver) Option {
+	return func(a *Aggregator) {
+		a.resolver = resolver
+	}
+}
+
+// WithContent sets the store used to enrich results with hotel content.
+// Content sent by providers is recorded in the store as offers arrive.
+func WithContent(store ContentStore) Option {
+	return func(a *Aggregator) {
+		a.content = store
+	}
+}
+
+// WithPriceScreen sets the screen used to flag or drop offers with
+// anomalous prices before deduplication.
+func WithPriceScreen(screen PriceScreen) Option {
+	return func(a *Aggregator) {
+		a.screen = screen
+	}
+}
+
+// WithPipeline sets the normalization pipeline for a provider's offers.
+// Providers without one use the standard steps, including mapping and
+// enrichment when a resolver or content store is set.
+func WithPipeline(provider string, pipeline *normalize.Pipeline) Option {
+	return func(a *Aggregator) {
+		a.pipelines[provider] = pipeline
+	}
+}
+
+// WithProviderTimeout sets how long a provider's searches may take. The
+// search timeout still bounds every provider. Providers without one are
+// only bound by the search timeout.
+func WithProviderTimeout(provider string, timeout time.Duration) Option {
+	return func(a *Aggregator) {
+		a.timeouts[provider] = timeout
+	}
+}
+
+// WithAdaptiveTimeouts derives each provider's timeout from its recent
+// latencies with policy. The provider timeout, or the search timeout, is
+// the most a provider is given.
+func WithAdaptiveTimeouts(policy TimeoutPolicy) Option {
+	return func(a *Aggregator) {
+		a.adaptive = policy
+	}
+}
+
 // NewAggregator creates a new Aggregator.
-func NewAggregator(providers []providers.Provider, timeout time.Duration, metrics *obs.Metrics, logger *slog.Logger) *Aggregator {
-	return &Aggregator{
+func NewAggregator(providers []providers.Provider, timeout time.Duration, metrics *obs.Metrics, logger *slog.Logger, opts ...Option) *Aggregator {
+	a := &Aggregator{
 		providers: providers,
 		timeout:   timeout,
+		timeouts:  make(map[string]time.Duration),
+		pipelines: make(map[string]*normalize.Pipeline),
 		metrics:   metrics,
 		logger:    logger,
 	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	a.pipeline = normalize.New(normalize.Standard(a.resolver, a.content)...)
+
+	return a
+}
+
+// pipelineFor returns the normalization pipeline for a provider.
+func (a *Aggregator) pipelineFor(provider string) *normalize.Pipeline {
+	if p, ok := a.pipelines[provider]; ok {
+		return p
+	}
+	return a.pipeline
 }
 
 // Search queries all providers concurrently and aggregates results.
-func (a *Aggregator) Search(ctx context.Context, city, checkin string, nights, adults int) (*types.Result, error) {
+// Offers are converted to currency before deduplication and sorting so that
+// prices are compared in a single currency. An empty currency keeps the
+// provider's own currency. When several offers remain for a hotel, the
+// cheapest one not marked suspect is kept.
+func (a *Aggregator) Search(ctx context.Context, city, checkin string, nights int, occupancy providers.Occupancy, currency string) (*types.Result, error) {
 	ctx, cancel := context.WithTimeout(ctx, a.timeout)
 	defer cancel()
 
 	var (
 		mu        sync.Mutex
 		wg        sync.WaitGroup
-		hotelMap  = make(map[string]types.Hotel)
+		offers    []types.Hotel
 		succeeded int
 		failed    int
-		errors    []error
+		skipped   int
+		skipErr   error
+		errs      []error
 	)
 
 	for _, provider := range a.providers {
+		pipeline := a.pipelineFor(provider.Name())
 		wg.Go(func() {
-			hotels, err := provider.Search(ctx, city, checkin, nights, adults)
+			callCtx, cancel := context.WithTimeout(ctx, a.Timeout(provider.Name()))
+			defer cancel()
+
+			start := time.Now()
+			hotels, err := provider.Search(callCtx, city, checkin, nights, occupancy)
+			a.observe(ctx, callCtx, provider.Name(), time.Since(start), err)
+			if errors.Is(err, providers.ErrBudgetExhausted) || errors.Is(err, providers.ErrBulkheadFull) {
+				mu.Lock()
+				skipped++
+				skipErr = err
+				mu.Unlock()
+				if errors.Is(err, providers.ErrBudgetExhausted) {
+					a.metrics.IncBudgetSkips(provider.Name())
+				}
+				return
+			}
 			if err != nil {
 				mu.Lock()
 				failed++
-				errors = append(errors, err)
+				errs = append(errs, err)
 				mu.Unlock()
 				a.metrics.IncProviderErrors()
 				return
@@ -60,19 +210,19 @@ func (a *Aggregator) Search(ctx context.Context, city, checkin string, nights, a
 			mu.Lock()
 			succeeded++
 			for _, h := range hotels {
-				normalized := normalizeHotel(h)
-				if normalized == nil {
+				offer := normalize.Offer{Provider: provider.Name(), City: city, Nights: nights, Hotel: h}
+				if step, ok := pipeline.Run(&offer); !ok {
+					a.metrics.IncNormalizationDrops(provider.Name(), step)
 					continue
 				}
-
-				// Dedup by hotel_id, keep lowest price
-				if existing, ok := hotelMap[normalized.HotelID]; ok {
-					if normalized.Price < existing.Price {
-						hotelMap[normalized.HotelID] = *normalized
-					}
-				} else {
-					hotelMap[normalized.HotelID] = *normalized
+				normalized := stayTotal(offer, nights)
+				if !a.convert(normalized, currency) {
+					a.metrics.IncNormalizationDrops(provider.Name(), "convert")
+					continue
 				}
+				normalized.PricePerNight = round2(normalized.Price / float64(nights))
+				normalized.Provider = provider.Name()
+				offers = append(offers, *normalized)
 			}
 			mu.Unlock()
 		})
@@ -82,25 +232,71 @@ func (a *Aggregator) Search(ctx context.Context, city, checkin string, nights, a
 	wg.Wait()
 
 	// Log provider errors if any
-	if len(errors) > 0 {
+	if len(errs) > 0 {
 		a.logger.Error("provider search errors",
 			"city", city,
 			"failed_count", failed,
-			"errors", errors)
+			"errors", errs)
+	}
+
+	// If no provider answered, return error
+	if len(a.providers) > 0 && succeeded == 0 {
+		if len(errs) > 0 {
+			return nil, errs[0]
+		}
+		return nil, skipErr
+	}
+
+	if a.screen != nil {
+		var findings []anomaly.Finding
+		offers, findings = a.screen.Screen(city, nights, occupancy, offers)
+		for _, f := range findings {
+			action := "flagged"
+			if f.Dropped {
+				action = "dropped"
+			}
+			a.metrics.IncPriceAnomalies(f.Provider, action)
+			a.logger.Warn("price anomaly",
+				"city", city,
+				"hotel_id", f.HotelID,
+				"provider", f.Provider,
+				"price", f.Price,
+				"reference", f.Reference,
+				"check", f.Check,
+				"action", action)
+		}
+	}
 
-		// If all providers failed, return error
-		if failed == len(a.providers) {
-			return nil, errors[0]
+	// Dedup by canonical hotel_id, keep the best offer and every offering provider
+	hotelMap := make(map[string]types.Hotel)
+	for _, offer := range offers {
+		existing, ok := hotelMap[offer.HotelID]
+		if !ok {
+			offer.Providers = []string{offer.Provider}
+			hotelMap[offer.HotelID] = offer
+			continue
+		}
+		offeredBy := addProvider(existing.Providers, offer.Provider)
+		if BetterOffer(offer, existing) {
+			existing = offer
 		}
+		existing.Providers = offeredBy
+		hotelMap[offer.HotelID] = existing
 	}
 
 	// Convert map to slice and sort by price
 	hotels := make([]types.Hotel, 0, len(hotelMap))
 	for _, h := range hotelMap {
+		if a.content != nil {
+			a.content.Enrich(&h)
+		}
 		hotels = append(hotels, h)
 	}
 	sort.Slice(hotels, func(i, j int) bool {
-		return hotels[i].Price < hotels[j].Price
+		if hotels[i].Price != hotels[j].Price {
+			return hotels[i].Price < hotels[j].Price
+		}
+		return hotels[i].HotelID < hotels[j].HotelID
 	})
 
 	return &types.Result{
@@ -108,34 +304,172 @@ func (a *Aggregator) Search(ctx context.Context, city, checkin string, nights, a
 		ProvidersTotal:     len(a.providers),
 		ProvidersSucceeded: succeeded,
 		ProvidersFailed:    failed,
+		ProvidersSkipped:   skipped,
 	}, nil
 }
 
-func normalizeHotel(h providers.Hotel) *types.Hotel {
-	// Drop invalid data
-	hotelID := strings.TrimSpace(h.HotelID)
-	if hotelID == "" {
-		return nil
+// limit returns the longest a provider's searches may take.
+func (a *Aggregator) limit(provider string) time.Duration {
+	if timeout, ok := a.timeouts[provider]; ok {
+		return min(timeout, a.timeout)
 	}
+	return a.timeout
+}
 
-	name := strings.TrimSpace(h.Name)
-	if name == "" {
-		return nil
+// Timeout returns the current timeout of a provider's searches.
+func (a *Aggregator) Timeout(provider string) time.Duration {
+	if a.adaptive == nil {
+		return a.limit(provider)
 	}
+	return a.adaptive.Timeout(provider, a.limit(provider))
+}
 
-	if h.Price <= 0 {
-		return nil
+// observe records the latency of a provider's search, made with callCtx
+// within the search's ctx, with the timeout policy. Searches cut short by
+// their own timeout are recorded too, so that the timeout of a provider
+// that slows down grows; other failures, and searches canceled by the
+// caller, say nothing about the provider's latency. The latency includes
+// any time spent queued in the provider's bulkhead, as the timeout does, so
+// a saturated provider's timeout grows as well.
+func (a *Aggregator) observe(ctx, callCtx context.Context, provider string, latency time.Duration, err error) {
+	if a.adaptive == nil {
+		return
+	}
+	if err == nil || (errors.Is(callCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil) {
+		a.adaptive.Observe(provider, latency)
 	}
+}
 
-	currency := strings.ToUpper(strings.TrimSpace(h.Currency))
-	if currency == "" {
-		currency = "EUR"
+// ProviderTimeout describes the current timeout of a provider's searches.
+type ProviderTimeout struct {
+	Provider string `json:"provider"`
+	// TimeoutMS is the timeout of the provider's next search.
+	TimeoutMS int64 `json:"timeout_ms"`
+	// LimitMS is the longest timeout the provider may get.
+	LimitMS int64 `json:"limit_ms"`
+	// LatencyMS is the latency quantile an adaptive timeout is derived
+	// from, over Samples recent searches.
+	LatencyMS int64 `json:"latency_ms"`
+	Samples   int   `json:"samples"`
+	Adaptive  bool  `json:"adaptive"`
+}
+
+// Timeouts returns the current timeout of every provider's searches.
+func (a *Aggregator) Timeouts() []ProviderTimeout {
+	list := make([]ProviderTimeout, 0, len(a.providers))
+	for _, p := range a.providers {
+		t := ProviderTimeout{
+			Provider:  p.Name(),
+			TimeoutMS: a.Timeout(p.Name()).Milliseconds(),
+			LimitMS:   a.limit(p.Name()).Milliseconds(),
+			Adaptive:  a.adaptive != nil,
+		}
+		if a.adaptive != nil {
+			latency, samples := a.adaptive.Latency(p.Name())
+			t.LatencyMS, t.Samples = latency.Milliseconds(), samples
+		}
+		list = append(list, t)
 	}
+	return list
+}
+
+// TimeoutsHandler returns a handler serving the providers' current timeouts
+// as JSON.
+func (a *Aggregator) TimeoutsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(a.Timeouts()); err != nil {
+			a.logger.Error("failed to write provider timeouts", "error", err)
+		}
+	}
+}
+
+// BetterOffer reports whether a should replace b as the offer shown for a
+// hotel: offers not marked suspect win, then the lowest price.
+func BetterOffer(a, b types.Hotel) bool {
+	if a.Suspect != b.Suspect {
+		return !a.Suspect
+	}
+	return a.Price < b.Price
+}
+
+// addProvider adds name to the sorted provider list if not already present.
+func addProvider(names []string, name string) []string {
+	i := sort.SearchStrings(names, name)
+	if i < len(names) && names[i] == name {
+		return names
+	}
+	merged := make([]string, 0, len(names)+1)
+	merged = append(merged, names[:i]...)
+	merged = append(merged, name)
+	return append(merged, names[i:]...)
+}
+
+// stayTotal builds a hotel from a normalized offer, bringing its amounts to
+// the total for the stay so that every offer is compared on the same basis.
+func stayTotal(offer normalize.Offer, nights int) *types.Hotel {
+	h := offer.Hotel
+	multiplier := 1.0
+	if h.PriceBasis == providers.PricePerNight {
+		multiplier = float64(nights)
+	}
+	price := round2(h.Price * multiplier)
 
 	return &types.Hotel{
-		HotelID:  hotelID,
-		Name:     name,
-		Currency: currency,
-		Price:    h.Price,
+		HotelID:          h.HotelID,
+		Name:             h.Name,
+		Currency:         h.Currency,
+		Price:            price,
+		OriginalCurrency: h.Currency,
+		OriginalPrice:    price,
+		Taxes:            round2(h.Taxes * multiplier),
+		Fees:             round2(h.Fees * multiplier),
+		PriceBasis:       string(h.PriceBasis),
+		Annotations:      offer.Annotations,
+	}
+}
+
+// round2 rounds an amount to cents.
+func round2(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// convert converts a normalized hotel's amounts to the target currency in place.
+// Returns false if the offer cannot be expressed in the target currency.
+func (a *Aggregator) convert(h *types.Hotel, currency string) bool {
+	if currency == "" || h.Currency == currency {
+		return true
+	}
+
+	if a.converter == nil {
+		a.logger.Warn("dropping offer in foreign currency, no converter configured",
+			"hotel_id", h.HotelID,
+			"currency", h.Currency,
+			"target_currency", currency)
+		return false
+	}
+
+	amounts := []*float64{&h.Price, &h.Taxes, &h.Fees}
+	converted := make([]float64, len(amounts))
+	for i, amount := range amounts {
+		if *amount == 0 {
+			continue
+		}
+		var err error
+		if converted[i], err = a.converter.Convert(*amount, h.Currency, currency); err != nil {
+			a.logger.Warn("dropping offer, currency conversion failed",
+				"hotel_id", h.HotelID,
+				"currency", h.Currency,
+				"target_currency", currency,
+				"error", err)
+			return false
+		}
+	}
+
+	for i, amount := range amounts {
+		*amount = round2(converted[i])
 	}
+	h.Currency = currency
+	return true
 }
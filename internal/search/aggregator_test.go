@@ -5,14 +5,22 @@ import (
 	"errors"
 	"log/slog"
 	"os"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/alex-user-go/hotels/internal/obs"
 	"github.com/alex-user-go/hotels/internal/providers"
 	"github.com/alex-user-go/hotels/internal/search"
+	"github.com/alex-user-go/hotels/internal/search/anomaly"
+	"github.com/alex-user-go/hotels/internal/search/latency"
+	"github.com/alex-user-go/hotels/internal/search/mapping"
+	"github.com/alex-user-go/hotels/internal/search/normalize"
 )
 
+// twoAdults is the occupancy used by most tests.
+var twoAdults = providers.SingleRoom(2)
+
 // mockProvider is a test provider that returns predefined results.
 type mockProvider struct {
 	name   string
@@ -25,7 +33,7 @@ func (m *mockProvider) Name() string {
 	return m.name
 }
 
-func (m *mockProvider) Search(ctx context.Context, city, checkin string, nights, adults int) ([]providers.Hotel, error) {
+func (m *mockProvider) Search(ctx context.Context, city, checkin string, nights int, occupancy providers.Occupancy) ([]providers.Hotel, error) {
 	if m.delay > 0 {
 		select {
 		case <-time.After(m.delay):
@@ -58,7 +66,7 @@ func TestAggregator_Search_Merging(t *testing.T) {
 	metrics := obs.NewMetrics(logger)
 	agg := search.NewAggregator(providers, 2*time.Second, metrics, logger)
 
-	result, err := agg.Search(context.Background(), "paris", "2025-12-01", 2, 2)
+	result, err := agg.Search(context.Background(), "paris", "2025-12-01", 2, twoAdults, "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -110,7 +118,7 @@ func TestAggregator_Search_Deduplication(t *testing.T) {
 	metrics := obs.NewMetrics(logger)
 	agg := search.NewAggregator(providers, 2*time.Second, metrics, logger)
 
-	result, err := agg.Search(context.Background(), "paris", "2025-12-01", 2, 2)
+	result, err := agg.Search(context.Background(), "paris", "2025-12-01", 2, twoAdults, "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -128,6 +136,9 @@ func TestAggregator_Search_Deduplication(t *testing.T) {
 			if h.Price != 120 {
 				t.Errorf("expected H001 price 120 (lowest), got %v", h.Price)
 			}
+			if len(h.Providers) != 2 || h.Providers[0] != "provider1" || h.Providers[1] != "provider2" {
+				t.Errorf("expected H001 offered by [provider1 provider2], got %v", h.Providers)
+			}
 		}
 	}
 	if !h001Found {
@@ -157,7 +168,7 @@ func TestAggregator_Search_Timeout(t *testing.T) {
 	metrics := obs.NewMetrics(logger)
 	agg := search.NewAggregator(providers, 500*time.Millisecond, metrics, logger) // 500ms timeout
 
-	result, err := agg.Search(context.Background(), "paris", "2025-12-01", 2, 2)
+	result, err := agg.Search(context.Background(), "paris", "2025-12-01", 2, twoAdults, "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -200,7 +211,7 @@ func TestAggregator_Search_PartialFailure(t *testing.T) {
 	metrics := obs.NewMetrics(logger)
 	agg := search.NewAggregator(providers, 2*time.Second, metrics, logger)
 
-	result, err := agg.Search(context.Background(), "paris", "2025-12-01", 2, 2)
+	result, err := agg.Search(context.Background(), "paris", "2025-12-01", 2, twoAdults, "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -230,7 +241,7 @@ func TestAggregator_Search_AllProvidersFail(t *testing.T) {
 	metrics := obs.NewMetrics(logger)
 	agg := search.NewAggregator(providers, 2*time.Second, metrics, logger)
 
-	result, err := agg.Search(context.Background(), "paris", "2025-12-01", 2, 2)
+	result, err := agg.Search(context.Background(), "paris", "2025-12-01", 2, twoAdults, "")
 	if err == nil {
 		t.Fatal("expected error when all providers fail, got nil")
 	}
@@ -259,7 +270,7 @@ func TestAggregator_Search_InvalidDataFiltered(t *testing.T) {
 	metrics := obs.NewMetrics(logger)
 	agg := search.NewAggregator(providers, 2*time.Second, metrics, logger)
 
-	result, err := agg.Search(context.Background(), "paris", "2025-12-01", 2, 2)
+	result, err := agg.Search(context.Background(), "paris", "2025-12-01", 2, twoAdults, "")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -295,7 +306,7 @@ func TestAggregator_Search_ContextCancellation(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	cancel() // Cancel immediately
 
-	result, err := agg.Search(ctx, "paris", "2025-12-01", 2, 2)
+	result, err := agg.Search(ctx, "paris", "2025-12-01", 2, twoAdults, "")
 	if err == nil {
 		t.Fatal("expected error from cancelled context, got nil")
 	}
@@ -304,3 +315,370 @@ func TestAggregator_Search_ContextCancellation(t *testing.T) {
 		t.Errorf("expected nil result from cancelled context, got %v", result)
 	}
 }
+
+// fixedConverter converts using fixed rates relative to EUR.
+type fixedConverter map[string]float64
+
+func (c fixedConverter) Convert(amount float64, from, to string) (float64, error) {
+	fromRate, ok := c[from]
+	if !ok {
+		return 0, errors.New("unknown currency " + from)
+	}
+	toRate, ok := c[to]
+	if !ok {
+		return 0, errors.New("unknown currency " + to)
+	}
+	return amount / fromRate * toRate, nil
+}
+
+func TestAggregator_Search_CurrencyConversion(t *testing.T) {
+	providers := []providers.Provider{
+		&mockProvider{
+			name: "provider1",
+			hotels: []providers.Hotel{
+				{HotelID: "H001", Name: "Hotel A", Currency: "EUR", Price: 140},
+				{HotelID: "H002", Name: "Hotel B", Currency: "GBP", Price: 100},
+			},
+		},
+		&mockProvider{
+			name: "provider2",
+			hotels: []providers.Hotel{
+				{HotelID: "H001", Name: "Hotel A", Currency: "USD", Price: 150}, // ~138.89 EUR, cheaper
+				{HotelID: "H003", Name: "Hotel C", Currency: "JPY", Price: 100}, // Unknown currency, dropped
+			},
+		},
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := obs.NewMetrics(logger)
+	converter := fixedConverter{"EUR": 1, "USD": 1.08, "GBP": 0.8}
+	agg := search.NewAggregator(providers, 2*time.Second, metrics, logger, search.WithConverter(converter))
+
+	result, err := agg.Search(context.Background(), "paris", "2025-12-01", 2, twoAdults, "EUR")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Hotels) != 2 {
+		t.Fatalf("expected 2 hotels, got %d", len(result.Hotels))
+	}
+
+	// GBP 100 = EUR 125, cheapest after conversion
+	first := result.Hotels[0]
+	if first.HotelID != "H002" || first.Currency != "EUR" || first.Price != 125 {
+		t.Errorf("expected H002 at EUR 125 first, got %s at %s %v", first.HotelID, first.Currency, first.Price)
+	}
+	if first.OriginalCurrency != "GBP" || first.OriginalPrice != 100 {
+		t.Errorf("expected original GBP 100, got %s %v", first.OriginalCurrency, first.OriginalPrice)
+	}
+
+	// H001 dedup must keep the USD offer, which is cheaper once converted
+	second := result.Hotels[1]
+	if second.HotelID != "H001" || second.OriginalCurrency != "USD" {
+		t.Errorf("expected H001 from USD offer, got %s from %s", second.HotelID, second.OriginalCurrency)
+	}
+}
+
+func TestAggregator_Search_ResolvesHotelIdentity(t *testing.T) {
+	providers := []providers.Provider{
+		&mockProvider{
+			name: "provider1",
+			hotels: []providers.Hotel{
+				{HotelID: "H001", Name: "Grand Hotel", Currency: "EUR", Price: 120},
+			},
+		},
+		&mockProvider{
+			name: "provider2",
+			hotels: []providers.Hotel{
+				{HotelID: "GH-100", Name: "The Grand Hotel", Currency: "EUR", Price: 110},
+				{HotelID: "H001", Name: "Unrelated Hotel", Currency: "EUR", Price: 90},
+			},
+		},
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := obs.NewMetrics(logger)
+	resolver, err := mapping.New([]mapping.Hotel{
+		{ID: "C1", Name: "Grand Hotel", Providers: map[string]string{"provider1": "H001", "provider2": "GH-100"}},
+	}, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	agg := search.NewAggregator(providers, 2*time.Second, metrics, logger, search.WithResolver(resolver))
+
+	result, err := agg.Search(context.Background(), "paris", "2025-12-01", 2, twoAdults, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Hotels) != 2 {
+		t.Fatalf("expected 2 hotels, got %d", len(result.Hotels))
+	}
+
+	// provider2's H001 is a different hotel and must not be merged with provider1's
+	unmapped := result.Hotels[0]
+	if unmapped.HotelID != "provider2:H001" || len(unmapped.Providers) != 1 {
+		t.Errorf("expected provider-scoped provider2:H001, got %s from %v", unmapped.HotelID, unmapped.Providers)
+	}
+
+	merged := result.Hotels[1]
+	if merged.HotelID != "C1" || merged.Name != "Grand Hotel" || merged.Price != 110 {
+		t.Errorf("expected C1 Grand Hotel at 110, got %s %q at %v", merged.HotelID, merged.Name, merged.Price)
+	}
+	if len(merged.Providers) != 2 {
+		t.Errorf("expected C1 from both providers, got %v", merged.Providers)
+	}
+}
+
+func TestAggregator_Search_PriceBasis(t *testing.T) {
+	providers := []providers.Provider{
+		&mockProvider{
+			name: "per_stay",
+			hotels: []providers.Hotel{
+				{HotelID: "H001", Name: "Hotel A", Currency: "EUR", Price: 330, Nights: 3, PriceBasis: providers.PricePerStay},
+				{HotelID: "H002", Name: "Hotel B", Currency: "EUR", Price: 100, Nights: 1}, // Quoted for another stay
+			},
+		},
+		&mockProvider{
+			name: "per_night",
+			hotels: []providers.Hotel{
+				// 3 × 100 = 300 for the stay, cheaper than the per-stay offer of 330
+				{HotelID: "H001", Name: "Hotel A", Currency: "USD", Price: 108, Taxes: 10.8, Fees: 5.4, PriceBasis: providers.PricePerNight},
+				{HotelID: "H003", Name: "Hotel C", Currency: "EUR", Price: 90, PriceBasis: "per_week"}, // Unknown basis
+			},
+		},
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := obs.NewMetrics(logger)
+	converter := fixedConverter{"EUR": 1, "USD": 1.08}
+	agg := search.NewAggregator(providers, 2*time.Second, metrics, logger, search.WithConverter(converter))
+
+	result, err := agg.Search(context.Background(), "paris", "2025-12-01", 3, twoAdults, "EUR")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(result.Hotels) != 1 {
+		t.Fatalf("expected 1 hotel, got %+v", result.Hotels)
+	}
+
+	h := result.Hotels[0]
+	if h.PriceBasis != "per_night" || h.OriginalPrice != 324 || h.OriginalCurrency != "USD" {
+		t.Errorf("expected per-night USD offer totalling 324, got %s %s %v", h.PriceBasis, h.OriginalCurrency, h.OriginalPrice)
+	}
+	if h.Price != 300 || h.PricePerNight != 100 {
+		t.Errorf("expected EUR 300 total and 100 per night, got %v and %v", h.Price, h.PricePerNight)
+	}
+	if h.Taxes != 30 || h.Fees != 15 {
+		t.Errorf("expected taxes 30 and fees 15 for the stay, got %v and %v", h.Taxes, h.Fees)
+	}
+	if len(h.Providers) != 2 {
+		t.Errorf("expected both providers to be merged, got %v", h.Providers)
+	}
+}
+
+func TestAggregator_Search_PriceAnomalies(t *testing.T) {
+	offers := func(name string, price float64) *mockProvider {
+		return &mockProvider{name: name, hotels: []providers.Hotel{
+			{HotelID: "H001", Name: "Hotel A", Currency: "EUR", Price: price},
+		}}
+	}
+	provs := []providers.Provider{offers("provider1", 100), offers("provider2", 110), offers("provider3", 3)}
+
+	tests := []struct {
+		name          string
+		action        anomaly.Action
+		wantPrice     float64
+		wantProviders int
+	}{
+		// The suspect offer is kept but not chosen over plausible ones
+		{name: "flag", action: anomaly.ActionFlag, wantPrice: 100, wantProviders: 3},
+		{name: "drop", action: anomaly.ActionDrop, wantPrice: 100, wantProviders: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+			metrics := obs.NewMetrics(logger)
+			cfg := anomaly.DefaultConfig()
+			cfg.Action = tt.action
+			agg := search.NewAggregator(provs, 2*time.Second, metrics, logger,
+				search.WithPriceScreen(anomaly.NewDetector(cfg)))
+
+			result, err := agg.Search(context.Background(), "paris", "2025-12-01", 2, twoAdults, "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(result.Hotels) != 1 {
+				t.Fatalf("expected 1 hotel, got %d", len(result.Hotels))
+			}
+			h := result.Hotels[0]
+			if h.Price != tt.wantPrice || h.Suspect {
+				t.Errorf("price = %v (suspect %v), want %v", h.Price, h.Suspect, tt.wantPrice)
+			}
+			if len(h.Providers) != tt.wantProviders {
+				t.Errorf("providers = %v, want %d", h.Providers, tt.wantProviders)
+			}
+
+			anomalies := metrics.Snapshot().PriceAnomalies
+			if len(anomalies) != 1 || anomalies[0].Provider != "provider3" || anomalies[0].Count != 1 {
+				t.Errorf("anomaly metrics = %+v", anomalies)
+			}
+		})
+	}
+}
+
+func TestAggregator_Search_Pipelines(t *testing.T) {
+	offers := []providers.Hotel{
+		{HotelID: "H001", Name: "Hotel  A", Currency: "eur", Price: 100},
+		{HotelID: "H002", Name: "Hotel B", Currency: "EUR", Price: -1},
+		{HotelID: "", Name: "Hotel C", Currency: "EUR", Price: 100},
+	}
+	providersList := []providers.Provider{
+		&mockProvider{name: "strict", hotels: offers},
+		&mockProvider{name: "lenient", hotels: offers},
+	}
+
+	registry := normalize.Registry{}
+	registry.Register(normalize.Standard(nil, nil)...)
+	lenient, err := registry.Build([]string{"currency"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := obs.NewMetrics(logger)
+	agg := search.NewAggregator(providersList, 2*time.Second, metrics, logger,
+		search.WithPipeline("lenient", lenient))
+
+	result, err := agg.Search(context.Background(), "paris", "2025-12-01", 2, twoAdults, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The lenient provider skips the trim, price and name steps
+	var names []string
+	for _, h := range result.Hotels {
+		names = append(names, h.Provider+":"+h.Name)
+	}
+	slices.Sort(names)
+	want := []string{"lenient:Hotel  A", "lenient:Hotel B", "lenient:Hotel C"}
+	if !slices.Equal(names, want) {
+		t.Errorf("hotels = %v, want %v", names, want)
+	}
+
+	drops := metrics.Snapshot().NormalizationDrops
+	wantDrops := []obs.NormalizationDropCount{
+		{Provider: "strict", Step: normalize.StepPrice, Count: 1},
+		{Provider: "strict", Step: normalize.StepTrim, Count: 1},
+	}
+	if !slices.Equal(drops, wantDrops) {
+		t.Errorf("drops = %+v, want %+v", drops, wantDrops)
+	}
+}
+
+func TestAggregator_Search_BudgetExhausted(t *testing.T) {
+	exhausted := providers.NewBudgetedProvider(&mockProvider{name: "limited"}, providers.WithDailyQuota(1))
+	_, _ = exhausted.Search(context.Background(), "paris", "2025-12-01", 2, twoAdults)
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := obs.NewMetrics(logger)
+	agg := search.NewAggregator([]providers.Provider{
+		exhausted,
+		&mockProvider{name: "provider1", hotels: []providers.Hotel{{HotelID: "H001", Name: "Hotel A", Currency: "EUR", Price: 100}}},
+		&mockProvider{name: "provider2", err: errors.New("provider down")},
+	}, 2*time.Second, metrics, logger)
+
+	result, err := agg.Search(context.Background(), "paris", "2025-12-01", 2, twoAdults, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ProvidersSucceeded != 1 || result.ProvidersFailed != 1 || result.ProvidersSkipped != 1 {
+		t.Errorf("succeeded/failed/skipped = %d/%d/%d, want 1/1/1",
+			result.ProvidersSucceeded, result.ProvidersFailed, result.ProvidersSkipped)
+	}
+
+	snapshot := metrics.Snapshot()
+	if snapshot.ProviderErrors != 1 {
+		t.Errorf("provider errors = %d, want 1 without the skipped provider", snapshot.ProviderErrors)
+	}
+	if !slices.Equal(snapshot.BudgetSkips, []obs.BudgetSkipCount{{Provider: "limited", Count: 1}}) {
+		t.Errorf("budget skips = %v, want 1 for limited", snapshot.BudgetSkips)
+	}
+
+	// Searches with every provider skipped fail
+	agg = search.NewAggregator([]providers.Provider{exhausted}, 2*time.Second, metrics, logger)
+	if _, err := agg.Search(context.Background(), "paris", "2025-12-01", 2, twoAdults, ""); !errors.Is(err, providers.ErrBudgetExhausted) {
+		t.Errorf("error = %v, want ErrBudgetExhausted", err)
+	}
+}
+
+func TestAggregator_Search_ProviderTimeout(t *testing.T) {
+	hotels := []providers.Hotel{{HotelID: "H001", Name: "Hotel A", Currency: "EUR", Price: 100}}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := obs.NewMetrics(logger)
+	agg := search.NewAggregator([]providers.Provider{
+		&mockProvider{name: "fast", hotels: hotels, delay: 100 * time.Millisecond},
+		&mockProvider{name: "slow", hotels: hotels, delay: 100 * time.Millisecond},
+	}, 2*time.Second, metrics, logger,
+		search.WithProviderTimeout("fast", time.Second),
+		search.WithProviderTimeout("slow", 20*time.Millisecond),
+	)
+
+	result, err := agg.Search(context.Background(), "paris", "2025-12-01", 2, twoAdults, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.ProvidersSucceeded != 1 || result.ProvidersFailed != 1 {
+		t.Errorf("succeeded/failed = %d/%d, want 1/1", result.ProvidersSucceeded, result.ProvidersFailed)
+	}
+	if len(result.Hotels) != 1 || !slices.Equal(result.Hotels[0].Providers, []string{"fast"}) {
+		t.Errorf("hotels = %+v, want the fast provider's offer only", result.Hotels)
+	}
+}
+
+func TestAggregator_Search_AdaptiveTimeout(t *testing.T) {
+	hotels := []providers.Hotel{{HotelID: "H001", Name: "Hotel A", Currency: "EUR", Price: 100}}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	metrics := obs.NewMetrics(logger)
+	provider := &mockProvider{name: "quick", hotels: hotels, delay: 10 * time.Millisecond}
+	cfg := latency.Config{Quantile: 0.99, Factor: 3, Floor: 5 * time.Millisecond, MinSamples: 3, WindowSize: 10}
+	agg := search.NewAggregator([]providers.Provider{provider}, 2*time.Second, metrics, logger,
+		search.WithAdaptiveTimeouts(latency.NewTracker(cfg)),
+	)
+
+	// Until enough searches are seen, the search timeout applies
+	if got := agg.Timeout("quick"); got != 2*time.Second {
+		t.Errorf("timeout before warm-up = %v, want 2s", got)
+	}
+	for range 3 {
+		if _, err := agg.Search(context.Background(), "paris", "2025-12-01", 2, twoAdults, ""); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	timeout := agg.Timeout("quick")
+	if timeout < 30*time.Millisecond || timeout > time.Second {
+		t.Fatalf("timeout after warm-up = %v, want about three times the latency", timeout)
+	}
+
+	// A hanging provider is given up on after the adaptive timeout
+	provider.delay = time.Second
+	start := time.Now()
+	if _, err := agg.Search(context.Background(), "paris", "2025-12-01", 2, twoAdults, ""); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > timeout+500*time.Millisecond {
+		t.Errorf("search took %v, want about %v", elapsed, timeout)
+	}
+
+	// The timed-out search counts, so the timeout grows
+	timeouts := agg.Timeouts()
+	if len(timeouts) != 1 || !timeouts[0].Adaptive || timeouts[0].Samples != 4 || timeouts[0].LimitMS != 2000 {
+		t.Errorf("timeouts = %+v, want 4 samples within a 2s limit", timeouts)
+	}
+	if got := agg.Timeout("quick"); got <= timeout {
+		t.Errorf("timeout after a timed-out search = %v, want more than %v", got, timeout)
+	}
+}
@@ -0,0 +1,252 @@
+// Package anomaly detects offers whose price is out of line with comparable
+// offers: other providers' offers for the same hotel or, failing enough of
+// those, recent offers in the same city.
+package anomaly
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/alex-user-go/hotels/internal/providers"
+	"github.com/alex-user-go/hotels/internal/search/types"
+)
+
+// Action is what happens to an anomalous offer.
+type Action string
+
+const (
+	// ActionFlag keeps the offer and marks it as suspect.
+	ActionFlag Action = "flag"
+	// ActionDrop removes the offer.
+	ActionDrop Action = "drop"
+)
+
+// ParseAction parses an action name.
+func ParseAction(s string) (Action, error) {
+	switch a := Action(strings.ToLower(strings.TrimSpace(s))); a {
+	case ActionFlag, ActionDrop:
+		return a, nil
+	}
+	return "", fmt.Errorf("unknown anomaly action %q, expected flag or drop", s)
+}
+
+// Checks that can find an offer anomalous.
+const (
+	// CheckPeers compares an offer with all offers for the same hotel.
+	CheckPeers = "peers"
+	// CheckCity compares an offer's nightly price with recent offers in the city.
+	CheckCity = "city"
+)
+
+// Config controls anomaly detection. A factor F flags prices above F times
+// or below 1/F times the reference price.
+type Config struct {
+	Action Action
+	// PeerFactor bounds the deviation from the median offer for the hotel.
+	PeerFactor float64
+	// MinPeers is the number of offers for a hotel, including the one being
+	// checked, needed to compare it with its peers.
+	MinPeers int
+	// CityFactor bounds the deviation from the rolling city median for the
+	// same occupancy.
+	CityFactor float64
+	// MinCitySamples is the number of recent city prices needed before the
+	// city median is used.
+	MinCitySamples int
+	// WindowSize is the number of recent nightly prices kept per city,
+	// occupancy and currency.
+	WindowSize int
+	// MaxWindows caps the number of city, occupancy and currency windows
+	// kept; the least recently used one is forgotten to make room for a new
+	// one.
+	MaxWindows int
+}
+
+// DefaultConfig returns the default anomaly detection settings.
+func DefaultConfig() Config {
+	return Config{
+		Action:         ActionFlag,
+		PeerFactor:     3,
+		MinPeers:       3,
+		CityFactor:     5,
+		MinCitySamples: 20,
+		WindowSize:     500,
+		MaxWindows:     1000,
+	}
+}
+
+// Finding is an offer found anomalous.
+type Finding struct {
+	HotelID   string
+	Provider  string
+	Price     float64
+	Reference float64
+	Check     string
+	Dropped   bool
+}
+
+// Detector screens offers for anomalous prices. It keeps a rolling window of
+// nightly prices per city, occupancy and currency, fed with the offers it
+// accepts, since prices for more guests or rooms aren't comparable.
+type Detector struct {
+	cfg Config
+
+	mu      sync.Mutex
+	windows map[string]*window
+	tick    uint64 // Incremented on every window use, for eviction
+}
+
+// NewDetector creates a Detector.
+func NewDetector(cfg Config) *Detector {
+	return &Detector{
+		cfg:     cfg,
+		windows: make(map[string]*window),
+	}
+}
+
+// Screen checks the offers of one search. Anomalous offers are marked
+// Suspect, or removed if the action is ActionDrop; the kept offers are
+// returned along with a finding for every anomalous one. Offers are only
+// compared with offers in the same currency, and with city prices for the
+// same occupancy.
+func (d *Detector) Screen(city string, nights int, occupancy providers.Occupancy, offers []types.Hotel) ([]types.Hotel, []Finding) {
+	if nights <= 0 {
+		nights = 1
+	}
+	scope := strings.ToLower(strings.TrimSpace(city)) + "|" + occupancy.Canonical().String()
+
+	type group struct{ hotel, currency string }
+	prices := make(map[group][]float64)
+	for _, o := range offers {
+		g := group{o.HotelID, o.Currency}
+		prices[g] = append(prices[g], o.Price)
+	}
+	peerMedians := make(map[group]float64, len(prices))
+	for g, p := range prices {
+		if len(p) >= d.cfg.MinPeers {
+			peerMedians[g] = median(p)
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	cityMedians := make(map[string]float64)
+	for _, o := range offers {
+		key := scope + "|" + o.Currency
+		if _, ok := cityMedians[key]; ok {
+			continue
+		}
+		if w := d.windows[key]; w != nil && len(w.prices) >= d.cfg.MinCitySamples {
+			d.touch(w)
+			cityMedians[key] = median(w.prices)
+		} else {
+			cityMedians[key] = 0
+		}
+	}
+
+	kept := offers[:0:0]
+	var findings []Finding
+	for _, o := range offers {
+		nightly := o.Price / float64(nights)
+		key := scope + "|" + o.Currency
+
+		var f *Finding
+		if ref, ok := peerMedians[group{o.HotelID, o.Currency}]; ok {
+			if deviates(o.Price, ref, d.cfg.PeerFactor) {
+				f = &Finding{Reference: ref, Check: CheckPeers}
+			}
+		} else if ref := cityMedians[key]; ref > 0 && deviates(nightly, ref, d.cfg.CityFactor) {
+			f = &Finding{Reference: ref * float64(nights), Check: CheckCity}
+		}
+
+		if f == nil {
+			d.window(key).add(nightly)
+			kept = append(kept, o)
+			continue
+		}
+
+		f.HotelID, f.Provider, f.Price = o.HotelID, o.Provider, o.Price
+		f.Dropped = d.cfg.Action == ActionDrop
+		findings = append(findings, *f)
+		if !f.Dropped {
+			o.Suspect = true
+			kept = append(kept, o)
+		}
+	}
+
+	return kept, findings
+}
+
+// window returns the rolling window for key, evicting the least recently
+// used window if a new one would exceed the cap. Must be called with d.mu
+// held.
+func (d *Detector) window(key string) *window {
+	w := d.windows[key]
+	if w == nil {
+		if d.cfg.MaxWindows > 0 && len(d.windows) >= d.cfg.MaxWindows {
+			d.evict()
+		}
+		w = &window{size: d.cfg.WindowSize}
+		d.windows[key] = w
+	}
+	d.touch(w)
+	return w
+}
+
+// touch marks w as just used. Must be called with d.mu held.
+func (d *Detector) touch(w *window) {
+	d.tick++
+	w.used = d.tick
+}
+
+// evict forgets the least recently used window. Must be called with d.mu
+// held.
+func (d *Detector) evict() {
+	var oldest string
+	var used uint64
+	for key, w := range d.windows {
+		if oldest == "" || w.used < used {
+			oldest, used = key, w.used
+		}
+	}
+	delete(d.windows, oldest)
+}
+
+// deviates reports whether price is more than factor times away from ref.
+func deviates(price, ref, factor float64) bool {
+	return factor > 0 && (price > ref*factor || price*factor < ref)
+}
+
+// median returns the median of values without modifying them.
+func median(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+// window is a fixed-size ring of recent prices.
+type window struct {
+	prices []float64
+	next   int
+	size   int
+	used   uint64
+}
+
+func (w *window) add(price float64) {
+	if w.size <= 0 {
+		return
+	}
+	if len(w.prices) < w.size {
+		w.prices = append(w.prices, price)
+		return
+	}
+	w.prices[w.next] = price
+	w.next = (w.next + 1) % w.size
+}
@@ -0,0 +1,178 @@
+package anomaly_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alex-user-go/hotels/internal/providers"
+	"github.com/alex-user-go/hotels/internal/search/anomaly"
+	"github.com/alex-user-go/hotels/internal/search/types"
+)
+
+func offer(hotelID, provider string, price float64) types.Hotel {
+	return types.Hotel{HotelID: hotelID, Provider: provider, Currency: "EUR", Price: price}
+}
+
+func TestDetector_Screen_Peers(t *testing.T) {
+	tests := []struct {
+		name        string
+		action      anomaly.Action
+		offers      []types.Hotel
+		wantKept    int
+		wantFinding string // provider of the anomalous offer, if any
+	}{
+		{
+			name:     "consistent prices",
+			action:   anomaly.ActionFlag,
+			offers:   []types.Hotel{offer("H1", "a", 100), offer("H1", "b", 120), offer("H1", "c", 90)},
+			wantKept: 3,
+		},
+		{
+			name:        "too expensive is flagged",
+			action:      anomaly.ActionFlag,
+			offers:      []types.Hotel{offer("H1", "a", 100), offer("H1", "b", 110), offer("H1", "c", 1000)},
+			wantKept:    3,
+			wantFinding: "c",
+		},
+		{
+			name:        "too cheap is dropped",
+			action:      anomaly.ActionDrop,
+			offers:      []types.Hotel{offer("H1", "a", 100), offer("H1", "b", 110), offer("H1", "c", 1)},
+			wantKept:    2,
+			wantFinding: "c",
+		},
+		{
+			name:     "too few peers",
+			action:   anomaly.ActionDrop,
+			offers:   []types.Hotel{offer("H1", "a", 100), offer("H1", "b", 1000)},
+			wantKept: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := anomaly.DefaultConfig()
+			cfg.Action = tt.action
+			d := anomaly.NewDetector(cfg)
+
+			kept, findings := d.Screen("paris", 2, providers.SingleRoom(2), tt.offers)
+
+			if len(kept) != tt.wantKept {
+				t.Errorf("kept %d offers, want %d", len(kept), tt.wantKept)
+			}
+			if tt.wantFinding == "" {
+				if len(findings) != 0 {
+					t.Fatalf("expected no findings, got %+v", findings)
+				}
+				return
+			}
+			if len(findings) != 1 || findings[0].Provider != tt.wantFinding || findings[0].Check != anomaly.CheckPeers {
+				t.Fatalf("findings = %+v, want one peers finding for %q", findings, tt.wantFinding)
+			}
+			if findings[0].Reference != 110 && findings[0].Reference != 100 {
+				t.Errorf("reference = %v, want the median offer", findings[0].Reference)
+			}
+			for _, o := range kept {
+				if o.Suspect != (o.Provider == tt.wantFinding) {
+					t.Errorf("offer from %s: suspect = %v", o.Provider, o.Suspect)
+				}
+			}
+		})
+	}
+}
+
+func TestDetector_Screen_CityMedian(t *testing.T) {
+	cfg := anomaly.DefaultConfig()
+	cfg.MinCitySamples = 5
+	d := anomaly.NewDetector(cfg)
+
+	// Not enough history yet: nothing is compared
+	if _, findings := d.Screen("paris", 1, providers.SingleRoom(2), []types.Hotel{offer("H9", "a", 5000)}); len(findings) != 0 {
+		t.Fatalf("expected no findings without history, got %+v", findings)
+	}
+
+	// Build up the city history with nightly prices around 100
+	var history []types.Hotel
+	for i := range 6 {
+		history = append(history, offer(fmt.Sprintf("H%d", 100+i), "a", 200+float64(i)*10))
+	}
+	if _, findings := d.Screen("Paris", 2, providers.SingleRoom(2), history); len(findings) != 0 {
+		t.Fatalf("unexpected findings: %+v", findings)
+	}
+
+	kept, findings := d.Screen("paris", 1, providers.SingleRoom(2), []types.Hotel{offer("H1", "a", 95), offer("H2", "b", 900)})
+	if len(kept) != 2 {
+		t.Errorf("kept %d offers, want 2", len(kept))
+	}
+	if len(findings) != 1 || findings[0].HotelID != "H2" || findings[0].Check != anomaly.CheckCity {
+		t.Fatalf("findings = %+v, want one city finding for H2", findings)
+	}
+
+	// Other currencies have their own history
+	if _, findings := d.Screen("paris", 1, providers.SingleRoom(2), []types.Hotel{{HotelID: "H3", Currency: "JPY", Price: 15000}}); len(findings) != 0 {
+		t.Errorf("expected no findings for an unseen currency, got %+v", findings)
+	}
+}
+
+func TestDetector_Screen_CityMedianPerOccupancy(t *testing.T) {
+	cfg := anomaly.DefaultConfig()
+	cfg.MinCitySamples = 5
+	d := anomaly.NewDetector(cfg)
+
+	// Single-room history with nightly prices around 100
+	var history []types.Hotel
+	for i := range 6 {
+		history = append(history, offer(fmt.Sprintf("H%d", 100+i), "a", 100+float64(i)*5))
+	}
+	d.Screen("paris", 1, providers.SingleRoom(2), history)
+
+	// Four rooms cost far more, but aren't compared with single rooms
+	fourRooms := providers.Occupancy{{Adults: 2}, {Adults: 2}, {Adults: 2}, {Adults: 2}}
+	kept, findings := d.Screen("paris", 1, fourRooms, []types.Hotel{offer("H1", "a", 650)})
+	if len(findings) != 0 || len(kept) != 1 || kept[0].Suspect {
+		t.Errorf("four-room offer flagged: kept %+v, findings %+v", kept, findings)
+	}
+
+	// The same price for a single room is still anomalous
+	if _, findings := d.Screen("paris", 1, providers.SingleRoom(2), []types.Hotel{offer("H1", "a", 650)}); len(findings) != 1 {
+		t.Errorf("findings = %+v, want one city finding", findings)
+	}
+}
+
+func TestDetector_Screen_MaxWindows(t *testing.T) {
+	cfg := anomaly.DefaultConfig()
+	cfg.MinCitySamples = 1
+	cfg.MaxWindows = 2
+	d := anomaly.NewDetector(cfg)
+
+	for _, city := range []string{"paris", "rome", "paris", "vienna"} {
+		d.Screen(city, 1, providers.SingleRoom(2), []types.Hotel{offer("H1", "a", 100)})
+	}
+
+	// Rome was least recently used, so Vienna took its place
+	tests := []struct {
+		city        string
+		wantFinding bool
+	}{
+		{city: "paris", wantFinding: true},
+		{city: "vienna", wantFinding: true},
+		{city: "rome", wantFinding: false},
+	}
+	for _, tt := range tests {
+		_, findings := d.Screen(tt.city, 1, providers.SingleRoom(2), []types.Hotel{offer("H2", "b", 5000)})
+		if got := len(findings) > 0; got != tt.wantFinding {
+			t.Errorf("%s: findings = %+v, want finding %v", tt.city, findings, tt.wantFinding)
+		}
+	}
+}
+
+func TestParseAction(t *testing.T) {
+	for _, s := range []string{"flag", " DROP "} {
+		if _, err := anomaly.ParseAction(s); err != nil {
+			t.Errorf("ParseAction(%q) failed: %v", s, err)
+		}
+	}
+	if _, err := anomaly.ParseAction("ignore"); err == nil {
+		t.Error("expected error for unknown action")
+	}
+}
@@ -50,8 +50,9 @@ func (c *Cache) Close() {
 }
 
 // Key generates a cache key from search parameters.
-func (c *Cache) Key(city, checkin string, nights, adults int) string {
-	return fmt.Sprintf("%s:%s:%d:%d", city, checkin, nights, adults)
+// occupancy is the canonical string form of the search occupancy.
+func (c *Cache) Key(city, checkin string, nights int, occupancy, currency string) string {
+	return fmt.Sprintf("%s:%s:%d:%s:%s", city, checkin, nights, occupancy, currency)
 }
 
 // GetOrFetch retrieves from cache or executes the fetch function.
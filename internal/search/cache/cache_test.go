@@ -13,36 +13,47 @@ import (
 
 func TestCache_Key(t *testing.T) {
 	tests := []struct {
-		name    string
-		city    string
-		checkin string
-		nights  int
-		adults  int
-		want    string
+		name      string
+		city      string
+		checkin   string
+		nights    int
+		occupancy string
+		currency  string
+		want      string
 	}{
 		{
-			name:    "basic key",
-			city:    "paris",
-			checkin: "2024-01-15",
-			nights:  3,
-			adults:  2,
-			want:    "paris:2024-01-15:3:2",
+			name:      "basic key",
+			city:      "paris",
+			checkin:   "2024-01-15",
+			nights:    3,
+			occupancy: "2",
+			currency:  "EUR",
+			want:      "paris:2024-01-15:3:2:EUR",
+		},
+		{
+			name:      "empty city",
+			city:      "",
+			checkin:   "2024-01-15",
+			nights:    1,
+			occupancy: "1",
+			currency:  "USD",
+			want:      ":2024-01-15:1:1:USD",
 		},
 		{
-			name:    "empty city",
-			city:    "",
-			checkin: "2024-01-15",
-			nights:  1,
-			adults:  1,
-			want:    ":2024-01-15:1:1",
+			name:      "multiple rooms with children",
+			city:      "rome",
+			checkin:   "2024-01-15",
+			nights:    2,
+			occupancy: "2-5,8|1",
+			currency:  "EUR",
+			want:      "rome:2024-01-15:2:2-5,8|1:EUR",
 		},
 		{
 			name:    "zero values",
 			city:    "london",
 			checkin: "",
 			nights:  0,
-			adults:  0,
-			want:    "london::0:0",
+			want:    "london::0::",
 		},
 	}
 
@@ -51,7 +62,7 @@ func TestCache_Key(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := cache.Key(tt.city, tt.checkin, tt.nights, tt.adults)
+			got := cache.Key(tt.city, tt.checkin, tt.nights, tt.occupancy, tt.currency)
 			if got != tt.want {
 				t.Errorf("Key() = %q, want %q", got, tt.want)
 			}
@@ -0,0 +1,122 @@
+// Package latency derives provider timeouts from their recent latencies, so
+// that a provider that usually answers quickly isn't waited for long when it
+// hangs.
+package latency
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Config controls adaptive timeouts. A provider's timeout is Factor times
+// the Quantile of its recent latencies, at least Floor and at most the limit
+// given by the caller.
+type Config struct {
+	// Quantile is the latency quantile timeouts are derived from, such as
+	// 0.99.
+	Quantile float64
+	// Factor is the margin applied to the quantile.
+	Factor float64
+	// Floor is the shortest timeout.
+	Floor time.Duration
+	// MinSamples is the number of recent latencies needed before timeouts
+	// are derived from them. Until then, the limit is used.
+	MinSamples int
+	// WindowSize is the number of recent latencies kept per provider.
+	WindowSize int
+}
+
+// DefaultConfig returns the default adaptive timeout settings.
+func DefaultConfig() Config {
+	return Config{
+		Quantile:   0.99,
+		Factor:     2,
+		Floor:      250 * time.Millisecond,
+		MinSamples: 20,
+		WindowSize: 200,
+	}
+}
+
+// Tracker keeps a rolling window of latencies per provider and derives
+// timeouts from them.
+type Tracker struct {
+	cfg Config
+
+	mu      sync.Mutex
+	windows map[string]*window
+}
+
+// NewTracker creates a Tracker.
+func NewTracker(cfg Config) *Tracker {
+	return &Tracker{
+		cfg:     cfg,
+		windows: make(map[string]*window),
+	}
+}
+
+// Observe records how long a search of provider took.
+func (t *Tracker) Observe(provider string, latency time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	w := t.windows[provider]
+	if w == nil {
+		w = &window{size: t.cfg.WindowSize}
+		t.windows[provider] = w
+	}
+	w.add(latency)
+}
+
+// Latency returns the configured quantile of the provider's recent
+// latencies and the number of latencies it was computed from.
+func (t *Tracker) Latency(provider string) (time.Duration, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	w := t.windows[provider]
+	if w == nil || len(w.latencies) == 0 {
+		return 0, 0
+	}
+	return quantile(w.latencies, t.cfg.Quantile), len(w.latencies)
+}
+
+// Timeout returns the timeout of the provider's next search, at most limit.
+// Providers with too few recent latencies get limit.
+func (t *Tracker) Timeout(provider string, limit time.Duration) time.Duration {
+	q, samples := t.Latency(provider)
+	if samples == 0 || samples < t.cfg.MinSamples {
+		return limit
+	}
+	timeout := time.Duration(float64(q) * t.cfg.Factor)
+	return min(max(timeout, t.cfg.Floor), limit)
+}
+
+// quantile returns the q-quantile of latencies, by the nearest-rank method,
+// without modifying them.
+func quantile(latencies []time.Duration, q float64) time.Duration {
+	sorted := slices.Clone(latencies)
+	slices.Sort(sorted)
+	rank := int(math.Ceil(q * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
+
+// window is a fixed-size ring of recent latencies.
+type window struct {
+	latencies []time.Duration
+	next      int
+	size      int
+}
+
+func (w *window) add(latency time.Duration) {
+	if w.size <= 0 {
+		return
+	}
+	if len(w.latencies) < w.size {
+		w.latencies = append(w.latencies, latency)
+		return
+	}
+	w.latencies[w.next] = latency
+	w.next = (w.next + 1) % w.size
+}
@@ -0,0 +1,87 @@
+package latency_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/search/latency"
+)
+
+func TestTracker_Timeout(t *testing.T) {
+	ms := time.Millisecond
+	cfg := latency.Config{Quantile: 0.9, Factor: 2, Floor: 50 * ms, MinSamples: 5, WindowSize: 10}
+
+	tests := []struct {
+		name      string
+		latencies []time.Duration
+		limit     time.Duration
+		want      time.Duration
+	}{
+		{
+			name:  "no latencies",
+			limit: 2 * time.Second,
+			want:  2 * time.Second,
+		},
+		{
+			name:      "too few latencies",
+			latencies: []time.Duration{100 * ms, 100 * ms, 100 * ms, 100 * ms},
+			limit:     2 * time.Second,
+			want:      2 * time.Second,
+		},
+		{
+			name:      "quantile times factor",
+			latencies: []time.Duration{100 * ms, 110 * ms, 120 * ms, 130 * ms, 140 * ms, 150 * ms, 160 * ms, 170 * ms, 180 * ms, 300 * ms},
+			limit:     2 * time.Second,
+			want:      360 * ms,
+		},
+		{
+			name:      "floor",
+			latencies: []time.Duration{ms, ms, ms, ms, ms},
+			limit:     2 * time.Second,
+			want:      50 * ms,
+		},
+		{
+			name:      "limit",
+			latencies: []time.Duration{time.Second, time.Second, time.Second, time.Second, time.Second},
+			limit:     1500 * ms,
+			want:      1500 * ms,
+		},
+		{
+			name: "old latencies roll out of the window",
+			latencies: []time.Duration{
+				time.Second, time.Second, time.Second, time.Second, time.Second,
+				100 * ms, 100 * ms, 100 * ms, 100 * ms, 100 * ms,
+				100 * ms, 100 * ms, 100 * ms, 100 * ms, 100 * ms,
+			},
+			limit: 2 * time.Second,
+			want:  200 * ms,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := latency.NewTracker(cfg)
+			for _, l := range tt.latencies {
+				tracker.Observe("p", l)
+			}
+			if got := tracker.Timeout("p", tt.limit); got != tt.want {
+				t.Errorf("Timeout = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTracker_Latency(t *testing.T) {
+	tracker := latency.NewTracker(latency.Config{Quantile: 0.5, WindowSize: 3})
+	for _, l := range []time.Duration{10, 20, 30, 40} {
+		tracker.Observe("p", l*time.Millisecond)
+	}
+
+	got, samples := tracker.Latency("p")
+	if got != 30*time.Millisecond || samples != 3 {
+		t.Errorf("Latency = %v over %d samples, want 30ms over 3", got, samples)
+	}
+	if got, samples := tracker.Latency("other"); got != 0 || samples != 0 {
+		t.Errorf("Latency of an unknown provider = %v over %d samples, want none", got, samples)
+	}
+}
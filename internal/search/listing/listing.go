@@ -0,0 +1,263 @@
+package listing
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/alex-user-go/hotels/internal/search/types"
+)
+
+// Sort orders supported by Apply.
+const (
+	SortPriceAsc  = "price_asc"
+	SortPriceDesc = "price_desc"
+	SortName      = "name"
+	SortProviders = "providers"
+	// SortDistance orders by DistanceKm; hotels without one come last.
+	SortDistance = "distance"
+)
+
+// DefaultLimit is the page size used when none is requested.
+const DefaultLimit = 20
+
+// MaxLimit is the largest accepted page size.
+const MaxLimit = 100
+
+// maxCursorProviders bounds the provider count a cursor may carry, well above
+// the number of providers a hotel can have.
+const maxCursorProviders = 1000
+
+// ErrInvalidCursor is returned when a cursor cannot be decoded or does not
+// belong to the requested sort order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Options controls sorting, filtering and pagination of search results.
+// Zero values mean "not set".
+type Options struct {
+	Sort     string
+	MinPrice float64
+	MaxPrice float64
+	Name     string
+	Provider string
+	Cursor   string
+	Limit    int
+}
+
+// Page is a single page of hotels.
+type Page struct {
+	Hotels []types.Hotel
+	// Total is the number of hotels matching the filters across all pages.
+	Total int
+	// NextCursor points after the last hotel of the page; empty on the last page.
+	NextCursor string
+}
+
+// ValidSort reports whether s is a supported sort order.
+func ValidSort(s string) bool {
+	switch s {
+	case SortPriceAsc, SortPriceDesc, SortName, SortProviders, SortDistance:
+		return true
+	}
+	return false
+}
+
+// cursor identifies the last hotel of a page by its sort key.
+// Pages resume strictly after this position, so they stay consistent even
+// when the underlying result is refetched between page requests.
+type cursor struct {
+	Sort      string  `json:"s"`
+	HotelID   string  `json:"id"`
+	Price     float64 `json:"p,omitempty"`
+	Name      string  `json:"n,omitempty"`
+	Providers int     `json:"c,omitempty"`
+	Distance  float64 `json:"d,omitempty"`
+}
+
+// Apply filters, sorts and paginates hotels. The input slice is not modified.
+func Apply(hotels []types.Hotel, opts Options) (*Page, error) {
+	sortBy := opts.Sort
+	if sortBy == "" {
+		sortBy = SortPriceAsc
+	}
+	if !ValidSort(sortBy) {
+		return nil, fmt.Errorf("unsupported sort %q", sortBy)
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	matched := Filter(hotels, opts)
+
+	less := lessFunc(sortBy)
+	sort.Slice(matched, func(i, j int) bool {
+		return less(matched[i], matched[j])
+	})
+
+	start := 0
+	if opts.Cursor != "" {
+		c, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if c.Sort != sortBy {
+			return nil, fmt.Errorf("%w: cursor was issued for sort %q", ErrInvalidCursor, c.Sort)
+		}
+		after := c.hotel()
+		start = sort.Search(len(matched), func(i int) bool {
+			return less(after, matched[i])
+		})
+	}
+
+	end := min(start+limit, len(matched))
+	page := &Page{
+		Hotels: matched[start:end],
+		Total:  len(matched),
+	}
+	if end < len(matched) {
+		page.NextCursor = encodeCursor(sortBy, matched[end-1])
+	}
+
+	return page, nil
+}
+
+// Filter returns the hotels passing the price, name and provider filters of
+// opts, in their original order. Sorting and pagination options are ignored.
+func Filter(hotels []types.Hotel, opts Options) []types.Hotel {
+	matched := make([]types.Hotel, 0, len(hotels))
+	for _, h := range hotels {
+		if matches(h, opts) {
+			matched = append(matched, h)
+		}
+	}
+	return matched
+}
+
+// matches reports whether a hotel passes all filters.
+func matches(h types.Hotel, opts Options) bool {
+	if opts.MinPrice > 0 && h.Price < opts.MinPrice {
+		return false
+	}
+	if opts.MaxPrice > 0 && h.Price > opts.MaxPrice {
+		return false
+	}
+	if opts.Name != "" && !strings.Contains(strings.ToLower(h.Name), strings.ToLower(opts.Name)) {
+		return false
+	}
+	if opts.Provider != "" && !slices.Contains(h.Providers, opts.Provider) {
+		return false
+	}
+	return true
+}
+
+// lessFunc returns a strict total order for the sort. Ties are broken by
+// hotel ID so that cursors always identify a unique position.
+func lessFunc(sortBy string) func(a, b types.Hotel) bool {
+	byID := func(a, b types.Hotel) bool { return a.HotelID < b.HotelID }
+
+	switch sortBy {
+	case SortPriceDesc:
+		return func(a, b types.Hotel) bool {
+			if a.Price != b.Price {
+				return a.Price > b.Price
+			}
+			return byID(a, b)
+		}
+	case SortName:
+		return func(a, b types.Hotel) bool {
+			an, bn := strings.ToLower(a.Name), strings.ToLower(b.Name)
+			if an != bn {
+				return an < bn
+			}
+			return byID(a, b)
+		}
+	case SortProviders:
+		// Most widely offered first, then cheapest
+		return func(a, b types.Hotel) bool {
+			if len(a.Providers) != len(b.Providers) {
+				return len(a.Providers) > len(b.Providers)
+			}
+			if a.Price != b.Price {
+				return a.Price < b.Price
+			}
+			return byID(a, b)
+		}
+	case SortDistance:
+		// Nearest first, then cheapest
+		return func(a, b types.Hotel) bool {
+			if (a.DistanceKm == nil) != (b.DistanceKm == nil) {
+				return a.DistanceKm != nil
+			}
+			if a.DistanceKm != nil && *a.DistanceKm != *b.DistanceKm {
+				return *a.DistanceKm < *b.DistanceKm
+			}
+			if a.Price != b.Price {
+				return a.Price < b.Price
+			}
+			return byID(a, b)
+		}
+	default:
+		return func(a, b types.Hotel) bool {
+			if a.Price != b.Price {
+				return a.Price < b.Price
+			}
+			return byID(a, b)
+		}
+	}
+}
+
+func encodeCursor(sortBy string, h types.Hotel) string {
+	c := cursor{Sort: sortBy, HotelID: h.HotelID}
+	switch sortBy {
+	case SortName:
+		c.Name = h.Name
+	case SortProviders:
+		c.Providers = len(h.Providers)
+		c.Price = h.Price
+	case SortDistance:
+		if h.DistanceKm != nil {
+			c.Distance = *h.DistanceKm
+		}
+		c.Price = h.Price
+	default:
+		c.Price = h.Price
+	}
+
+	data, _ := json.Marshal(c) // Marshaling a plain struct cannot fail
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (*cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.HotelID == "" {
+		return nil, ErrInvalidCursor
+	}
+	if c.Providers < 0 || c.Providers > maxCursorProviders {
+		return nil, ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// hotel builds a placeholder hotel positioned at the cursor.
+func (c *cursor) hotel() types.Hotel {
+	h := types.Hotel{
+		HotelID:   c.HotelID,
+		Name:      c.Name,
+		Price:     c.Price,
+		Providers: make([]string, c.Providers),
+	}
+	if c.Sort == SortDistance {
+		h.DistanceKm = &c.Distance
+	}
+	return h
+}
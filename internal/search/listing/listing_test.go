@@ -0,0 +1,206 @@
+package listing_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/alex-user-go/hotels/internal/search/listing"
+	"github.com/alex-user-go/hotels/internal/search/types"
+)
+
+func testHotels() []types.Hotel {
+	return []types.Hotel{
+		{HotelID: "H001", Name: "Grand Hotel", Price: 150, Providers: []string{"p1", "p2", "p3"}},
+		{HotelID: "H002", Name: "city Center Inn", Price: 120, Providers: []string{"p1", "p2"}},
+		{HotelID: "H003", Name: "Budget Stay", Price: 80, Providers: []string{"p2"}},
+		{HotelID: "H004", Name: "Luxury Palace", Price: 300, Providers: []string{"p1"}},
+		{HotelID: "H005", Name: "Seaside Resort", Price: 120, Providers: []string{"p3"}},
+	}
+}
+
+func hotelIDs(hotels []types.Hotel) []string {
+	ids := make([]string, len(hotels))
+	for i, h := range hotels {
+		ids[i] = h.HotelID
+	}
+	return ids
+}
+
+func equalIDs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestApply_SortAndFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    listing.Options
+		wantIDs []string
+	}{
+		{
+			name:    "default price ascending with id tiebreak",
+			opts:    listing.Options{},
+			wantIDs: []string{"H003", "H002", "H005", "H001", "H004"},
+		},
+		{
+			name:    "price descending",
+			opts:    listing.Options{Sort: listing.SortPriceDesc},
+			wantIDs: []string{"H004", "H001", "H002", "H005", "H003"},
+		},
+		{
+			name:    "name case insensitive",
+			opts:    listing.Options{Sort: listing.SortName},
+			wantIDs: []string{"H003", "H002", "H001", "H004", "H005"},
+		},
+		{
+			name:    "provider count",
+			opts:    listing.Options{Sort: listing.SortProviders},
+			wantIDs: []string{"H001", "H002", "H003", "H005", "H004"},
+		},
+		{
+			name:    "price range",
+			opts:    listing.Options{MinPrice: 100, MaxPrice: 150},
+			wantIDs: []string{"H002", "H005", "H001"},
+		},
+		{
+			name:    "name substring",
+			opts:    listing.Options{Name: "HOTEL"},
+			wantIDs: []string{"H001"},
+		},
+		{
+			name:    "provider",
+			opts:    listing.Options{Provider: "p3"},
+			wantIDs: []string{"H005", "H001"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := listing.Apply(testHotels(), tt.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := hotelIDs(page.Hotels); !equalIDs(got, tt.wantIDs) {
+				t.Errorf("hotels = %v, want %v", got, tt.wantIDs)
+			}
+			if page.Total != len(tt.wantIDs) {
+				t.Errorf("total = %d, want %d", page.Total, len(tt.wantIDs))
+			}
+			if page.NextCursor != "" {
+				t.Errorf("expected no next cursor, got %q", page.NextCursor)
+			}
+		})
+	}
+}
+
+func TestApply_Pagination(t *testing.T) {
+	for _, sortBy := range []string{listing.SortPriceAsc, listing.SortPriceDesc, listing.SortName, listing.SortProviders} {
+		t.Run(sortBy, func(t *testing.T) {
+			all, err := listing.Apply(testHotels(), listing.Options{Sort: sortBy})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var paged []types.Hotel
+			cursor := ""
+			for range 10 {
+				page, err := listing.Apply(testHotels(), listing.Options{Sort: sortBy, Limit: 2, Cursor: cursor})
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				paged = append(paged, page.Hotels...)
+				cursor = page.NextCursor
+				if cursor == "" {
+					break
+				}
+			}
+
+			if got, want := hotelIDs(paged), hotelIDs(all.Hotels); !equalIDs(got, want) {
+				t.Errorf("paged hotels = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestApply_CursorStableAcrossRefetch(t *testing.T) {
+	first, err := listing.Apply(testHotels(), listing.Options{Limit: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The result is refetched and a cheaper hotel appears before the cursor
+	hotels := append(testHotels(), types.Hotel{HotelID: "H000", Name: "New", Price: 10})
+
+	second, err := listing.Apply(hotels, listing.Options{Limit: 2, Cursor: first.NextCursor})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := hotelIDs(second.Hotels), []string{"H005", "H001"}; !equalIDs(got, want) {
+		t.Errorf("second page = %v, want %v", got, want)
+	}
+}
+
+// rawCursor encodes a hand-written cursor.
+func rawCursor(s string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+func TestApply_InvalidCursor(t *testing.T) {
+	page, err := listing.Apply(testHotels(), listing.Options{Limit: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		opts listing.Options
+	}{
+		{name: "garbage", opts: listing.Options{Cursor: "not-a-cursor!"}},
+		{name: "different sort", opts: listing.Options{Cursor: page.NextCursor, Sort: listing.SortName}},
+		{name: "negative provider count", opts: listing.Options{Cursor: rawCursor(`{"s":"providers","id":"H1","c":-1}`), Sort: listing.SortProviders}},
+		{name: "huge provider count", opts: listing.Options{Cursor: rawCursor(`{"s":"providers","id":"H1","c":9000000000000000000}`), Sort: listing.SortProviders}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := listing.Apply(testHotels(), tt.opts); !errors.Is(err, listing.ErrInvalidCursor) {
+				t.Errorf("error = %v, want %v", err, listing.ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestApply_SortByDistance(t *testing.T) {
+	km := func(d float64) *float64 { return &d }
+	hotels := []types.Hotel{
+		{HotelID: "H001", Price: 150, DistanceKm: km(2.5)},
+		{HotelID: "H002", Price: 120, DistanceKm: km(0.8)},
+		{HotelID: "H003", Price: 80},
+		{HotelID: "H004", Price: 100, DistanceKm: km(2.5)},
+	}
+
+	page, err := listing.Apply(hotels, listing.Options{Sort: listing.SortDistance, Limit: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := hotelIDs(page.Hotels), []string{"H002", "H004"}; !equalIDs(got, want) {
+		t.Errorf("first page = %v, want %v", got, want)
+	}
+
+	// Ties at the same distance go to the cheaper hotel; unknown distances come last
+	page, err = listing.Apply(hotels, listing.Options{Sort: listing.SortDistance, Limit: 2, Cursor: page.NextCursor})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := hotelIDs(page.Hotels), []string{"H001", "H003"}; !equalIDs(got, want) {
+		t.Errorf("second page = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,366 @@
+// Package mapping resolves provider-specific hotel IDs to canonical hotel IDs.
+package mapping
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Default fuzzy matching settings.
+const (
+	// DefaultMinScore is the lowest name similarity accepted as a match.
+	DefaultMinScore = 0.75
+	// DefaultAmbiguityMargin is how far ahead of the runner-up the best
+	// candidate must score to be accepted.
+	DefaultAmbiguityMargin = 0.1
+	// DefaultMaxReportEntries caps the report entries kept per provider.
+	DefaultMaxReportEntries = 500
+	// DefaultMaxResolved caps the remembered resolutions of unmapped
+	// provider hotels.
+	DefaultMaxResolved = 10000
+)
+
+// Match describes how a provider hotel missing from the mapping file was
+// resolved.
+type Match string
+
+const (
+	// MatchFuzzy means the hotel was matched on normalized name and city.
+	MatchFuzzy Match = "fuzzy"
+	// MatchAmbiguous means several canonical hotels matched equally well.
+	MatchAmbiguous Match = "ambiguous"
+	// MatchUnmapped means no canonical hotel matched.
+	MatchUnmapped Match = "unmapped"
+)
+
+// Hotel is a canonical hotel and the IDs providers use for it.
+type Hotel struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	City      string            `json:"city,omitempty"`
+	Providers map[string]string `json:"providers"`
+}
+
+// File is the format of the mapping file.
+type File struct {
+	Hotels []Hotel `json:"hotels"`
+}
+
+// Load reads a mapping file.
+func Load(path string) ([]Hotel, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mapping file: %w", err)
+	}
+
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse mapping file: %w", err)
+	}
+
+	return file.Hotels, nil
+}
+
+// Mapping resolves provider hotel IDs to canonical IDs. Hotels missing from
+// the mapping file are matched by normalized name and city; those that still
+// can't be resolved keep a provider-scoped ID and are listed in the report.
+type Mapping struct {
+	byProvider  map[string]map[string]*Hotel // provider -> provider hotel ID -> hotel
+	byCity      map[string][]candidate       // normalized city -> hotels
+	minScore    float64
+	margin      float64
+	maxEntries  int
+	maxResolved int
+	logger      *slog.Logger
+
+	mu       sync.Mutex
+	resolved map[resolveKey]resolution
+	report   map[string]map[string]*Entry // provider -> provider hotel ID -> entry
+}
+
+// candidate is a canonical hotel prepared for fuzzy matching.
+type candidate struct {
+	hotel   *Hotel
+	bigrams map[string]int
+}
+
+type resolveKey struct {
+	provider, hotelID, city string
+}
+
+type resolution struct {
+	hotelID, name string
+}
+
+// Option configures a Mapping.
+type Option func(*Mapping)
+
+// WithMinScore sets the lowest name similarity, between 0 and 1, accepted
+// by the fuzzy matcher.
+func WithMinScore(score float64) Option {
+	return func(m *Mapping) {
+		m.minScore = score
+	}
+}
+
+// WithMaxResolved caps the remembered resolutions of provider hotels missing
+// from the mapping file. Past the cap, an arbitrary resolution is forgotten
+// for each new one and is matched again when next seen.
+func WithMaxResolved(n int) Option {
+	return func(m *Mapping) {
+		m.maxResolved = n
+	}
+}
+
+// New creates a Mapping from canonical hotels. It fails if a canonical ID
+// is repeated or a provider ID is mapped to more than one hotel.
+func New(hotels []Hotel, logger *slog.Logger, opts ...Option) (*Mapping, error) {
+	m := &Mapping{
+		byProvider:  make(map[string]map[string]*Hotel),
+		byCity:      make(map[string][]candidate),
+		minScore:    DefaultMinScore,
+		margin:      DefaultAmbiguityMargin,
+		maxEntries:  DefaultMaxReportEntries,
+		maxResolved: DefaultMaxResolved,
+		logger:      logger,
+		resolved:    make(map[resolveKey]resolution),
+		report:      make(map[string]map[string]*Entry),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	seen := make(map[string]bool, len(hotels))
+	for i := range hotels {
+		h := &hotels[i]
+		h.ID = strings.TrimSpace(h.ID)
+		if h.ID == "" {
+			return nil, fmt.Errorf("hotel %d has no id", i)
+		}
+		if seen[h.ID] {
+			return nil, fmt.Errorf("duplicate hotel id %q", h.ID)
+		}
+		seen[h.ID] = true
+
+		for provider, providerID := range h.Providers {
+			providerID = strings.TrimSpace(providerID)
+			ids := m.byProvider[provider]
+			if ids == nil {
+				ids = make(map[string]*Hotel)
+				m.byProvider[provider] = ids
+			}
+			if other, ok := ids[providerID]; ok {
+				return nil, fmt.Errorf("%s hotel %q is mapped to both %q and %q", provider, providerID, other.ID, h.ID)
+			}
+			ids[providerID] = h
+		}
+
+		if city := normalize(h.City); city != "" && h.Name != "" {
+			m.byCity[city] = append(m.byCity[city], candidate{hotel: h, bigrams: bigrams(normalize(h.Name))})
+		}
+	}
+
+	return m, nil
+}
+
+// Resolve returns the canonical ID and name of a provider hotel. city is the
+// hotel's city as reported by the provider or, failing that, the searched city.
+func (m *Mapping) Resolve(provider, hotelID, name, city string) (string, string) {
+	if h, ok := m.byProvider[provider][hotelID]; ok {
+		return h.ID, canonicalName(h, name)
+	}
+
+	key := resolveKey{provider: provider, hotelID: hotelID, city: normalize(city)}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if r, ok := m.resolved[key]; ok {
+		m.record(provider, hotelID, name, city, "", nil)
+		return r.hotelID, r.name
+	}
+
+	match, best, candidates := m.fuzzyMatch(name, key.city)
+	r := resolution{hotelID: provider + ":" + hotelID, name: name}
+	if match == MatchFuzzy {
+		r = resolution{hotelID: best.ID, name: canonicalName(best, name)}
+		m.logger.Debug("hotel matched by name", "provider", provider, "provider_hotel_id", hotelID, "hotel_id", best.ID)
+	}
+	if len(m.resolved) >= m.maxResolved {
+		// Forget an arbitrary resolution to stay under the cap
+		for k := range m.resolved {
+			delete(m.resolved, k)
+			break
+		}
+	}
+	if m.maxResolved > 0 {
+		m.resolved[key] = r
+	}
+	m.record(provider, hotelID, name, city, match, candidates)
+
+	return r.hotelID, r.name
+}
+
+// fuzzyMatch finds the canonical hotel in city whose name is most similar.
+// candidates lists the IDs of every hotel that scored above the threshold.
+func (m *Mapping) fuzzyMatch(name, city string) (Match, *Hotel, []string) {
+	target := bigrams(normalize(name))
+
+	type scored struct {
+		hotel *Hotel
+		score float64
+	}
+	var matches []scored
+	for _, c := range m.byCity[city] {
+		if score := dice(target, c.bigrams); score >= m.minScore {
+			matches = append(matches, scored{hotel: c.hotel, score: score})
+		}
+	}
+	if len(matches) == 0 {
+		return MatchUnmapped, nil, nil
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].hotel.ID < matches[j].hotel.ID
+	})
+	ids := make([]string, len(matches))
+	for i, s := range matches {
+		ids[i] = s.hotel.ID
+	}
+
+	if len(matches) > 1 && matches[0].score-matches[1].score < m.margin {
+		return MatchAmbiguous, nil, ids
+	}
+	return MatchFuzzy, matches[0].hotel, ids
+}
+
+// canonicalName returns the mapped hotel's name, or the provider's if the
+// mapping has none.
+func canonicalName(h *Hotel, name string) string {
+	if h.Name != "" {
+		return h.Name
+	}
+	return name
+}
+
+// normalize lowercases s, drops punctuation and collapses whitespace.
+func normalize(s string) string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// bigrams counts the character pairs of s.
+func bigrams(s string) map[string]int {
+	runes := []rune(s)
+	grams := make(map[string]int, len(runes))
+	for i := 0; i+1 < len(runes); i++ {
+		grams[string(runes[i:i+2])]++
+	}
+	return grams
+}
+
+// dice returns the Sørensen–Dice coefficient of two bigram sets, from 0
+// (nothing in common) to 1 (identical).
+func dice(a, b map[string]int) float64 {
+	var total, common int
+	for gram, n := range a {
+		total += n
+		common += min(n, b[gram])
+	}
+	for _, n := range b {
+		total += n
+	}
+	if total == 0 {
+		return 0
+	}
+	return 2 * float64(common) / float64(total)
+}
+
+// Entry is a provider hotel that needs curating: matched by name only,
+// ambiguous or not matched at all.
+type Entry struct {
+	ProviderHotelID string    `json:"provider_hotel_id"`
+	Name            string    `json:"name"`
+	City            string    `json:"city"`
+	Match           Match     `json:"match"`
+	Candidates      []string  `json:"candidates,omitempty"`
+	Seen            int       `json:"seen"`
+	LastSeen        time.Time `json:"last_seen"`
+}
+
+// Report lists, per provider, the hotels that were not resolved through the
+// mapping file.
+type Report struct {
+	Providers map[string][]Entry `json:"providers"`
+}
+
+// record adds a sighting of a provider hotel to the report. An empty match
+// counts another sighting of an already known entry. Must be called with
+// m.mu held.
+func (m *Mapping) record(provider, hotelID, name, city string, match Match, candidates []string) {
+	entries := m.report[provider]
+	if entries == nil {
+		entries = make(map[string]*Entry)
+		m.report[provider] = entries
+	}
+
+	e, ok := entries[hotelID]
+	if !ok {
+		if match == "" || len(entries) >= m.maxEntries {
+			return
+		}
+		e = &Entry{ProviderHotelID: hotelID}
+		entries[hotelID] = e
+	}
+	e.Name = name
+	e.City = city
+	if match != "" {
+		e.Match = match
+		e.Candidates = candidates
+	}
+	e.Seen++
+	e.LastSeen = time.Now()
+}
+
+// Report returns the hotels that need curating, sorted by provider hotel ID.
+func (m *Mapping) Report() Report {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	report := Report{Providers: make(map[string][]Entry, len(m.report))}
+	for provider, entries := range m.report {
+		list := make([]Entry, 0, len(entries))
+		for _, e := range entries {
+			list = append(list, *e)
+		}
+		sort.Slice(list, func(i, j int) bool {
+			return list[i].ProviderHotelID < list[j].ProviderHotelID
+		})
+		report.Providers[provider] = list
+	}
+	return report
+}
+
+// ReportHandler returns a handler serving the report as JSON.
+func (m *Mapping) ReportHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(m.Report()); err != nil {
+			m.logger.Error("failed to write mapping report", "error", err)
+		}
+	}
+}
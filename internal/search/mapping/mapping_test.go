@@ -0,0 +1,228 @@
+package mapping_test
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alex-user-go/hotels/internal/search/mapping"
+)
+
+func newTestMapping(t *testing.T) *mapping.Mapping {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	m, err := mapping.New([]mapping.Hotel{
+		{ID: "C1", Name: "Grand Hotel", City: "Paris", Providers: map[string]string{"p1": "H001", "p2": "GH-100"}},
+		{ID: "C2", Name: "City Center Inn", City: "Paris", Providers: map[string]string{"p1": "H002"}},
+		{ID: "C3", Name: "Hotel Lumiere Est", City: "Paris"},
+		{ID: "C4", Name: "Hotel Lumiere Ouest", City: "Paris"},
+		{ID: "C5", Name: "Grand Hotel", City: "Rome"},
+	}, logger)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return m
+}
+
+func TestMapping_Resolve(t *testing.T) {
+	m := newTestMapping(t)
+
+	tests := []struct {
+		name     string
+		provider string
+		hotelID  string
+		hotel    string
+		city     string
+		wantID   string
+		wantName string
+	}{
+		{
+			name:     "mapped",
+			provider: "p2",
+			hotelID:  "GH-100",
+			hotel:    "The Grand Hotel",
+			city:     "paris",
+			wantID:   "C1",
+			wantName: "Grand Hotel",
+		},
+		{
+			name:     "mapped id is provider specific",
+			provider: "p2",
+			hotelID:  "H001",
+			hotel:    "Somewhere Else",
+			city:     "paris",
+			wantID:   "p2:H001",
+			wantName: "Somewhere Else",
+		},
+		{
+			name:     "fuzzy on normalized name",
+			provider: "p3",
+			hotelID:  "X-2",
+			hotel:    "City Centre Inn!",
+			city:     " PARIS ",
+			wantID:   "C2",
+			wantName: "City Center Inn",
+		},
+		{
+			name:     "fuzzy within city",
+			provider: "p3",
+			hotelID:  "X-1",
+			hotel:    "Grand Hotel",
+			city:     "Rome",
+			wantID:   "C5",
+			wantName: "Grand Hotel",
+		},
+		{
+			name:     "ambiguous",
+			provider: "p3",
+			hotelID:  "X-3",
+			hotel:    "Hotel Lumiere",
+			city:     "paris",
+			wantID:   "p3:X-3",
+			wantName: "Hotel Lumiere",
+		},
+		{
+			name:     "unmapped",
+			provider: "p3",
+			hotelID:  "X-4",
+			hotel:    "Seaside Resort",
+			city:     "paris",
+			wantID:   "p3:X-4",
+			wantName: "Seaside Resort",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, name := m.Resolve(tt.provider, tt.hotelID, tt.hotel, tt.city)
+			if id != tt.wantID || name != tt.wantName {
+				t.Errorf("Resolve() = %q, %q, want %q, %q", id, name, tt.wantID, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestMapping_Report(t *testing.T) {
+	m := newTestMapping(t)
+
+	m.Resolve("p1", "H001", "Grand Hotel", "paris")       // Mapped, not reported
+	m.Resolve("p3", "X-2", "City Centre Inn", "paris")    // Fuzzy
+	m.Resolve("p3", "X-3", "Hotel Lumiere", "paris")      // Ambiguous
+	m.Resolve("p3", "X-4", "Seaside Resort", "paris")     // Unmapped
+	m.Resolve("p3", "X-4", "Seaside Resort", "paris")     // Seen again
+	m.Resolve("p2", "Z-9", "Mountain Lodge", "innsbruck") // Unmapped
+
+	report := m.Report()
+
+	if _, ok := report.Providers["p1"]; ok {
+		t.Errorf("mapped provider hotels should not be reported, got %+v", report.Providers["p1"])
+	}
+	if len(report.Providers["p2"]) != 1 {
+		t.Errorf("expected 1 p2 entry, got %+v", report.Providers["p2"])
+	}
+
+	entries := report.Providers["p3"]
+	if len(entries) != 3 {
+		t.Fatalf("expected 3 p3 entries, got %+v", entries)
+	}
+
+	want := []struct {
+		id         string
+		match      mapping.Match
+		candidates int
+		seen       int
+	}{
+		{id: "X-2", match: mapping.MatchFuzzy, candidates: 1, seen: 1},
+		{id: "X-3", match: mapping.MatchAmbiguous, candidates: 2, seen: 1},
+		{id: "X-4", match: mapping.MatchUnmapped, candidates: 0, seen: 2},
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.ProviderHotelID != w.id || e.Match != w.match || len(e.Candidates) != w.candidates || e.Seen != w.seen {
+			t.Errorf("entries[%d] = %+v, want %s %s with %d candidates seen %d times", i, e, w.id, w.match, w.candidates, w.seen)
+		}
+	}
+}
+
+func TestNew_InvalidMapping(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+	tests := []struct {
+		name   string
+		hotels []mapping.Hotel
+	}{
+		{
+			name:   "missing id",
+			hotels: []mapping.Hotel{{Name: "Grand Hotel"}},
+		},
+		{
+			name:   "duplicate id",
+			hotels: []mapping.Hotel{{ID: "C1"}, {ID: "C1"}},
+		},
+		{
+			name: "provider id mapped twice",
+			hotels: []mapping.Hotel{
+				{ID: "C1", Providers: map[string]string{"p1": "H001"}},
+				{ID: "C2", Providers: map[string]string{"p1": "H001"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := mapping.New(tt.hotels, logger); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mapping.json")
+	data := `{"hotels": [{"id": "C1", "name": "Grand Hotel", "city": "paris", "providers": {"p1": "H001"}}]}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	hotels, err := mapping.Load(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(hotels) != 1 || hotels[0].ID != "C1" || hotels[0].Providers["p1"] != "H001" {
+		t.Errorf("unexpected hotels: %+v", hotels)
+	}
+
+	if _, err := mapping.Load(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestMapping_Resolve_MaxResolved(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	m, err := mapping.New([]mapping.Hotel{
+		{ID: "C1", Name: "Grand Hotel", City: "Paris"},
+	}, logger, mapping.WithMaxResolved(1))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Each city is a new resolution, evicting the previous one
+	for range 2 {
+		for _, city := range []string{"paris", "rome", "vienna"} {
+			id, _ := m.Resolve("p3", "X-1", "Grand Hotel", city)
+			want := "p3:X-1"
+			if city == "paris" {
+				want = "C1"
+			}
+			if id != want {
+				t.Errorf("Resolve(%s) = %q, want %q", city, id, want)
+			}
+		}
+	}
+
+	entries := m.Report().Providers["p3"]
+	if len(entries) != 1 || entries[0].Seen != 6 {
+		t.Errorf("report = %+v, want X-1 seen 6 times", entries)
+	}
+}
@@ -0,0 +1,446 @@
+// Package markup applies resale rules to aggregated hotel prices: markups
+// and discounts by provider, city, check-in dates, length of stay and the
+// caller's API key tier.
+package markup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/search/types"
+)
+
+// DefaultTier is the tier of callers without a known API key.
+const DefaultTier = "public"
+
+// DefaultCurrency is the currency of rule amounts that don't declare one.
+const DefaultCurrency = "EUR"
+
+const dateLayout = "2006-01-02"
+
+// Rule adjusts the price of the offers it matches. Empty conditions match
+// everything; From and To bound the check-in date, both inclusive.
+//
+// The adjustment is Percent of the net price plus Fixed, clamped to
+// [Floor, Ceiling] when set; negative values are discounts. Fixed, Floor and
+// Ceiling are amounts per stay in Currency. RoundTo99 rounds the final sell
+// price up to the next .99.
+type Rule struct {
+	ID        string   `json:"id"`
+	Providers []string `json:"providers,omitempty"`
+	Cities    []string `json:"cities,omitempty"`
+	From      string   `json:"from,omitempty"`
+	To        string   `json:"to,omitempty"`
+	MinNights int      `json:"min_nights,omitempty"`
+	MaxNights int      `json:"max_nights,omitempty"`
+	Tiers     []string `json:"tiers,omitempty"`
+
+	Percent   float64  `json:"percent,omitempty"`
+	Fixed     float64  `json:"fixed,omitempty"`
+	Floor     *float64 `json:"floor,omitempty"`
+	Ceiling   *float64 `json:"ceiling,omitempty"`
+	Currency  string   `json:"currency,omitempty"`
+	RoundTo99 bool     `json:"round_to_99,omitempty"`
+}
+
+// File is the format of the rules file. Tiers maps API keys to tiers.
+type File struct {
+	Tiers map[string]string `json:"tiers"`
+	Rules []Rule            `json:"rules"`
+}
+
+// Load reads a rules file.
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rules file: %w", err)
+	}
+
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse rules file: %w", err)
+	}
+
+	return &file, nil
+}
+
+// ruleSet is a validated rules file.
+type ruleSet struct {
+	tiers map[string]string
+	rules []rule
+}
+
+// tier returns the tier of an API key.
+func (s *ruleSet) tier(apiKey string) string {
+	if tier, ok := s.tiers[strings.TrimSpace(apiKey)]; ok {
+		return tier
+	}
+	return DefaultTier
+}
+
+// rule is a Rule with its conditions prepared for matching.
+type rule struct {
+	Rule
+	providers map[string]bool
+	cities    map[string]bool
+	tiers     map[string]bool
+}
+
+// compile validates a rules file. It fails on missing or repeated rule IDs,
+// malformed dates and inverted ranges.
+func compile(file *File) (*ruleSet, error) {
+	set := &ruleSet{
+		tiers: make(map[string]string, len(file.Tiers)),
+		rules: make([]rule, 0, len(file.Rules)),
+	}
+	for key, tier := range file.Tiers {
+		key, tier = strings.TrimSpace(key), strings.TrimSpace(tier)
+		if key == "" || tier == "" {
+			return nil, errors.New("tiers must map non-empty API keys to non-empty tiers")
+		}
+		set.tiers[key] = tier
+	}
+
+	seen := make(map[string]bool, len(file.Rules))
+	for i, r := range file.Rules {
+		r.ID = strings.TrimSpace(r.ID)
+		if r.ID == "" {
+			return nil, fmt.Errorf("rule %d has no id", i)
+		}
+		if seen[r.ID] {
+			return nil, fmt.Errorf("duplicate rule id %q", r.ID)
+		}
+		seen[r.ID] = true
+
+		for _, date := range []string{r.From, r.To} {
+			if date == "" {
+				continue
+			}
+			if _, err := time.Parse(dateLayout, date); err != nil {
+				return nil, fmt.Errorf("rule %q: invalid date %q, expected YYYY-MM-DD", r.ID, date)
+			}
+		}
+		if r.From != "" && r.To != "" && r.From > r.To {
+			return nil, fmt.Errorf("rule %q: from is after to", r.ID)
+		}
+		if r.MinNights < 0 || r.MaxNights < 0 {
+			return nil, fmt.Errorf("rule %q: nights must not be negative", r.ID)
+		}
+		if r.MaxNights > 0 && r.MinNights > r.MaxNights {
+			return nil, fmt.Errorf("rule %q: min_nights exceeds max_nights", r.ID)
+		}
+		if r.Percent <= -100 {
+			return nil, fmt.Errorf("rule %q: percent must be greater than -100", r.ID)
+		}
+		if r.Floor != nil && r.Ceiling != nil && *r.Floor > *r.Ceiling {
+			return nil, fmt.Errorf("rule %q: floor exceeds ceiling", r.ID)
+		}
+		r.Currency = strings.ToUpper(strings.TrimSpace(r.Currency))
+		if r.Currency == "" {
+			r.Currency = DefaultCurrency
+		}
+
+		set.rules = append(set.rules, rule{
+			Rule:      r,
+			providers: toSet(r.Providers, strings.TrimSpace),
+			cities:    toSet(r.Cities, normalizeCity),
+			tiers:     toSet(r.Tiers, strings.TrimSpace),
+		})
+	}
+
+	return set, nil
+}
+
+// toSet builds a lookup set from values, or nil if there are none.
+func toSet(values []string, normalize func(string) string) map[string]bool {
+	if len(values) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[normalize(v)] = true
+	}
+	return set
+}
+
+func normalizeCity(city string) string {
+	return strings.ToLower(strings.TrimSpace(city))
+}
+
+// Query describes the search a result was produced for.
+type Query struct {
+	City    string
+	Checkin string
+	Nights  int
+	// APIKey is the caller's API key; unknown or empty keys get DefaultTier.
+	APIKey string
+}
+
+// matches reports whether the rule applies to an offer from provider.
+func (r *rule) matches(q Query, tier, provider string) bool {
+	if r.providers != nil && !r.providers[provider] {
+		return false
+	}
+	if r.cities != nil && !r.cities[normalizeCity(q.City)] {
+		return false
+	}
+	if r.tiers != nil && !r.tiers[tier] {
+		return false
+	}
+	// Dates are validated as YYYY-MM-DD, so they compare as strings
+	if r.From != "" && q.Checkin < r.From {
+		return false
+	}
+	if r.To != "" && q.Checkin > r.To {
+		return false
+	}
+	if r.MinNights > 0 && q.Nights < r.MinNights {
+		return false
+	}
+	if r.MaxNights > 0 && q.Nights > r.MaxNights {
+		return false
+	}
+	return true
+}
+
+// CurrencyConverter converts amounts between currencies.
+type CurrencyConverter interface {
+	Convert(amount float64, from, to string) (float64, error)
+}
+
+// Engine applies the rules of a rules file. The file is reloaded in the
+// background when it changes; if a reload fails the last valid rules are
+// kept.
+type Engine struct {
+	path      string
+	interval  time.Duration
+	converter CurrencyConverter
+	logger    *slog.Logger
+	done      chan struct{}
+
+	mu      sync.RWMutex
+	rules   *ruleSet
+	modTime time.Time
+}
+
+// Option configures an Engine.
+type Option func(*Engine)
+
+// WithConverter sets the converter used to express rule amounts in the
+// search currency. Without one, rules whose currency differs from the
+// search currency are skipped.
+func WithConverter(converter CurrencyConverter) Option {
+	return func(e *Engine) {
+		e.converter = converter
+	}
+}
+
+// NewEngine creates an Engine and loads the rules file at path. A
+// non-positive interval disables reloading.
+func NewEngine(path string, interval time.Duration, logger *slog.Logger, opts ...Option) (*Engine, error) {
+	e := &Engine{
+		path:     path,
+		interval: interval,
+		logger:   logger,
+		done:     make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	if err := e.Reload(); err != nil {
+		return nil, err
+	}
+
+	// Start background reload
+	if interval > 0 {
+		go e.reloadLoop()
+	}
+
+	return e, nil
+}
+
+// Close stops the background reload goroutine.
+func (e *Engine) Close() {
+	close(e.done)
+}
+
+// Reload reads and validates the rules file, replacing the active rules.
+func (e *Engine) Reload() error {
+	info, err := os.Stat(e.path)
+	if err != nil {
+		return fmt.Errorf("failed to read rules file: %w", err)
+	}
+	file, err := Load(e.path)
+	if err != nil {
+		return err
+	}
+	rules, err := compile(file)
+	if err != nil {
+		return fmt.Errorf("invalid rules file: %w", err)
+	}
+
+	e.mu.Lock()
+	e.rules = rules
+	e.modTime = info.ModTime()
+	e.mu.Unlock()
+
+	return nil
+}
+
+// reloadLoop periodically reloads the rules file if it was modified.
+// A version that failed to load is not retried until the file changes again.
+func (e *Engine) reloadLoop() {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	var failed time.Time
+
+	for {
+		select {
+		case <-ticker.C:
+			info, err := os.Stat(e.path)
+			if err != nil {
+				e.logger.Error("failed to check pricing rules", "error", err)
+				continue
+			}
+			e.mu.RLock()
+			changed := !info.ModTime().Equal(e.modTime)
+			e.mu.RUnlock()
+			if !changed || info.ModTime().Equal(failed) {
+				continue
+			}
+			if err := e.Reload(); err != nil {
+				failed = info.ModTime()
+				e.logger.Error("failed to reload pricing rules", "error", err)
+				continue
+			}
+			e.logger.Info("pricing rules reloaded", "path", e.path)
+		case <-e.done:
+			return
+		}
+	}
+}
+
+// Apply returns a copy of result with the matching rules applied to each
+// hotel, and the IDs of the rules that were applied, sorted by first use.
+// Every matching rule contributes an adjustment computed on the net price.
+// The net price is kept in NetPrice; result itself is not modified.
+func (e *Engine) Apply(q Query, result *types.Result) (*types.Result, []string) {
+	e.mu.RLock()
+	rules := e.rules
+	e.mu.RUnlock()
+
+	tier := rules.tier(q.APIKey)
+
+	priced := *result
+	priced.Hotels = make([]types.Hotel, len(result.Hotels))
+	var used []string
+	usedSet := make(map[string]bool)
+
+	for i, h := range result.Hotels {
+		h.NetPrice = h.Price
+		h.AppliedRules = nil
+
+		var adjustment float64
+		round := false
+		for j := range rules.rules {
+			r := &rules.rules[j]
+			if !r.matches(q, tier, h.Provider) {
+				continue
+			}
+			amount, ok := e.adjustment(r, h.NetPrice, h.Currency)
+			if !ok {
+				continue
+			}
+			adjustment += amount
+			round = round || r.RoundTo99
+			h.AppliedRules = append(h.AppliedRules, r.ID)
+		}
+
+		if len(h.AppliedRules) > 0 {
+			sell := round2(h.NetPrice + adjustment)
+			if round {
+				sell = roundTo99(sell)
+			}
+			if sell > 0 {
+				h.Price = sell
+				if q.Nights > 0 {
+					h.PricePerNight = round2(sell / float64(q.Nights))
+				}
+				for _, id := range h.AppliedRules {
+					if !usedSet[id] {
+						usedSet[id] = true
+						used = append(used, id)
+					}
+				}
+			} else {
+				e.logger.Warn("pricing rules ignored, sell price not positive",
+					"hotel_id", h.HotelID,
+					"rules", h.AppliedRules,
+				)
+				h.AppliedRules = nil
+			}
+		}
+		priced.Hotels[i] = h
+	}
+
+	return &priced, used
+}
+
+// adjustment computes a rule's adjustment to a net price in currency.
+// It returns false if the rule's amounts can't be expressed in currency.
+func (e *Engine) adjustment(r *rule, net float64, currency string) (float64, bool) {
+	convert := func(amount float64) (float64, error) {
+		if amount == 0 || r.Currency == currency {
+			return amount, nil
+		}
+		if e.converter == nil {
+			return 0, fmt.Errorf("no converter for %s", r.Currency)
+		}
+		return e.converter.Convert(amount, r.Currency, currency)
+	}
+
+	fixed, err := convert(r.Fixed)
+	if err != nil {
+		e.logger.Warn("skipping pricing rule", "rule", r.ID, "currency", currency, "error", err)
+		return 0, false
+	}
+	amount := net*r.Percent/100 + fixed
+
+	if r.Floor != nil {
+		floor, err := convert(*r.Floor)
+		if err != nil {
+			e.logger.Warn("skipping pricing rule", "rule", r.ID, "currency", currency, "error", err)
+			return 0, false
+		}
+		amount = math.Max(amount, floor)
+	}
+	if r.Ceiling != nil {
+		ceiling, err := convert(*r.Ceiling)
+		if err != nil {
+			e.logger.Warn("skipping pricing rule", "rule", r.ID, "currency", currency, "error", err)
+			return 0, false
+		}
+		amount = math.Min(amount, ceiling)
+	}
+
+	return amount, true
+}
+
+// round2 rounds an amount to cents.
+func round2(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// roundTo99 rounds a price in cents up to the next amount ending in .99.
+func roundTo99(price float64) float64 {
+	return round2(math.Floor(price) + 0.99)
+}
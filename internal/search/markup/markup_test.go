@@ -0,0 +1,245 @@
+package markup_test
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alex-user-go/hotels/internal/search/markup"
+	"github.com/alex-user-go/hotels/internal/search/types"
+)
+
+const testRules = `{
+  "tiers": {"partner-key": "partner"},
+  "rules": [
+    {"id": "base", "percent": 10, "floor": 5, "ceiling": 30},
+    {"id": "p2-commission", "providers": ["p2"], "percent": -5},
+    {"id": "partner", "tiers": ["partner"], "fixed": -10},
+    {"id": "long-stay", "min_nights": 7, "percent": -4},
+    {"id": "paris-summer", "cities": ["Paris"], "from": "2026-06-01", "to": "2026-08-31", "fixed": 3, "currency": "USD", "round_to_99": true}
+  ]
+}`
+
+// fixedRate converts at a fixed rate of 2 units of any currency per EUR.
+type fixedRate struct{}
+
+func (fixedRate) Convert(amount float64, from, to string) (float64, error) {
+	if from != "USD" || to != "EUR" {
+		return 0, errors.New("unsupported")
+	}
+	return amount / 2, nil
+}
+
+func writeRules(t *testing.T, rules string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rules.json")
+	if err := os.WriteFile(path, []byte(rules), 0o600); err != nil {
+		t.Fatalf("failed to write rules: %v", err)
+	}
+	return path
+}
+
+func newEngine(t *testing.T, path string, interval time.Duration) *markup.Engine {
+	t.Helper()
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	e, err := markup.NewEngine(path, interval, logger, markup.WithConverter(fixedRate{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	t.Cleanup(e.Close)
+	return e
+}
+
+func TestEngine_Apply(t *testing.T) {
+	e := newEngine(t, writeRules(t, testRules), 0)
+
+	tests := []struct {
+		name      string
+		query     markup.Query
+		provider  string
+		net       float64
+		wantPrice float64
+		wantRules []string
+	}{
+		{
+			name:      "percent markup",
+			query:     markup.Query{City: "rome", Checkin: "2026-03-01", Nights: 2},
+			provider:  "p1",
+			net:       100,
+			wantPrice: 110,
+			wantRules: []string{"base"},
+		},
+		{
+			name:      "floor",
+			query:     markup.Query{City: "rome", Checkin: "2026-03-01", Nights: 2},
+			provider:  "p1",
+			net:       20,
+			wantPrice: 25,
+			wantRules: []string{"base"},
+		},
+		{
+			name:      "ceiling",
+			query:     markup.Query{City: "rome", Checkin: "2026-03-01", Nights: 2},
+			provider:  "p1",
+			net:       1000,
+			wantPrice: 1030,
+			wantRules: []string{"base"},
+		},
+		{
+			name:      "provider discount stacks on net price",
+			query:     markup.Query{City: "rome", Checkin: "2026-03-01", Nights: 2},
+			provider:  "p2",
+			net:       100,
+			wantPrice: 105,
+			wantRules: []string{"base", "p2-commission"},
+		},
+		{
+			name:      "api key tier",
+			query:     markup.Query{City: "rome", Checkin: "2026-03-01", Nights: 2, APIKey: "partner-key"},
+			provider:  "p1",
+			net:       100,
+			wantPrice: 100,
+			wantRules: []string{"base", "partner"},
+		},
+		{
+			name:      "unknown api key is public",
+			query:     markup.Query{City: "rome", Checkin: "2026-03-01", Nights: 2, APIKey: "other"},
+			provider:  "p1",
+			net:       100,
+			wantPrice: 110,
+			wantRules: []string{"base"},
+		},
+		{
+			name:      "length of stay",
+			query:     markup.Query{City: "rome", Checkin: "2026-03-01", Nights: 7},
+			provider:  "p1",
+			net:       200,
+			wantPrice: 212,
+			wantRules: []string{"base", "long-stay"},
+		},
+		{
+			name:      "city and dates with converted amount and rounding",
+			query:     markup.Query{City: "paris", Checkin: "2026-08-31", Nights: 2},
+			provider:  "p1",
+			net:       100,
+			wantPrice: 111.99,
+			wantRules: []string{"base", "paris-summer"},
+		},
+		{
+			name:      "outside date range",
+			query:     markup.Query{City: "paris", Checkin: "2026-09-01", Nights: 2},
+			provider:  "p1",
+			net:       100,
+			wantPrice: 110,
+			wantRules: []string{"base"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &types.Result{Hotels: []types.Hotel{
+				{HotelID: "H1", Currency: "EUR", Price: tt.net, PricePerNight: tt.net / 2, Provider: tt.provider},
+			}}
+
+			priced, applied := e.Apply(tt.query, result)
+
+			got := priced.Hotels[0]
+			if got.Price != tt.wantPrice {
+				t.Errorf("price = %v, want %v", got.Price, tt.wantPrice)
+			}
+			if got.NetPrice != tt.net {
+				t.Errorf("net price = %v, want %v", got.NetPrice, tt.net)
+			}
+			if !slices.Equal(got.AppliedRules, tt.wantRules) || !slices.Equal(applied, tt.wantRules) {
+				t.Errorf("rules = %v (applied %v), want %v", got.AppliedRules, applied, tt.wantRules)
+			}
+			if result.Hotels[0].Price != tt.net {
+				t.Error("input result was modified")
+			}
+		})
+	}
+}
+
+func TestEngine_Apply_SkipsUnconvertibleRule(t *testing.T) {
+	e := newEngine(t, writeRules(t, `{"rules": [{"id": "gbp-fee", "fixed": 5, "currency": "GBP"}]}`), 0)
+
+	result := &types.Result{Hotels: []types.Hotel{{HotelID: "H1", Currency: "EUR", Price: 100}}}
+	priced, applied := e.Apply(markup.Query{City: "rome", Checkin: "2026-03-01", Nights: 1}, result)
+
+	if priced.Hotels[0].Price != 100 || len(applied) != 0 {
+		t.Errorf("expected the rule to be skipped, got price %v and rules %v", priced.Hotels[0].Price, applied)
+	}
+}
+
+func TestNewEngine_InvalidRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   string
+		wantErr string
+	}{
+		{name: "missing id", rules: `{"rules": [{"percent": 5}]}`, wantErr: "has no id"},
+		{name: "duplicate id", rules: `{"rules": [{"id": "a"}, {"id": "a"}]}`, wantErr: "duplicate rule id"},
+		{name: "bad date", rules: `{"rules": [{"id": "a", "from": "2026/01/01"}]}`, wantErr: "invalid date"},
+		{name: "inverted dates", rules: `{"rules": [{"id": "a", "from": "2026-02-01", "to": "2026-01-01"}]}`, wantErr: "from is after to"},
+		{name: "inverted nights", rules: `{"rules": [{"id": "a", "min_nights": 5, "max_nights": 2}]}`, wantErr: "min_nights exceeds max_nights"},
+		{name: "floor above ceiling", rules: `{"rules": [{"id": "a", "floor": 10, "ceiling": 5}]}`, wantErr: "floor exceeds ceiling"},
+		{name: "full discount", rules: `{"rules": [{"id": "a", "percent": -100}]}`, wantErr: "greater than -100"},
+		{name: "malformed json", rules: `{"rules": [`, wantErr: "failed to parse"},
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := markup.NewEngine(writeRules(t, tt.rules), 0, logger)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEngine_Reload(t *testing.T) {
+	path := writeRules(t, `{"rules": [{"id": "v1", "percent": 10}]}`)
+	e := newEngine(t, path, 10*time.Millisecond)
+
+	apply := func() float64 {
+		result := &types.Result{Hotels: []types.Hotel{{HotelID: "H1", Currency: "EUR", Price: 100}}}
+		priced, _ := e.Apply(markup.Query{Nights: 1}, result)
+		return priced.Hotels[0].Price
+	}
+	if got := apply(); got != 110 {
+		t.Fatalf("price = %v, want 110", got)
+	}
+
+	// An invalid file keeps the previous rules
+	if err := os.WriteFile(path, []byte(`{"rules": [{"id": ""}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Reload(); err == nil {
+		t.Fatal("expected reload of invalid rules to fail")
+	}
+	if got := apply(); got != 110 {
+		t.Fatalf("price after failed reload = %v, want 110", got)
+	}
+
+	// A changed file is picked up in the background
+	if err := os.WriteFile(path, []byte(`{"rules": [{"id": "v2", "percent": 20}]}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for apply() != 120 {
+		if time.Now().After(deadline) {
+			t.Fatal("rules were not reloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,224 @@
+// Package normalize runs provider offers through a pipeline of named
+// normalization and validation steps before they are aggregated.
+package normalize
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/alex-user-go/hotels/internal/providers"
+)
+
+// Names of the built-in steps.
+const (
+	StepTrim       = "trim"
+	StepCurrency   = "currency"
+	StepPrice      = "price"
+	StepName       = "name"
+	StepMapping    = "mapping"
+	StepEnrichment = "enrichment"
+)
+
+// DefaultCurrency is assigned to offers that don't state a currency.
+const DefaultCurrency = "EUR"
+
+// Offer is a provider hotel moving through a pipeline, with the context of
+// the search it was returned for. Steps transform Hotel in place.
+type Offer struct {
+	Provider string
+	// City is the searched city.
+	City   string
+	Nights int
+	Hotel  providers.Hotel
+	// Annotations are notes steps attach to the offer.
+	Annotations []string
+}
+
+// Annotate attaches a note to the offer.
+func (o *Offer) Annotate(note string) {
+	o.Annotations = append(o.Annotations, note)
+}
+
+// Step is a named pipeline step. Apply transforms or annotates the offer and
+// returns false to drop it.
+type Step struct {
+	Name  string
+	Apply func(o *Offer) bool
+}
+
+// Pipeline applies steps to offers in order.
+type Pipeline struct {
+	steps []Step
+}
+
+// New creates a Pipeline from steps.
+func New(steps ...Step) *Pipeline {
+	return &Pipeline{steps: steps}
+}
+
+// Run applies every step to the offer. If a step drops it, Run stops and
+// returns the name of that step and false.
+func (p *Pipeline) Run(o *Offer) (string, bool) {
+	for _, s := range p.steps {
+		if !s.Apply(o) {
+			return s.Name, false
+		}
+	}
+	return "", true
+}
+
+// Steps returns the names of the pipeline's steps in order.
+func (p *Pipeline) Steps() []string {
+	names := make([]string, len(p.steps))
+	for i, s := range p.steps {
+		names[i] = s.Name
+	}
+	return names
+}
+
+// Registry holds the steps pipelines can be built from, by name.
+type Registry map[string]Step
+
+// Register adds steps to the registry, replacing steps with the same name.
+func (r Registry) Register(steps ...Step) {
+	for _, s := range steps {
+		r[s.Name] = s
+	}
+}
+
+// Build creates a Pipeline from step names. It fails on unknown or repeated
+// names.
+func (r Registry) Build(names []string) (*Pipeline, error) {
+	steps := make([]Step, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		s, ok := r[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown normalization step %q", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("normalization step %q is repeated", name)
+		}
+		seen[name] = true
+		steps = append(steps, s)
+	}
+	return New(steps...), nil
+}
+
+// Resolver resolves provider-specific hotel IDs to canonical IDs.
+type Resolver interface {
+	Resolve(provider, hotelID, name, city string) (string, string)
+}
+
+// Learner records hotel content sent by providers.
+type Learner interface {
+	Learn(hotelID string, hotel providers.Hotel)
+}
+
+// Trim removes surrounding whitespace from text fields and drops offers
+// without a hotel ID or name.
+func Trim() Step {
+	return Step{Name: StepTrim, Apply: func(o *Offer) bool {
+		h := &o.Hotel
+		h.HotelID = strings.TrimSpace(h.HotelID)
+		h.Name = strings.TrimSpace(h.Name)
+		h.City = strings.TrimSpace(h.City)
+		h.Currency = strings.TrimSpace(h.Currency)
+		h.Address = strings.TrimSpace(h.Address)
+		return h.HotelID != "" && h.Name != ""
+	}}
+}
+
+// Currency upper-cases the currency code, assigns DefaultCurrency when it is
+// missing and drops offers whose code is not three letters.
+func Currency() Step {
+	return Step{Name: StepCurrency, Apply: func(o *Offer) bool {
+		code := strings.ToUpper(strings.TrimSpace(o.Hotel.Currency))
+		if code == "" {
+			code = DefaultCurrency
+			o.Annotate("currency defaulted to " + DefaultCurrency)
+		}
+		o.Hotel.Currency = code
+		if len(code) != 3 {
+			return false
+		}
+		for _, r := range code {
+			if r < 'A' || r > 'Z' {
+				return false
+			}
+		}
+		return true
+	}}
+}
+
+// Price drops offers with a non-positive price, negative or excessive taxes
+// and fees, an unknown price basis or a quote for a different number of
+// nights. A missing basis is set to per stay.
+func Price() Step {
+	return Step{Name: StepPrice, Apply: func(o *Offer) bool {
+		h := &o.Hotel
+		if h.Price <= 0 || h.Taxes < 0 || h.Fees < 0 || h.Taxes+h.Fees > h.Price {
+			return false
+		}
+		if h.Nights > 0 && h.Nights != o.Nights {
+			return false
+		}
+		if h.PriceBasis == "" {
+			h.PriceBasis = providers.PricePerStay
+		}
+		return h.PriceBasis.Valid()
+	}}
+}
+
+// Name removes control characters from the hotel name and collapses runs of
+// whitespace. Offers whose name ends up empty are dropped.
+func Name() Step {
+	return Step{Name: StepName, Apply: func(o *Offer) bool {
+		cleaned := strings.Join(strings.FieldsFunc(o.Hotel.Name, func(r rune) bool {
+			return unicode.IsSpace(r) || unicode.IsControl(r)
+		}), " ")
+		if cleaned != o.Hotel.Name {
+			o.Annotate("name cleaned")
+			o.Hotel.Name = cleaned
+		}
+		return cleaned != ""
+	}}
+}
+
+// Mapping replaces the provider hotel ID and name with the canonical ones.
+// The hotel's own city is used for matching, falling back to the searched
+// city.
+func Mapping(resolver Resolver) Step {
+	return Step{Name: StepMapping, Apply: func(o *Offer) bool {
+		city := o.Hotel.City
+		if strings.TrimSpace(city) == "" {
+			city = o.City
+		}
+		o.Hotel.HotelID, o.Hotel.Name = resolver.Resolve(o.Provider, o.Hotel.HotelID, o.Hotel.Name, city)
+		return true
+	}}
+}
+
+// Enrichment records the content the provider sent for the hotel under its
+// current, normally canonical, ID.
+func Enrichment(learner Learner) Step {
+	return Step{Name: StepEnrichment, Apply: func(o *Offer) bool {
+		learner.Learn(o.Hotel.HotelID, o.Hotel)
+		return true
+	}}
+}
+
+// Standard returns the built-in steps in their default order. Mapping and
+// enrichment are only included when a resolver or learner is given.
+func Standard(resolver Resolver, learner Learner) []Step {
+	steps := []Step{Trim(), Currency(), Price(), Name()}
+	if resolver != nil {
+		steps = append(steps, Mapping(resolver))
+	}
+	if learner != nil {
+		steps = append(steps, Enrichment(learner))
+	}
+	return steps
+}
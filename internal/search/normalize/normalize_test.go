@@ -0,0 +1,117 @@
+package normalize_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/alex-user-go/hotels/internal/providers"
+	"github.com/alex-user-go/hotels/internal/search/normalize"
+)
+
+// prefixResolver maps every hotel to an ID prefixed with "C-".
+type prefixResolver struct{}
+
+func (prefixResolver) Resolve(provider, hotelID, name, city string) (string, string) {
+	return "C-" + hotelID + "@" + city, "Canonical " + name
+}
+
+// recordingLearner remembers the IDs it learned content for.
+type recordingLearner struct {
+	ids []string
+}
+
+func (r *recordingLearner) Learn(hotelID string, hotel providers.Hotel) {
+	r.ids = append(r.ids, hotelID)
+}
+
+func TestPipeline_Run(t *testing.T) {
+	learner := &recordingLearner{}
+	pipeline := normalize.New(normalize.Standard(prefixResolver{}, learner)...)
+
+	tests := []struct {
+		name        string
+		hotel       providers.Hotel
+		wantDropped string
+		wantHotel   providers.Hotel
+		wantNotes   []string
+	}{
+		{
+			name:  "clean offer",
+			hotel: providers.Hotel{HotelID: " H1 ", Name: "Grand\tHotel\x00 ", Currency: "eur", Price: 100},
+			wantHotel: providers.Hotel{
+				HotelID: "C-H1@paris", Name: "Canonical Grand Hotel", Currency: "EUR", Price: 100,
+				PriceBasis: providers.PricePerStay,
+			},
+			wantNotes: []string{"name cleaned"},
+		},
+		{
+			name:  "hotel city used for mapping",
+			hotel: providers.Hotel{HotelID: "H1", Name: "Grand Hotel", City: "lyon", Price: 100, PriceBasis: providers.PricePerNight},
+			wantHotel: providers.Hotel{
+				HotelID: "C-H1@lyon", Name: "Canonical Grand Hotel", City: "lyon", Currency: "EUR", Price: 100,
+				PriceBasis: providers.PricePerNight,
+			},
+			wantNotes: []string{"currency defaulted to EUR"},
+		},
+		{name: "missing id", hotel: providers.Hotel{HotelID: "  ", Name: "Hotel", Price: 100}, wantDropped: normalize.StepTrim},
+		{name: "bad currency", hotel: providers.Hotel{HotelID: "H1", Name: "Hotel", Currency: "EURO", Price: 100}, wantDropped: normalize.StepCurrency},
+		{name: "zero price", hotel: providers.Hotel{HotelID: "H1", Name: "Hotel", Price: 0}, wantDropped: normalize.StepPrice},
+		{name: "taxes above price", hotel: providers.Hotel{HotelID: "H1", Name: "Hotel", Price: 10, Taxes: 11}, wantDropped: normalize.StepPrice},
+		{name: "other stay length", hotel: providers.Hotel{HotelID: "H1", Name: "Hotel", Price: 10, Nights: 3}, wantDropped: normalize.StepPrice},
+		{name: "unknown basis", hotel: providers.Hotel{HotelID: "H1", Name: "Hotel", Price: 10, PriceBasis: "per_week"}, wantDropped: normalize.StepPrice},
+		{name: "name of control characters", hotel: providers.Hotel{HotelID: "H1", Name: "\x01\x02", Price: 10}, wantDropped: normalize.StepName},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			offer := normalize.Offer{Provider: "p1", City: "paris", Nights: 2, Hotel: tt.hotel}
+			dropped, ok := pipeline.Run(&offer)
+
+			if tt.wantDropped != "" {
+				if ok || dropped != tt.wantDropped {
+					t.Fatalf("dropped by %q (ok %v), want %q", dropped, ok, tt.wantDropped)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("unexpectedly dropped by %q", dropped)
+			}
+			h := offer.Hotel
+			w := tt.wantHotel
+			if h.HotelID != w.HotelID || h.Name != w.Name || h.City != w.City || h.Currency != w.Currency || h.Price != w.Price || h.PriceBasis != w.PriceBasis {
+				t.Errorf("hotel = %+v, want %+v", h, w)
+			}
+			if !slices.Equal(offer.Annotations, tt.wantNotes) {
+				t.Errorf("annotations = %v, want %v", offer.Annotations, tt.wantNotes)
+			}
+			if got := learner.ids[len(learner.ids)-1]; got != w.HotelID {
+				t.Errorf("content learned for %q, want %q", got, w.HotelID)
+			}
+		})
+	}
+}
+
+func TestRegistry_Build(t *testing.T) {
+	registry := normalize.Registry{}
+	registry.Register(normalize.Standard(nil, nil)...)
+
+	pipeline, err := registry.Build([]string{"trim", " price "})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := pipeline.Steps(); !slices.Equal(got, []string{"trim", "price"}) {
+		t.Errorf("steps = %v", got)
+	}
+
+	// Without the name step, names are only trimmed
+	offer := normalize.Offer{Nights: 1, Hotel: providers.Hotel{HotelID: "H1", Name: "Grand  Hotel", Price: 10}}
+	if _, ok := pipeline.Run(&offer); !ok || offer.Hotel.Name != "Grand  Hotel" {
+		t.Errorf("unexpected result: %+v", offer.Hotel)
+	}
+
+	for _, names := range [][]string{{"trim", "mapping"}, {"trim", "trim"}} {
+		if _, err := registry.Build(names); err == nil {
+			t.Errorf("Build(%v): expected error", names)
+		}
+	}
+}
@@ -41,6 +41,13 @@ func (l *Limiter) Close() {
 
 // Allow checks if a request for the given key is allowed.
 func (l *Limiter) Allow(key string) bool {
+	return l.AllowN(key, 1)
+}
+
+// AllowN checks if a request costing n tokens is allowed for the given key.
+// Tokens are only consumed if all n are available; a non-positive n is
+// never allowed.
+func (l *Limiter) AllowN(key string, n int) bool {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
@@ -62,9 +69,9 @@ func (l *Limiter) Allow(key string) bool {
 		b.lastReset = now
 	}
 
-	// Check and consume token
-	if b.tokens > 0 {
-		b.tokens--
+	// Check and consume tokens
+	if n > 0 && b.tokens >= n {
+		b.tokens -= n
 		return true
 	}
 
@@ -85,6 +85,29 @@ func TestLimiter_Allow(t *testing.T) {
 	}
 }
 
+func TestLimiter_AllowN(t *testing.T) {
+	l := ratelimit.New(10, time.Minute)
+	defer l.Close()
+
+	key := "batch"
+
+	if !l.AllowN(key, 7) {
+		t.Error("request costing 7 of 10 tokens should be allowed")
+	}
+	if l.AllowN(key, 4) {
+		t.Error("request costing 4 of 3 remaining tokens should be blocked")
+	}
+	if !l.AllowN(key, 3) {
+		t.Error("blocked request must not consume tokens; 3 should remain")
+	}
+	if l.Allow(key) {
+		t.Error("bucket should be empty")
+	}
+	if l.AllowN("other", 0) {
+		t.Error("non-positive cost should be rejected")
+	}
+}
+
 func TestLimiter_Allow_WindowReset(t *testing.T) {
 	l := ratelimit.New(2, 50*time.Millisecond)
 	defer l.Close()
@@ -6,12 +6,51 @@ type Result struct {
 	ProvidersTotal     int     `json:"-"`
 	ProvidersSucceeded int     `json:"-"`
 	ProvidersFailed    int     `json:"-"`
+	ProvidersSkipped   int     `json:"-"`
 }
 
 // Hotel represents a normalized hotel.
+// Price and Currency hold the total stay amount in the requested display
+// currency, including Taxes and Fees when the provider itemized them;
+// PricePerNight is Price spread over the nights. OriginalPrice and
+// OriginalCurrency hold the total stay amount in the provider's currency, and
+// PriceBasis tells whether the provider quoted per stay or per night.
+// Providers lists every provider that offered the hotel, sorted by name.
+// Address, Stars, Location, Amenities and Image come from the hotel content
+// catalog and are omitted when unknown. DistanceKm is only set by geo searches.
+// Suspect marks a price found out of line with comparable offers.
+//
+// Provider, NetPrice, AppliedRules and Annotations are kept internal: the
+// provider whose offer was chosen, the price before markup rules, the IDs of
+// the rules that turned it into Price and the notes normalization steps
+// attached to the offer.
 type Hotel struct {
-	HotelID  string  `json:"hotel_id"`
-	Name     string  `json:"name"`
-	Currency string  `json:"currency"`
-	Price    float64 `json:"price"`
+	HotelID          string    `json:"hotel_id"`
+	Name             string    `json:"name"`
+	Currency         string    `json:"currency"`
+	Price            float64   `json:"price"`
+	OriginalCurrency string    `json:"original_currency"`
+	OriginalPrice    float64   `json:"original_price"`
+	PricePerNight    float64   `json:"price_per_night"`
+	Taxes            float64   `json:"taxes,omitempty"`
+	Fees             float64   `json:"fees,omitempty"`
+	PriceBasis       string    `json:"price_basis"`
+	Providers        []string  `json:"providers"`
+	Address          string    `json:"address,omitempty"`
+	Stars            float64   `json:"stars,omitempty"`
+	Location         *Location `json:"location,omitempty"`
+	Amenities        []string  `json:"amenities,omitempty"`
+	Image            string    `json:"image,omitempty"`
+	DistanceKm       *float64  `json:"distance_km,omitempty"`
+	Suspect          bool      `json:"suspect,omitempty"`
+	Provider         string    `json:"-"`
+	NetPrice         float64   `json:"-"`
+	AppliedRules     []string  `json:"-"`
+	Annotations      []string  `json:"-"`
+}
+
+// Location is a point in decimal degrees.
+type Location struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
 }